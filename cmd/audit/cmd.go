@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	listCmd "github.com/kyma-incubator/reconciler/cmd/audit/list"
+	"github.com/kyma-incubator/reconciler/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd(o *cli.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the audit log",
+		Long:  "Administrative CLI tool to inspect who changed configurations, clusters and operations of the Kyma reconciler",
+	}
+
+	cmd.AddCommand(listCmd.NewCmd(listCmd.NewOptions(o)))
+
+	return cmd
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+	"github.com/kyma-incubator/reconciler/pkg/audit"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List audit entries.",
+		Long:    `List the recorded writes (latest entries first) with their actor, action, target entity and versions.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			if o.Registry == nil {
+				if err := o.InitApplicationRegistry(true); err != nil {
+					return err
+				}
+			}
+			return Run(o)
+		},
+	}
+	cmd.Flags().StringVar(&o.Since, "since", o.Since, "Show entries recorded since this duration (e.g. 24h) or RFC3339 timestamp")
+	cmd.Flags().StringVar(&o.Actor, "actor", "", "Show only entries of this actor")
+	cmd.Flags().StringVar(&o.EntityType, "entity-type", "", "Show only entries of this entity type (e.g. cluster, bucketRule, value)")
+	cmd.Flags().StringVar(&o.Target, "target", "", "Show only entries of this target entity (e.g. a cluster name)")
+	cmd.Flags().IntVar(&o.Limit, "limit", 0, "Maximal amount of shown entries (0 shows all entries)")
+	cmd.Flags().StringVarP(&o.OutputFormat, "output-format", "o", "table",
+		fmt.Sprintf("Define output formatting. Supported options are '%s'.", strings.Join(cli.SupportedOutputFormats, "', '")))
+	return cmd
+}
+
+func Run(o *Options) error {
+	since, err := audit.ParseSince(o.Since)
+	if err != nil {
+		return err
+	}
+	entries, err := o.Registry.AuditRepository().List(&audit.Filter{
+		Since:      since,
+		Actor:      o.Actor,
+		EntityType: o.EntityType,
+		Target:     o.Target,
+		Limit:      o.Limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	formatter, err := cli.NewOutputFormatter(o.OutputFormat)
+	if err != nil {
+		return err
+	}
+	if err := formatter.Header("ID", "Created at (UTC)", "Actor", "Action", "Entity type", "Target",
+		"Version before", "Version after", "Details"); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := formatter.AddRow(entry.ID, entry.Created.Format(time.RFC822Z), entry.Actor, entry.Action,
+			entry.EntityType, entry.Target, entry.VersionBefore, entry.VersionAfter, entry.Details); err != nil {
+			return err
+		}
+	}
+	return formatter.Output(os.Stdout)
+}
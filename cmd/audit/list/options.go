@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+)
+
+type Options struct {
+	*cli.Options
+	Since      string
+	Actor      string
+	EntityType string
+	Target     string
+	Limit      int
+}
+
+func NewOptions(o *cli.Options) *Options {
+	return &Options{o,
+		"24h", //Since
+		"",    //Actor
+		"",    //EntityType
+		"",    //Target
+		0,     //Limit
+	}
+}
+
+func (o *Options) Validate() error {
+	if o.Limit < 0 {
+		return fmt.Errorf("limit cannot be < 0")
+	}
+	return nil
+}
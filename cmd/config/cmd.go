@@ -3,11 +3,19 @@ package cmd
 import (
 	createCmd "github.com/kyma-incubator/reconciler/cmd/config/create"
 	createKeyCmd "github.com/kyma-incubator/reconciler/cmd/config/create/key"
+	createRuleCmd "github.com/kyma-incubator/reconciler/cmd/config/create/rule"
 	createValueCmd "github.com/kyma-incubator/reconciler/cmd/config/create/value"
+	deleteCmd "github.com/kyma-incubator/reconciler/cmd/config/delete"
+	deleteRuleCmd "github.com/kyma-incubator/reconciler/cmd/config/delete/rule"
+	evaluateCmd "github.com/kyma-incubator/reconciler/cmd/config/evaluate"
+	evaluateBucketsCmd "github.com/kyma-incubator/reconciler/cmd/config/evaluate/buckets"
+	exportCmd "github.com/kyma-incubator/reconciler/cmd/config/export"
 	getCmd "github.com/kyma-incubator/reconciler/cmd/config/get"
 	getBucketCmd "github.com/kyma-incubator/reconciler/cmd/config/get/bucket"
 	getKeyCmd "github.com/kyma-incubator/reconciler/cmd/config/get/key"
+	getRuleCmd "github.com/kyma-incubator/reconciler/cmd/config/get/rule"
 	getValueCmd "github.com/kyma-incubator/reconciler/cmd/config/get/value"
+	importCmd "github.com/kyma-incubator/reconciler/cmd/config/import"
 	"github.com/kyma-incubator/reconciler/internal/cli"
 	"github.com/spf13/cobra"
 )
@@ -24,13 +32,29 @@ func NewCmd(o *cli.Options) *cobra.Command {
 	cmd.AddCommand(getCommand)
 	getCommand.AddCommand(getBucketCmd.NewCmd(o))
 	getCommand.AddCommand(getKeyCmd.NewCmd(getKeyCmd.NewOptions(o)))
+	getCommand.AddCommand(getRuleCmd.NewCmd(getRuleCmd.NewOptions(o)))
 	getCommand.AddCommand(getValueCmd.NewCmd(getValueCmd.NewOptions(o)))
 
 	//register create commands
 	createCommand := createCmd.NewCmd(o)
 	cmd.AddCommand(createCommand)
 	createCommand.AddCommand(createKeyCmd.NewCmd(createKeyCmd.NewOptions(o)))
+	createCommand.AddCommand(createRuleCmd.NewCmd(createRuleCmd.NewOptions(o)))
 	createCommand.AddCommand(createValueCmd.NewCmd(createValueCmd.NewOptions(o)))
 
+	//register delete commands
+	deleteCommand := deleteCmd.NewCmd(o)
+	cmd.AddCommand(deleteCommand)
+	deleteCommand.AddCommand(deleteRuleCmd.NewCmd(o))
+
+	//register evaluate commands
+	evaluateCommand := evaluateCmd.NewCmd(o)
+	cmd.AddCommand(evaluateCommand)
+	evaluateCommand.AddCommand(evaluateBucketsCmd.NewCmd(o))
+
+	//register import and export commands
+	cmd.AddCommand(exportCmd.NewCmd(exportCmd.NewOptions(o)))
+	cmd.AddCommand(importCmd.NewCmd(importCmd.NewOptions(o)))
+
 	return cmd
 }
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/kyma-incubator/reconciler/pkg/audit"
+	"github.com/kyma-incubator/reconciler/pkg/interpreter"
 	"github.com/kyma-incubator/reconciler/pkg/model"
 	"github.com/spf13/cobra"
 )
@@ -21,11 +25,11 @@ func NewCmd(o *Options) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&o.DataType, "data-type", "string", fmt.Sprintf("Define data-type of the key (supported types are %s, %s, %s)",
-		model.String, model.Integer, model.Boolean))
-	cmd.Flags().BoolVar(&o.Encrypted, "encrypted", true, "Key values have to be encrypted")
+	cmd.Flags().StringVar(&o.DataType, "data-type", "string", fmt.Sprintf("Define data-type of the key (supported types are %s)",
+		dataTypes()))
+	cmd.Flags().BoolVar(&o.Encrypted, "encrypted", true, fmt.Sprintf("Key values have to be encrypted (always enabled for data type '%s')", model.Secret))
 	cmd.Flags().StringVar(&o.Validator, "validator", "", "Validator logic executed when setting a new value")
-	cmd.Flags().StringVar(&o.Trigger, "trigger", "", "Trigger function executed when a value was added/changed")
+	cmd.Flags().StringVar(&o.Trigger, "trigger", "", "Trigger function executed when a value was added/changed (only for keys prefixed by '<component>.', not executed during the first reconciliation of a component)")
 
 	if err := cobra.MarkFlagRequired(cmd.Flags(), "data-type"); err != nil {
 		panic(err) //would be an obvious bug and has to lead to a panic
@@ -38,7 +42,7 @@ func Run(o *Options, keys []string) error {
 	for _, key := range keys {
 		newKey, err := createKey(o, key)
 		if err != nil {
-			return err
+			return renderError(err)
 		}
 		fmt.Printf("Key '%s' created\n", newKey.Key)
 	}
@@ -50,12 +54,36 @@ func createKey(o *Options, key string) (*model.KeyEntity, error) {
 	if err != nil {
 		return nil, err
 	}
-	return o.Registry.KVRepository().CreateKey(&model.KeyEntity{
+	actor := audit.CLIActor()
+	return o.Registry.KVRepository().WithActor(actor).CreateKey(&model.KeyEntity{
 		Key:       key,
 		DataType:  dt,
 		Encrypted: o.Encrypted,
 		Validator: o.Validator,
 		Trigger:   o.Trigger,
-		Username:  "!TODO!", //FIXME
+		Username:  actor,
 	})
 }
+
+func dataTypes() string {
+	var result []string
+	for _, dt := range model.DataTypes() {
+		result = append(result, string(dt))
+	}
+	return strings.Join(result, ", ")
+}
+
+//renderError lists the compile issues of invalid validator or trigger code
+func renderError(err error) error {
+	var codeErr *model.InvalidCodeError
+	var compileErr *interpreter.CompileError
+	if !errors.As(err, &codeErr) || !errors.As(err, &compileErr) {
+		return err
+	}
+	var msgs []string
+	for _, issue := range compileErr.Issues {
+		msgs = append(msgs, fmt.Sprintf("  %s", issue))
+	}
+	return fmt.Errorf("%s code of key '%s' cannot be compiled:\n%s",
+		codeErr.Attribute, codeErr.Key, strings.Join(msgs, "\n"))
+}
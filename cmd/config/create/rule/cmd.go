@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kyma-incubator/reconciler/pkg/audit"
+	"github.com/kyma-incubator/reconciler/pkg/cluster"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rule",
+		Aliases: []string{"rules", "ru"},
+		Short:   "Create a bucket rule.",
+		Long: `Create a new entity or version of a bucket rule.
+A bucket rule assigns an ordered list of buckets to all clusters matching its selector.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			if len(args) != 1 {
+				return fmt.Errorf("One rule name has to be provided: '%s'", strings.Join(args, "', '"))
+			}
+			return Run(o, args[0])
+		},
+	}
+
+	cmd.Flags().Int64Var(&o.Priority, "priority", 0, "Priority of the rule: buckets of rules with a higher priority override buckets of rules with a lower priority")
+	cmd.Flags().StringSliceVar(&o.Buckets, "bucket", []string{}, fmt.Sprintf("Ordered list of buckets (supported placeholders are '%s', '%s', '%s')",
+		cluster.BucketPlaceholderGlobalAccountID, cluster.BucketPlaceholderSubAccountID, cluster.BucketPlaceholderCluster))
+	cmd.Flags().StringToStringVar(&o.Selector, "selector", map[string]string{}, fmt.Sprintf("Selector patterns (supported fields are %s, %s, %s, %s, %s, %s)",
+		model.SelectorGlobalAccountID, model.SelectorSubAccountID, model.SelectorServicePlanID,
+		model.SelectorShootName, model.SelectorRuntimeName, model.SelectorRuntimeDescription))
+
+	if err := cobra.MarkFlagRequired(cmd.Flags(), "bucket"); err != nil {
+		panic(err) //would be an obvious bug and has to lead to a panic
+	}
+
+	return cmd
+}
+
+func Run(o *Options, rule string) error {
+	actor := audit.CLIActor()
+	ruleEntity, err := model.NewBucketRuleEntity(rule, o.Priority, o.Selector, o.Buckets, actor)
+	if err != nil {
+		return err
+	}
+	ruleEntity, err = o.Registry.KVRepository().WithActor(actor).CreateBucketRule(ruleEntity)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Bucket rule '%s' created (version %d)\n", ruleEntity.Rule, ruleEntity.Version)
+	return nil
+}
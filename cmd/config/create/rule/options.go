@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+)
+
+type Options struct {
+	*cli.Options
+	Priority int64
+	Buckets  []string
+	Selector map[string]string
+}
+
+func NewOptions(o *cli.Options) *Options {
+	return &Options{o, 0, []string{}, map[string]string{}}
+}
+
+func (o *Options) Validate() error {
+	if len(o.Buckets) == 0 {
+		return fmt.Errorf("At least one bucket has to be specified")
+	}
+	return nil
+}
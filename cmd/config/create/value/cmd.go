@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/kyma-incubator/reconciler/pkg/audit"
 	"github.com/kyma-incubator/reconciler/pkg/model"
 	"github.com/spf13/cobra"
 )
@@ -43,19 +44,20 @@ func Run(o *Options, val string) error {
 		return err
 	}
 
-	value, err := o.Registry.KVRepository().CreateValue(&model.ValueEntity{
+	actor := audit.CLIActor()
+	value, err := o.Registry.KVRepository().WithActor(actor).CreateValue(&model.ValueEntity{
 		Bucket:     o.Bucket,
 		Key:        key.Key,
 		KeyVersion: key.Version,
 		DataType:   key.DataType,
 		Value:      val,
-		Username:   "!TODO!", //FIXME
+		Username:   actor,
 	})
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Value '%s' created (bucket: %s / key: %s - version %d)\n", value.Value, value.Bucket, value.Key, value.KeyVersion)
+	fmt.Printf("Value '%s' created (bucket: %s / key: %s - version %d)\n", key.Mask(val), value.Bucket, value.Key, value.KeyVersion)
 	return nil
 }
 
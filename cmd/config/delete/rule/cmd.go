@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+	"github.com/kyma-incubator/reconciler/pkg/audit"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd(o *cli.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rule",
+		Aliases: []string{"rules", "ru"},
+		Short:   "Delete bucket rules.",
+		Long:    `Delete bucket rules (the history of a deleted rule is preserved).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return Run(o, args)
+		},
+	}
+	return cmd
+}
+
+func Run(o *cli.Options, rules []string) error {
+	actor := audit.CLIActor()
+	for _, rule := range rules {
+		if err := o.Registry.KVRepository().WithActor(actor).DeleteBucketRule(rule, actor); err != nil {
+			return err
+		}
+		fmt.Printf("Bucket rule '%s' deleted\n", rule)
+	}
+	return nil
+}
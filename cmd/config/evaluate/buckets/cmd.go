@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+	"github.com/kyma-incubator/reconciler/pkg/cluster"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmd(o *cli.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "buckets",
+		Aliases: []string{"bucket", "bu"},
+		Short:   "Evaluate the buckets of a cluster.",
+		Long:    `Evaluate which buckets (in merge order) are assigned to a cluster by the bucket rules.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("One cluster has to be provided: '%s'", strings.Join(args, "', '"))
+			}
+			return Run(o, args[0])
+		},
+	}
+	return cmd
+}
+
+func Run(o *cli.Options, clusterName string) error {
+	state, err := o.Registry.Inventory().GetLatest(clusterName)
+	if err != nil {
+		return err
+	}
+
+	configManager, err := cluster.NewConfigurationManager(o.Registry.KVRepository(), o.Registry.CacheRepository(),
+		viper.GetStringSlice("buckets"), o.Verbose)
+	if err != nil {
+		return err
+	}
+	buckets, err := configManager.EvaluateBuckets(state)
+	if err != nil {
+		return err
+	}
+
+	formatter, err := cli.NewOutputFormatter(o.OutputFormat)
+	if err != nil {
+		return err
+	}
+	if err := formatter.Header("Bucket", "Rule"); err != nil {
+		return err
+	}
+	for _, bucket := range buckets {
+		if err := formatter.AddRow(bucket.Bucket, bucket.Rule); err != nil {
+			return err
+		}
+	}
+	return formatter.Output(os.Stdout)
+}
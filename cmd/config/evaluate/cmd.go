@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd(o *cli.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "evaluate",
+		Short: "Evaluate the configuration of a cluster",
+	}
+
+	cmd.PersistentFlags().StringVarP(&o.OutputFormat, "output-format", "o", "table",
+		fmt.Sprintf("Define output formatting. Supported options are '%s'.", strings.Join(cli.SupportedOutputFormats, "', '")))
+
+	return cmd
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCmd(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export configuration keys and values.",
+		Long: `Export configuration keys and the values of buckets as YAML document.
+If no bucket is specified, all keys and buckets are exported. The document can be applied with 'config import'.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return Run(o)
+		},
+	}
+	cmd.Flags().StringSliceVar(&o.Buckets, "bucket", []string{}, "Bucket to export (all buckets are exported if undefined)")
+	cmd.Flags().StringVarP(&o.File, "file", "f", "", "File the document is written to (default is stdout)")
+	cmd.Flags().BoolVar(&o.IncludeEncrypted, "include-encrypted", false, "Export values of encrypted keys in plain text")
+	return cmd
+}
+
+func Run(o *Options) error {
+	doc, err := o.Registry.KVRepository().Export(o.Buckets, o.IncludeEncrypted)
+	if err != nil {
+		return err
+	}
+	data, err := doc.YAML()
+	if err != nil {
+		return err
+	}
+	if o.File == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := ioutil.WriteFile(o.File, data, 0600); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d keys and %d buckets to '%s'\n", len(doc.Keys), len(doc.Buckets), o.File)
+	return nil
+}
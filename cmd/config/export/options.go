@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/kyma-incubator/reconciler/internal/cli"
+)
+
+type Options struct {
+	*cli.Options
+	Buckets          []string
+	File             string
+	IncludeEncrypted bool
+}
+
+func NewOptions(o *cli.Options) *Options {
+	return &Options{o, []string{}, "", false}
+}
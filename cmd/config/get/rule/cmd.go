@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rule",
+		Aliases: []string{"rules", "ru"},
+		Short:   "Get bucket rules.",
+		Long:    `List bucket rules (ordered by their priority) or get particular bucket rules.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return Run(o, args)
+		},
+	}
+	cmd.Flags().BoolVar(&o.History, "history", false, "Show history of the bucket rules")
+	return cmd
+}
+
+func Run(o *Options, ruleFilter []string) error {
+	rules, err := o.Registry.KVRepository().BucketRules()
+	if err != nil {
+		return err
+	}
+
+	if len(ruleFilter) > 0 {
+		rules = filterRules(rules, ruleFilter)
+	}
+
+	if o.History {
+		var rulesHistory []*model.BucketRuleEntity
+		for _, rule := range rules {
+			ruleHistory, err := o.Registry.KVRepository().BucketRuleHistory(rule.Rule)
+			if err != nil {
+				return err
+			}
+			rulesHistory = append(rulesHistory, ruleHistory...)
+		}
+		rules = rulesHistory
+	}
+
+	return renderRules(o, rules)
+}
+
+func filterRules(rules []*model.BucketRuleEntity, ruleFilter []string) []*model.BucketRuleEntity {
+	filter := make(map[string]bool, len(ruleFilter))
+	for _, rule := range ruleFilter {
+		filter[rule] = true
+	}
+	filteredRules := []*model.BucketRuleEntity{}
+	for _, rule := range rules { //keep the priority order
+		if filter[rule.Rule] {
+			filteredRules = append(filteredRules, rule)
+		}
+	}
+	return filteredRules
+}
+
+func renderRules(o *Options, rules []*model.BucketRuleEntity) error {
+	formatter, err := cli.NewOutputFormatter(o.OutputFormat)
+	if err != nil {
+		return err
+	}
+
+	if err := formatter.Header("Rule", "Priority", "Selector", "Buckets", "Deleted",
+		"Created by", "Created at (UTC)", "Version"); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		selector, err := rule.GetSelector()
+		if err != nil {
+			return err
+		}
+		buckets, err := rule.GetBuckets()
+		if err != nil {
+			return err
+		}
+		if err := formatter.AddRow(rule.Rule, rule.Priority, selector, buckets, rule.Deleted,
+			rule.Username, rule.Created.Format(time.RFC822Z), rule.Version); err != nil {
+			return err
+		}
+	}
+	return formatter.Output(os.Stdout)
+}
@@ -55,6 +55,7 @@ func Run(o *Options, valueFilter []string) error {
 }
 
 func renderValues(o *Options, values []*model.ValueEntity) error {
+	keys := make(map[int64]*model.KeyEntity) //cache of the keys the values are mapped to (by key version)
 	formatter, err := cli.NewOutputFormatter(o.OutputFormat)
 	if err != nil {
 		return err
@@ -65,7 +66,16 @@ func renderValues(o *Options, values []*model.ValueEntity) error {
 		return err
 	}
 	for _, value := range values {
-		if err := formatter.AddRow(value.Bucket, value.Value, value.DataType, value.Username,
+		key, ok := keys[value.KeyVersion]
+		if !ok {
+			key, err = o.Registry.KVRepository().Key(value.Key, value.KeyVersion)
+			if err != nil {
+				return err
+			}
+			keys[value.KeyVersion] = key
+		}
+		//values of encrypted keys are masked
+		if err := formatter.AddRow(value.Bucket, key.Mask(value.Value), value.DataType, value.Username,
 			value.Created.Format(time.RFC822Z), value.Version); err != nil {
 			return err
 		}
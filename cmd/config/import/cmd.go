@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+	"github.com/kyma-incubator/reconciler/pkg/audit"
+	"github.com/kyma-incubator/reconciler/pkg/kv"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import configuration keys and values.",
+		Long: `Import configuration keys and bucket values from a YAML document (e.g. created by 'config export').
+All entries are validated before any change is applied. Changed keys and values are stored as new versions
+within one transaction. Keys and values which are not part of the document remain unchanged.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return Run(o)
+		},
+	}
+	cmd.Flags().StringVarP(&o.File, "file", "f", "", "YAML document to import")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "Show the differences to the current configuration without applying them")
+	cmd.Flags().StringVarP(&o.OutputFormat, "output-format", "o", "table",
+		fmt.Sprintf("Define output formatting. Supported options are '%s'.", strings.Join(cli.SupportedOutputFormats, "', '")))
+	if err := cobra.MarkFlagRequired(cmd.Flags(), "file"); err != nil {
+		panic(err) //would be an obvious bug and has to lead to a panic
+	}
+	return cmd
+}
+
+func Run(o *Options) error {
+	data, err := ioutil.ReadFile(o.File)
+	if err != nil {
+		return err
+	}
+	doc, err := kv.NewDocument(data)
+	if err != nil {
+		return err
+	}
+
+	var changes []*kv.Change
+	if o.DryRun {
+		changes, err = o.Registry.KVRepository().Diff(doc)
+	} else {
+		actor := audit.CLIActor()
+		changes, err = o.Registry.KVRepository().WithActor(actor).Import(doc, actor)
+	}
+	if err != nil {
+		return err
+	}
+
+	return renderChanges(o, changes)
+}
+
+func renderChanges(o *Options, changes []*kv.Change) error {
+	formatter, err := cli.NewOutputFormatter(o.OutputFormat)
+	if err != nil {
+		return err
+	}
+	if err := formatter.Header("Action", "Type", "Bucket", "Key", "Old", "New"); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if change.Action == kv.ChangeActionUnchanged {
+			continue
+		}
+		entryType := "value"
+		if change.IsKeyChange() {
+			entryType = "key"
+		}
+		if err := formatter.AddRow(change.Action, entryType, change.Bucket, change.Key, change.Old, change.New); err != nil {
+			return err
+		}
+	}
+	return formatter.Output(os.Stdout)
+}
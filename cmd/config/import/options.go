@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+	file "github.com/kyma-incubator/reconciler/pkg/files"
+)
+
+type Options struct {
+	*cli.Options
+	File   string
+	DryRun bool
+}
+
+func NewOptions(o *cli.Options) *Options {
+	return &Options{o, "", false}
+}
+
+func (o *Options) Validate() error {
+	if o.File == "" {
+		return fmt.Errorf("File to import has to be specified")
+	}
+	if !file.Exists(o.File) {
+		return fmt.Errorf("File '%s' not found", o.File)
+	}
+	return nil
+}
@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"path/filepath"
+	"time"
 
 	"github.com/kyma-incubator/reconciler/internal/cli"
 
 	"github.com/kyma-incubator/reconciler/pkg/cluster"
 	"github.com/kyma-incubator/reconciler/pkg/keb"
 	"github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/kyma-incubator/reconciler/pkg/model"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler"
 
 	//Register all reconcilers
@@ -16,10 +18,12 @@ import (
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/workspace"
 	"github.com/kyma-incubator/reconciler/pkg/scheduler"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 )
 
 const (
-	workspaceDir = ".workspace"
+	workspaceDir     = ".workspace"
+	localClusterName = "local"
 )
 
 func NewCmd(o *Options) *cobra.Command {
@@ -66,19 +70,55 @@ func RunLocal(o *Options) error {
 	}
 	defaultComponentsYaml := filepath.Join(ws.InstallationResourceDir, "components.yaml")
 
+	//the in-memory inventory tracks the status history of the local cluster
+	inventory := cluster.NewInMemoryInventory(nil)
 	workerFactory, _ := scheduler.NewLocalWorkerFactory(
-		&cluster.MockInventory{},
+		inventory,
 		scheduler.NewInMemoryOperationsRegistry(),
 		func(component string, status reconciler.Status) {
 			l.Infof("Component %s has status %s", component, status)
 		},
+		nil, //local installations have no configuration buckets
 		true)
 
-	ls := scheduler.NewLocalScheduler(workerFactory, scheduler.WithLogger(l))
-	return ls.Run(cli.NewContext(), &keb.Cluster{
+	localCluster := &keb.Cluster{
+		Cluster:    localClusterName,
 		Kubeconfig: o.kubeconfig,
 		KymaConfig: keb.KymaConfig{
 			Version:    o.version,
 			Profile:    o.profile,
-			Components: o.Components(defaultComponentsYaml)}})
+			Components: o.Components(defaultComponentsYaml)}}
+	state, err := inventory.CreateOrUpdate(1, localCluster)
+	if err != nil {
+		return err
+	}
+	if state, err = inventory.UpdateStatus(state.Status.ID, model.ClusterStatusReconciling); err != nil {
+		return err
+	}
+
+	ls := scheduler.NewLocalScheduler(workerFactory, scheduler.WithLogger(l))
+	runErr := ls.Run(cli.NewContext(), localCluster)
+
+	status := model.ClusterStatusReady
+	if runErr != nil {
+		status = model.ClusterStatusError
+	}
+	if _, err := inventory.UpdateStatus(state.Status.ID, status); err != nil {
+		l.Warnf("Failed to update status of cluster '%s' to '%s': %s", localClusterName, status, err)
+	}
+	logStatusChanges(l, inventory)
+
+	return runErr
+}
+
+//logStatusChanges logs the status history of the local cluster (oldest status first)
+func logStatusChanges(l *zap.SugaredLogger, inventory cluster.Inventory) {
+	changes, err := inventory.StatusChanges(localClusterName, 24*time.Hour)
+	if err != nil {
+		l.Warnf("Failed to retrieve status changes of cluster '%s': %s", localClusterName, err)
+		return
+	}
+	for idx := len(changes) - 1; idx >= 0; idx-- {
+		l.Infof("Cluster status '%s' (duration: %s)", changes[idx].Status.Status, changes[idx].Duration.Round(time.Second))
+	}
 }
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	auditCmd "github.com/kyma-incubator/reconciler/cmd/audit"
 	cfgCmd "github.com/kyma-incubator/reconciler/cmd/config"
 	localCmd "github.com/kyma-incubator/reconciler/cmd/local"
 	msCmd "github.com/kyma-incubator/reconciler/cmd/mothership"
@@ -34,6 +35,7 @@ func main() {
 	cmd.AddCommand(msCmd.NewCmd(o))
 	cmd.AddCommand(rclCmd.NewCmd(o))
 	cmd.AddCommand(localCmd.NewCmd(localCmd.NewOptions(o)))
+	cmd.AddCommand(auditCmd.NewCmd(o))
 
 	if err := cmd.Execute(); err != nil {
 		os.Exit(1)
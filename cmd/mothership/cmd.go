@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	encryptCmd "github.com/kyma-incubator/reconciler/cmd/mothership/encrypt"
 	installCmd "github.com/kyma-incubator/reconciler/cmd/mothership/install"
+	migrateCmd "github.com/kyma-incubator/reconciler/cmd/mothership/migrate"
+	rotateKeyCmd "github.com/kyma-incubator/reconciler/cmd/mothership/rotatekey"
 	startCmd "github.com/kyma-incubator/reconciler/cmd/mothership/start"
 	"github.com/kyma-incubator/reconciler/internal/cli"
 	"github.com/spf13/cobra"
@@ -16,6 +19,9 @@ func NewCmd(o *cli.Options) *cobra.Command {
 
 	cmd.AddCommand(startCmd.NewCmd(startCmd.NewOptions(o)))
 	cmd.AddCommand(installCmd.NewCmd(installCmd.NewOptions(o)))
+	cmd.AddCommand(migrateCmd.NewCmd(migrateCmd.NewOptions(o)))
+	cmd.AddCommand(rotateKeyCmd.NewCmd(rotateKeyCmd.NewOptions(o)))
+	cmd.AddCommand(encryptCmd.NewCmd(encryptCmd.NewOptions(o)))
 
 	return cmd
 }
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmd(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt data which is stored in plain text",
+		Long: "Encrypts the kubeconfigs of the cluster inventory which were stored in plain text by older versions " +
+			"of the mothership reconciler. Values encrypted with an unknown key are not touched.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return Run(o)
+		},
+	}
+	cmd.Flags().IntVar(&o.BatchSize, "batch-size", o.BatchSize, "Amount of values which are encrypted within one transaction")
+	return cmd
+}
+
+func Run(o *Options) error {
+	connFact, err := db.NewConnectionFactory(viper.ConfigFileUsed(), o.Verbose)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := connFact.Close(); err != nil {
+			o.Logger().Warnf("Failed to close database connection: %s", err)
+		}
+	}()
+	conn, err := connFact.NewConnection()
+	if err != nil {
+		return err
+	}
+
+	return db.NewKeyRotation(conn, o.Logger()).
+		WithBatchSize(o.BatchSize).
+		WithProgress(func(progress *db.RotationProgress) {
+			o.Logger().Infof("Encrypted %d of %d values of column '%s' in table '%s'",
+				progress.Done, progress.Total, progress.Column, progress.Table)
+		}).
+		Encrypt(&db.EncryptedColumn{Entity: &model.ClusterEntity{}, Field: "Kubeconfig", IDField: "Version"})
+}
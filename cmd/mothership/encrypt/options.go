@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+)
+
+type Options struct {
+	*cli.Options
+	BatchSize int
+}
+
+func NewOptions(o *cli.Options) *Options {
+	return &Options{o,
+		100, //BatchSize
+	}
+}
+
+func (o *Options) Validate() error {
+	if o.BatchSize <= 0 {
+		return fmt.Errorf("batch size has to be > 0 but was %d", o.BatchSize)
+	}
+	return o.Options.Validate()
+}
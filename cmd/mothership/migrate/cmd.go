@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmd(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate the database schema of the mothership reconciler",
+		Long: "Applies or reverts the schema migrations which are embedded in the reconciler. " +
+			"Pending migrations are also applied when the mothership reconciler starts.",
+	}
+	cmd.PersistentFlags().StringVarP(&o.OutputFormat, "output-format", "o", "table",
+		fmt.Sprintf("Define output formatting. Supported options are '%s'.", strings.Join(cli.SupportedOutputFormats, "', '")))
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return Up(o.Options)
+		},
+	})
+
+	downCmd := &cobra.Command{
+		Use:   "down",
+		Short: "Revert the latest applied migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return Down(o)
+		},
+	}
+	downCmd.Flags().IntVar(&o.Steps, "steps", o.Steps, "Number of migrations to revert")
+	cmd.AddCommand(downCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show the applied and pending migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return Status(o)
+		},
+	})
+
+	return cmd
+}
+
+//Up applies all pending migrations to the configured database
+func Up(o *cli.Options) error {
+	return withMigrator(o, func(migrator *db.Migrator) error {
+		count, err := migrator.Up()
+		if err == nil {
+			o.Logger().Infof("Applied %d database migrations", count)
+		}
+		return err
+	})
+}
+
+func Down(o *Options) error {
+	return withMigrator(o.Options, func(migrator *db.Migrator) error {
+		count, err := migrator.Down(o.Steps)
+		if err == nil {
+			o.Logger().Infof("Reverted %d database migrations", count)
+		}
+		return err
+	})
+}
+
+func Status(o *Options) error {
+	return withMigrator(o.Options, func(migrator *db.Migrator) error {
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		formatter, err := cli.NewOutputFormatter(o.OutputFormat)
+		if err != nil {
+			return err
+		}
+		if err := formatter.Header("Version", "Name", "Applied", "Created"); err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			var created string
+			if status.Applied {
+				created = status.Created.String()
+			}
+			if err := formatter.AddRow(status.Version, status.Name, status.Applied, created); err != nil {
+				return err
+			}
+		}
+		return formatter.Output(os.Stdout)
+	})
+}
+
+func withMigrator(o *cli.Options, callback func(migrator *db.Migrator) error) error {
+	connFact, err := db.NewConnectionFactory(viper.ConfigFileUsed(), o.Verbose)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := connFact.Close(); err != nil {
+			o.Logger().Warnf("Failed to close database connection: %s", err)
+		}
+	}()
+	conn, err := connFact.NewConnection()
+	if err != nil {
+		return err
+	}
+	migrator, err := db.NewMigrator(conn, o.Logger())
+	if err != nil {
+		return err
+	}
+	return callback(migrator)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+)
+
+type Options struct {
+	*cli.Options
+	Steps int
+}
+
+func NewOptions(o *cli.Options) *Options {
+	return &Options{o,
+		1, //Steps
+	}
+}
+
+func (o *Options) Validate() error {
+	if o.Steps <= 0 {
+		return fmt.Errorf("steps have to be > 0 but was %d", o.Steps)
+	}
+	return o.Options.Validate()
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/kyma-incubator/reconciler/internal/cli"
+	"github.com/kyma-incubator/reconciler/pkg/cache"
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmd(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Rotate the encryption key of the mothership reconciler",
+		Long: "Creates a new encryption key (the previous key is kept as backup) and re-encrypts all encrypted data " +
+			"in the database with it. Restart the mothership reconciler afterwards to use the new key.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return Run(o)
+		},
+	}
+	cmd.Flags().BoolVar(&o.CreateKey, "create-key", o.CreateKey, "Create a new encryption key before the data gets re-encrypted "+
+		"(disable it to continue an interrupted rotation)")
+	cmd.Flags().IntVar(&o.BatchSize, "batch-size", o.BatchSize, "Amount of values which are re-encrypted within one transaction")
+	return cmd
+}
+
+func Run(o *Options) error {
+	if o.CreateKey {
+		encKeyFile, err := cli.NewEncryptionKey(true)
+		if err != nil {
+			o.Logger().Warnf("Failed to create encryption key file '%s'", encKeyFile)
+			return err
+		}
+		o.Logger().Infof("New encryption key file created: %s", encKeyFile)
+	}
+
+	//the connection factory reads the new key and the previous keys from the backups of the key file
+	connFact, err := db.NewConnectionFactory(viper.ConfigFileUsed(), o.Verbose)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := connFact.Close(); err != nil {
+			o.Logger().Warnf("Failed to close database connection: %s", err)
+		}
+	}()
+	conn, err := connFact.NewConnection()
+	if err != nil {
+		return err
+	}
+	previousKeyIDs := conn.Encryptor().PreviousKeyIDs()
+
+	err = db.NewKeyRotation(conn, o.Logger()).
+		WithBatchSize(o.BatchSize).
+		WithProgress(func(progress *db.RotationProgress) {
+			o.Logger().Infof("Re-encrypted %d of %d values of column '%s' in table '%s'",
+				progress.Done, progress.Total, progress.Column, progress.Table)
+		}).
+		Rotate(
+			&db.EncryptedColumn{Entity: &model.ClusterEntity{}, Field: "Kubeconfig", IDField: "Version"},
+			&db.EncryptedColumn{Entity: &model.ValueEntity{}, Field: "Value", IDField: "Version"},
+		)
+	if err != nil {
+		return err
+	}
+
+	return invalidateCache(o, connFact, previousKeyIDs)
+}
+
+//invalidateCache drops cache entries which contain values encrypted with a previous key:
+//they will be re-calculated with the new key during the next reconciliation of the cluster
+func invalidateCache(o *Options, connFact db.ConnectionFactory, previousKeyIDs []string) error {
+	cacheRepo, err := cache.NewRepository(connFact, o.Verbose)
+	if err != nil {
+		return err
+	}
+
+	cacheEntries, err := cacheRepo.All()
+	if err != nil {
+		return err
+	}
+	for _, cacheEntry := range cacheEntries {
+		for _, previousKeyID := range previousKeyIDs {
+			if strings.Contains(cacheEntry.Data, previousKeyID) {
+				if err := cacheRepo.InvalidateByID(cacheEntry.ID); err != nil {
+					return err
+				}
+				o.Logger().Infof("Invalidated cache entry '%s' of cluster '%s' which contained values encrypted with a previous key",
+					cacheEntry.Label, cacheEntry.Cluster)
+				break
+			}
+		}
+	}
+	return nil
+}
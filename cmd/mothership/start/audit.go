@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kyma-incubator/reconciler/pkg/audit"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	//query parameters of the audit log listing
+	paramSince      = "since"
+	paramActor      = "actor"
+	paramEntityType = "entityType"
+	paramTarget     = "target"
+	paramLimit      = "limit"
+
+	defaultAuditSince = "24h"
+)
+
+type auditConfig struct {
+	ActorHeader string //header which contains the caller identity (set by an authenticating proxy)
+	LogSink     bool   //export audit entries to the log
+}
+
+func parseAuditConfig(configFile string) (*auditConfig, error) {
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	return &auditConfig{
+		ActorHeader: viper.GetString("mothership.audit.actorHeader"),
+		LogSink:     viper.GetBool("mothership.audit.logSink"),
+	}, nil
+}
+
+func setupAudit(o *Options, cfg *auditConfig) {
+	o.AuditActorHeader = cfg.ActorHeader
+	if cfg.LogSink {
+		audit.RegisterSink(audit.NewLogSink(o.Logger()))
+	}
+}
+
+//actor returns the caller identity of a request (used to record its writes in the audit log)
+func actor(o *Options, r *http.Request) string {
+	return audit.RequestActor(r, o.AuditActorHeader)
+}
+
+func listAuditEntries(o *Options, w http.ResponseWriter, r *http.Request) {
+	filter, err := newAuditFilter(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, err)
+		return
+	}
+	entries, err := o.Registry.AuditRepository().List(filter)
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, errors.Wrap(err, "Could not list audit entries"))
+		return
+	}
+	resp := audit.HTTPAuditResponse{
+		Entries: []*audit.HTTPAuditEntry{},
+	}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, audit.NewHTTPAuditEntry(entry))
+	}
+	sendJSON(w, resp)
+}
+
+//newAuditFilter converts the query parameters of an audit log request to a filter
+func newAuditFilter(r *http.Request) (*audit.Filter, error) {
+	query := r.URL.Query()
+	filter := &audit.Filter{
+		Actor:      query.Get(paramActor),
+		EntityType: query.Get(paramEntityType),
+		Target:     query.Get(paramTarget),
+	}
+
+	since := query.Get(paramSince)
+	if since == "" {
+		since = defaultAuditSince
+	}
+	var err error
+	if filter.Since, err = audit.ParseSince(since); err != nil {
+		return nil, errors.Wrapf(err, "Parameter '%s' is invalid", paramSince)
+	}
+
+	if limit := query.Get(paramLimit); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			return nil, fmt.Errorf("parameter '%s' has to be a positive number", paramLimit)
+		}
+	}
+	return filter, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	migrateCmd "github.com/kyma-incubator/reconciler/cmd/mothership/migrate"
 	"github.com/kyma-incubator/reconciler/internal/cli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -29,6 +30,13 @@ func NewCmd(o *Options) *cobra.Command {
 				}
 			}
 
+			//schema has to be up to date before the application registry accesses the database
+			if o.Migrate {
+				if err := migrateCmd.Up(o.Options); err != nil {
+					return err
+				}
+			}
+
 			if err := o.InitApplicationRegistry(true); err != nil {
 				return err
 			}
@@ -43,10 +51,22 @@ func NewCmd(o *Options) *cobra.Command {
 	cmd.Flags().DurationVarP(&o.ClusterReconcileInterval, "reconcile-interval", "", 5*time.Minute, "Defines the time when a cluster will to be reconciled since his last successful reconciliation")
 	cmd.Flags().StringVar(&o.ReconcilersCfgPath, "reconcilers", "", "Path to component reconcilers configuration file")
 	cmd.Flags().BoolVar(&o.CreateEncyptionKey, "create-encryption-key", false, "Create new encryption key file during startup")
+	cmd.Flags().BoolVar(&o.Migrate, "migrate", true, "Apply pending database schema migrations during startup")
 	return cmd
 }
 
 func Run(ctx context.Context, o *Options) error {
+	//parse retention and audit config before the scheduler starts (all re-read the config file)
+	retentionCfg, err := parseRetentionConfig(viper.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+	auditCfg, err := parseAuditConfig(viper.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+	setupAudit(o, auditCfg)
+
 	go func(ctx context.Context, o *Options) {
 		err := startScheduler(ctx, o, viper.ConfigFileUsed())
 		if err != nil {
@@ -54,5 +74,11 @@ func Run(ctx context.Context, o *Options) error {
 		}
 	}(ctx, o)
 
+	go func(ctx context.Context, o *Options) {
+		if err := startRetentionJob(ctx, o, retentionCfg); err != nil {
+			o.Logger().Errorf("Retention job stopped: %s", err)
+		}
+	}(ctx, o)
+
 	return startWebserver(ctx, o)
 }
@@ -47,6 +47,14 @@ var (
 		require.NoError(t, err)
 	}
 
+	requireClustersResponseFct = func(t *testing.T, response interface{}) {
+		respModel := response.(*keb.HTTPClustersResponse)
+		require.NotEmpty(t, respModel.Clusters)
+		for _, cluster := range respModel.Clusters {
+			requireClusterResponseFct(t, cluster)
+		}
+	}
+
 	requireClusterStatusResponseFct = func(t *testing.T, response interface{}) {
 		respModel := response.(*keb.HTTPClusterStatusResponse)
 		require.Len(t, respModel.StatusChanges, 1)
@@ -155,6 +163,30 @@ func TestReconciliation(t *testing.T) {
 			responseModel:    &keb.HTTPErrorResponse{},
 			verifier:         requireErrorResponseFct,
 		},
+		{
+			name:             "List clusters: happy path",
+			url:              fmt.Sprintf("%s/%s?status=reconcile_pending,reconciling&pageSize=10", baseURL, "clusters"),
+			method:           httpGet,
+			expectedHTTPCode: 200,
+			responseModel:    &keb.HTTPClustersResponse{},
+			verifier:         requireClustersResponseFct,
+		},
+		{
+			name:             "List clusters: using invalid status",
+			url:              fmt.Sprintf("%s/%s?status=idontexist", baseURL, "clusters"),
+			method:           httpGet,
+			expectedHTTPCode: 400,
+			responseModel:    &keb.HTTPErrorResponse{},
+			verifier:         requireErrorResponseFct,
+		},
+		{
+			name:             "List clusters: using invalid page",
+			url:              fmt.Sprintf("%s/%s?page=%%25%%25", baseURL, "clusters"),
+			method:           httpGet,
+			expectedHTTPCode: 400,
+			responseModel:    &keb.HTTPErrorResponse{},
+			verifier:         requireErrorResponseFct,
+		},
 		{
 			name:             "Get list of status changes: without offset",
 			url:              fmt.Sprintf("%s/%s/statusChanges", fmt.Sprintf("%s/%s", baseURL, "clusters"), clusterName),
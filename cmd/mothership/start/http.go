@@ -7,6 +7,8 @@ import (
 	"github.com/kyma-incubator/reconciler/pkg/scheduler"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kyma-incubator/reconciler/pkg/kubernetes"
@@ -15,11 +17,13 @@ import (
 	"github.com/kyma-incubator/reconciler/pkg/cluster"
 	"github.com/kyma-incubator/reconciler/pkg/keb"
 	"github.com/kyma-incubator/reconciler/pkg/metrics"
+	"github.com/kyma-incubator/reconciler/pkg/model"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler"
 	"github.com/kyma-incubator/reconciler/pkg/repository"
 	"github.com/kyma-incubator/reconciler/pkg/server"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/viper"
 )
 
 const (
@@ -29,6 +33,19 @@ const (
 	paramOffset          = "offset"
 	paramSchedulingID    = "schedulingID"
 	paramCorrelationID   = "correlationID"
+	paramBucketRule      = "rule"
+
+	//query parameters of the cluster listing
+	paramStatus          = "status"
+	paramKymaVersion     = "kymaVersion"
+	paramKymaProfile     = "kymaProfile"
+	paramGlobalAccountID = "globalAccountID"
+	paramSubAccountID    = "subAccountID"
+	paramServicePlanID   = "servicePlanID"
+	paramUpdatedAfter    = "updatedAfter"
+	paramUpdatedBefore   = "updatedBefore"
+	paramPage            = "page"
+	paramPageSize        = "pageSize"
 )
 
 func startWebserver(ctx context.Context, o *Options) error {
@@ -39,6 +56,11 @@ func startWebserver(ctx context.Context, o *Options) error {
 		callHandler(o, createOrUpdateCluster)).
 		Methods("PUT", "POST")
 
+	router.HandleFunc(
+		fmt.Sprintf("/v{%s}/clusters", paramContractVersion), //supports filter- and pagination-params
+		callHandler(o, listClusters)).
+		Methods("GET")
+
 	router.HandleFunc(
 		fmt.Sprintf("/v{%s}/clusters/{%s}", paramContractVersion, paramCluster),
 		callHandler(o, deleteCluster)).
@@ -59,15 +81,44 @@ func startWebserver(ctx context.Context, o *Options) error {
 		callHandler(o, statusChanges)).
 		Methods("GET")
 
+	router.HandleFunc(
+		fmt.Sprintf("/v{%s}/clusters/{%s}/buckets", paramContractVersion, paramCluster),
+		callHandler(o, getClusterBuckets)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/v{%s}/bucketRules", paramContractVersion),
+		callHandler(o, getBucketRules)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/v{%s}/bucketRules", paramContractVersion),
+		callHandler(o, createOrUpdateBucketRule)).
+		Methods("PUT", "POST")
+
+	router.HandleFunc(
+		fmt.Sprintf("/v{%s}/bucketRules/{%s}", paramContractVersion, paramBucketRule),
+		callHandler(o, deleteBucketRule)).
+		Methods("DELETE")
+
 	router.HandleFunc(
 		fmt.Sprintf("/v{%s}/operations/{%s}/callback/{%s}", paramContractVersion, paramSchedulingID, paramCorrelationID),
 		callHandler(o, operationCallback)).
 		Methods("POST")
 
+	router.HandleFunc(
+		fmt.Sprintf("/v{%s}/audit", paramContractVersion), //supports since-, actor-, entityType-, target- and limit-params
+		callHandler(o, listAuditEntries)).
+		Methods("GET")
+
 	//metrics endpoint
-	metrics.RegisterAll(o.Registry.Inventory(), o.Logger())
+	metrics.RegisterAll(o.Registry.Inventory(), o.Registry.ConnectionFactory(), o.Logger())
 	router.Handle("/metrics", promhttp.Handler())
 
+	//health endpoint
+	router.HandleFunc("/health", callHandler(o, health)).
+		Methods("GET")
+
 	//start server process
 	srv := &server.Webserver{
 		Logger:     o.Logger(),
@@ -106,7 +157,7 @@ func createOrUpdateCluster(o *Options, w http.ResponseWriter, r *http.Request) {
 		sendError(w, http.StatusBadRequest, errors.Wrap(err, "kubeconfig not accepted"))
 		return
 	}
-	clusterState, err := o.Registry.Inventory().CreateOrUpdate(contractV, clusterModel)
+	clusterState, err := o.Registry.Inventory().WithActor(actor(o, r)).CreateOrUpdate(contractV, clusterModel)
 	if err != nil {
 		sendError(w, http.StatusInternalServerError, errors.Wrap(err, "Failed to create or update cluster entity"))
 		return
@@ -160,6 +211,89 @@ func getLatestCluster(o *Options, w http.ResponseWriter, r *http.Request) {
 	sendResponse(w, r, clusterState)
 }
 
+func listClusters(o *Options, w http.ResponseWriter, r *http.Request) {
+	filter, page, err := newListFilter(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, err)
+		return
+	}
+	result, err := o.Registry.Inventory().List(filter, page)
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if cluster.IsInvalidCursorError(err) {
+			httpCode = http.StatusBadRequest
+		}
+		sendError(w, httpCode, errors.Wrap(err, "Could not list clusters"))
+		return
+	}
+	resp := keb.HTTPClustersResponse{
+		Clusters: []*keb.HTTPClusterResponse{},
+		NextPage: result.NextCursor,
+	}
+	for _, clusterState := range result.States {
+		kebStatus, err := clusterState.Status.GetKEBClusterStatus()
+		if err != nil {
+			sendError(w, http.StatusInternalServerError,
+				errors.Wrap(err, "Failed to map reconciler internal cluster status to KEB cluster status"))
+			return
+		}
+		resp.Clusters = append(resp.Clusters, &keb.HTTPClusterResponse{
+			Cluster:              clusterState.Cluster.Cluster,
+			ClusterVersion:       clusterState.Cluster.Version,
+			ConfigurationVersion: clusterState.Configuration.Version,
+			Status:               kebStatus,
+			StatusURL: fmt.Sprintf("%s%s/%s/configs/%d/status", r.Host, r.URL.Path,
+				clusterState.Cluster.Cluster, clusterState.Configuration.Version),
+		})
+	}
+	sendJSON(w, resp)
+}
+
+//newListFilter converts the query parameters of a cluster listing request to a filter and a page
+func newListFilter(r *http.Request) (*cluster.ListFilter, *cluster.Page, error) {
+	query := r.URL.Query()
+	filter := &cluster.ListFilter{
+		KymaVersion:     query.Get(paramKymaVersion),
+		KymaProfile:     query.Get(paramKymaProfile),
+		GlobalAccountID: query.Get(paramGlobalAccountID),
+		SubAccountID:    query.Get(paramSubAccountID),
+		ServicePlanID:   query.Get(paramServicePlanID),
+	}
+
+	//statuses can be provided as comma separated list or by repeating the parameter
+	for _, statuses := range query[paramStatus] {
+		for _, status := range strings.Split(statuses, ",") {
+			clusterStatus, err := model.NewClusterStatus(model.Status(strings.TrimSpace(status)))
+			if err != nil {
+				return nil, nil, err
+			}
+			filter.Statuses = append(filter.Statuses, clusterStatus.Status)
+		}
+	}
+
+	var err error
+	if updatedAfter := query.Get(paramUpdatedAfter); updatedAfter != "" {
+		if filter.UpdatedAfter, err = time.Parse(time.RFC3339, updatedAfter); err != nil {
+			return nil, nil, errors.Wrapf(err, "Parameter '%s' is not a RFC3339 timestamp", paramUpdatedAfter)
+		}
+	}
+	if updatedBefore := query.Get(paramUpdatedBefore); updatedBefore != "" {
+		if filter.UpdatedBefore, err = time.Parse(time.RFC3339, updatedBefore); err != nil {
+			return nil, nil, errors.Wrapf(err, "Parameter '%s' is not a RFC3339 timestamp", paramUpdatedBefore)
+		}
+	}
+
+	page := &cluster.Page{
+		Cursor: query.Get(paramPage),
+	}
+	if pageSize := query.Get(paramPageSize); pageSize != "" {
+		if page.Size, err = strconv.Atoi(pageSize); err != nil || page.Size <= 0 {
+			return nil, nil, fmt.Errorf("parameter '%s' has to be a positive number (max. %d)", paramPageSize, cluster.MaxPageSize)
+		}
+	}
+	return filter, page, nil
+}
+
 func statusChanges(o *Options, w http.ResponseWriter, r *http.Request) {
 	params := server.NewParams(r)
 
@@ -226,12 +360,140 @@ func deleteCluster(o *Options, w http.ResponseWriter, r *http.Request) {
 		sendError(w, http.StatusNotFound, errors.Wrap(err, fmt.Sprintf("Deletion impossible: Cluster '%s' not found", clusterName)))
 		return
 	}
-	if err := o.Registry.Inventory().Delete(clusterName); err != nil {
+	if err := o.Registry.Inventory().WithActor(actor(o, r)).Delete(clusterName); err != nil {
 		sendError(w, http.StatusInternalServerError, errors.Wrap(err, fmt.Sprintf("Failed to delete cluster '%s'", clusterName)))
 		return
 	}
 }
 
+func getClusterBuckets(o *Options, w http.ResponseWriter, r *http.Request) {
+	params := server.NewParams(r)
+	clusterName, err := params.String(paramCluster)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, err)
+		return
+	}
+	clusterState, err := o.Registry.Inventory().GetLatest(clusterName)
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if repository.IsNotFoundError(err) {
+			httpCode = http.StatusNotFound
+		}
+		sendError(w, httpCode, errors.Wrap(err, "Could not retrieve cluster state"))
+		return
+	}
+	configManager, err := cluster.NewConfigurationManager(o.Registry.KVRepository(), o.Registry.CacheRepository(),
+		viper.GetStringSlice("buckets"), o.Verbose)
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, errors.Wrap(err, "Failed to create configuration manager"))
+		return
+	}
+	buckets, err := configManager.EvaluateBuckets(clusterState)
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, errors.Wrap(err, fmt.Sprintf("Failed to evaluate buckets of cluster '%s'", clusterName)))
+		return
+	}
+
+	resp := keb.HTTPClusterBucketsResponse{
+		Cluster: clusterName,
+		Buckets: []*keb.HTTPClusterBucket{},
+	}
+	for _, bucket := range buckets {
+		resp.Buckets = append(resp.Buckets, &keb.HTTPClusterBucket{
+			Bucket: bucket.Bucket,
+			Rule:   bucket.Rule,
+		})
+	}
+	sendJSON(w, resp)
+}
+
+func getBucketRules(o *Options, w http.ResponseWriter, r *http.Request) {
+	rules, err := o.Registry.KVRepository().BucketRules()
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, errors.Wrap(err, "Could not retrieve bucket rules"))
+		return
+	}
+	resp := keb.HTTPBucketRulesResponse{
+		Rules: []*keb.HTTPBucketRule{},
+	}
+	for _, rule := range rules {
+		httpRule, err := newBucketRuleResponse(rule)
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, err)
+			return
+		}
+		resp.Rules = append(resp.Rules, httpRule)
+	}
+	sendJSON(w, resp)
+}
+
+func createOrUpdateBucketRule(o *Options, w http.ResponseWriter, r *http.Request) {
+	reqBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, errors.Wrap(err, "Failed to read received JSON payload"))
+		return
+	}
+	var httpRule keb.HTTPBucketRule
+	if err := json.Unmarshal(reqBody, &httpRule); err != nil {
+		sendError(w, http.StatusBadRequest, errors.Wrap(err, "Failed to unmarshal JSON payload"))
+		return
+	}
+	caller := actor(o, r)
+	rule, err := model.NewBucketRuleEntity(httpRule.Rule, httpRule.Priority, httpRule.Selector, httpRule.Buckets, caller)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, errors.Wrap(err, "Bucket rule not accepted"))
+		return
+	}
+	rule, err = o.Registry.KVRepository().WithActor(caller).CreateBucketRule(rule)
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, errors.Wrap(err, "Failed to create or update bucket rule"))
+		return
+	}
+	resp, err := newBucketRuleResponse(rule)
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+	sendJSON(w, resp)
+}
+
+func deleteBucketRule(o *Options, w http.ResponseWriter, r *http.Request) {
+	params := server.NewParams(r)
+	ruleName, err := params.String(paramBucketRule)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, err)
+		return
+	}
+	caller := actor(o, r)
+	if err := o.Registry.KVRepository().WithActor(caller).DeleteBucketRule(ruleName, caller); err != nil {
+		httpCode := http.StatusInternalServerError
+		if repository.IsNotFoundError(err) {
+			httpCode = http.StatusNotFound
+		}
+		sendError(w, httpCode, errors.Wrap(err, fmt.Sprintf("Failed to delete bucket rule '%s'", ruleName)))
+		return
+	}
+}
+
+func newBucketRuleResponse(rule *model.BucketRuleEntity) (*keb.HTTPBucketRule, error) {
+	selector, err := rule.GetSelector()
+	if err != nil {
+		return nil, err
+	}
+	buckets, err := rule.GetBuckets()
+	if err != nil {
+		return nil, err
+	}
+	return &keb.HTTPBucketRule{
+		Rule:     rule.Rule,
+		Priority: rule.Priority,
+		Selector: selector,
+		Buckets:  buckets,
+		Version:  rule.Version,
+		Created:  rule.Created,
+	}, nil
+}
+
 func operationCallback(o *Options, w http.ResponseWriter, r *http.Request) {
 	params := server.NewParams(r)
 	schedulingID, err := params.String(paramSchedulingID)
@@ -263,13 +525,14 @@ func operationCallback(o *Options, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	operations := o.Registry.OperationsRegistry().WithActor(actor(o, r))
 	switch body.Status {
 	case string(reconciler.NotStarted), string(reconciler.Running):
-		err = o.Registry.OperationsRegistry().SetInProgress(correlationID, schedulingID)
+		err = operations.SetInProgress(correlationID, schedulingID)
 	case string(reconciler.Success):
-		err = o.Registry.OperationsRegistry().SetDone(correlationID, schedulingID)
+		err = operations.SetDone(correlationID, schedulingID)
 	case string(reconciler.Error):
-		err = o.Registry.OperationsRegistry().SetError(correlationID, schedulingID, "Reconciler reported error status")
+		err = operations.SetError(correlationID, schedulingID, "Reconciler reported error status")
 	}
 	if err != nil {
 		httpCode := http.StatusBadRequest
@@ -281,6 +544,14 @@ func operationCallback(o *Options, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func health(o *Options, w http.ResponseWriter, r *http.Request) {
+	if err := o.Registry.ConnectionFactory().Ping(); err != nil {
+		sendError(w, http.StatusServiceUnavailable, errors.Wrap(err, "Database is not reachable"))
+		return
+	}
+	sendJSON(w, map[string]string{"status": "ok"})
+}
+
 func sendError(w http.ResponseWriter, httpCode int, response interface{}) {
 	if err, ok := response.(error); ok { //convert to error response
 		response = reconciler.HTTPErrorResponse{
@@ -296,6 +567,13 @@ func sendError(w http.ResponseWriter, httpCode int, response interface{}) {
 	}
 }
 
+func sendJSON(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		sendError(w, http.StatusInternalServerError, errors.Wrap(err, "Failed to encode response payload to JSON"))
+	}
+}
+
 func sendResponse(w http.ResponseWriter, r *http.Request, clusterState *cluster.State) {
 	respModel, err := newClusterResponse(r, clusterState)
 	if err != nil {
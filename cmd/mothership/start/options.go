@@ -19,6 +19,8 @@ type Options struct {
 	ClusterReconcileInterval time.Duration
 	ReconcilersCfgPath       string
 	CreateEncyptionKey       bool
+	Migrate                  bool
+	AuditActorHeader         string
 }
 
 func NewOptions(o *cli.Options) *Options {
@@ -30,7 +32,9 @@ func NewOptions(o *cli.Options) *Options {
 		0 * time.Second, //WatchInterval
 		0 * time.Second, //ClusterReconcileInterval
 		"",              //ReconcilersCfg
-		false,
+		false,           //CreateEncyptionKey
+		true,            //Migrate
+		"",              //AuditActorHeader
 	}
 }
 
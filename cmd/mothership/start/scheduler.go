@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io/ioutil"
 
+	"github.com/kyma-incubator/reconciler/pkg/cluster"
+	"github.com/kyma-incubator/reconciler/pkg/metrics"
 	"github.com/kyma-incubator/reconciler/pkg/scheduler"
 	"github.com/spf13/viper"
 )
@@ -33,11 +35,22 @@ func startScheduler(ctx context.Context, o *Options, configFile string) error {
 		return err
 	}
 
+	configManager, err := cluster.NewConfigurationManager(
+		o.Registry.KVRepository(),
+		o.Registry.CacheRepository(),
+		mothershipCfg.Buckets,
+		o.Verbose,
+	)
+	if err != nil {
+		return err
+	}
+
 	workerFactory, err := scheduler.NewRemoteWorkerFactory(
 		o.Registry.Inventory(),
 		reconcilersCfg,
 		mothershipCfg,
 		o.Registry.OperationsRegistry(),
+		configManager,
 		o.Verbose,
 	)
 	if err != nil {
@@ -47,6 +60,7 @@ func startScheduler(ctx context.Context, o *Options, configFile string) error {
 	remoteScheduler, err := scheduler.NewRemoteScheduler(
 		inventoryWatch,
 		workerFactory,
+		o.Registry.OperationsRegistry(),
 		mothershipCfg,
 		o.Workers,
 		o.Verbose,
@@ -58,6 +72,20 @@ func startScheduler(ctx context.Context, o *Options, configFile string) error {
 	return remoteScheduler.Run(ctx)
 }
 
+func startRetentionJob(ctx context.Context, o *Options, retentionCfg *scheduler.RetentionConfig) error {
+	retentionJob, err := scheduler.NewRetentionJob(
+		o.Registry.Inventory(),
+		metrics.NewRetentionCollector(),
+		o.Verbose,
+		retentionCfg,
+	)
+	if err != nil {
+		return err
+	}
+
+	return retentionJob.Run(ctx)
+}
+
 func parseMothershipReconcilerConfig(configFile string) (scheduler.MothershipReconcilerConfig, error) {
 	viper.SetConfigFile(configFile)
 	if err := viper.ReadInConfig(); err != nil {
@@ -69,7 +97,23 @@ func parseMothershipReconcilerConfig(configFile string) (scheduler.MothershipRec
 		Host:          viper.GetString("mothership.host"),
 		Port:          viper.GetInt("mothership.port"),
 		CrdComponents: viper.GetStringSlice("crdComponents"),
-		PreComponents: viper.GetStringSlice("preComponents")}, nil
+		PreComponents: viper.GetStringSlice("preComponents"),
+		Buckets:       viper.GetStringSlice("buckets")}, nil
+}
+
+func parseRetentionConfig(configFile string) (*scheduler.RetentionConfig, error) {
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	return &scheduler.RetentionConfig{
+		Interval: viper.GetDuration("mothership.retention.interval"),
+		Policy: cluster.RetentionPolicy{
+			StatusMaxAge:       viper.GetDuration("mothership.retention.statusMaxAge"),
+			KeepConfigs:        viper.GetInt("mothership.retention.keepConfigs"),
+			DeletedGracePeriod: viper.GetDuration("mothership.retention.deletedGracePeriod"),
+		}}, nil
 }
 
 func parseComponentReconcilersConfig(path string) (scheduler.ComponentReconcilersConfig, error) {
@@ -61,6 +61,18 @@ func NewCmd(o *cli.Options) *cobra.Command {
 	cmd.PersistentFlags().StringVar(&reconcilerOpts.Workspace, "workspace", ".",
 		"Workspace directory used to cache Kyma sources")
 
+	//cache of rendered manifests
+	cmd.PersistentFlags().StringVar(&reconcilerOpts.ManifestCacheConfig.Dir, "manifest-cache-dir", "",
+		"Directory used to cache rendered manifests (default is '<workspace>/.manifest-cache')")
+	cmd.PersistentFlags().IntVar(&reconcilerOpts.ManifestCacheConfig.MaxEntries, "manifest-cache-size", 500,
+		"Maximal number of rendered manifests kept in the memory and in the disk cache")
+
+	//optional deployment steps
+	cmd.PersistentFlags().BoolVar(&reconcilerOpts.DeploymentConfig.ClusterCapabilities, "cluster-capabilities", false,
+		"Render charts against the capabilities of the target cluster and validate the resources before they get deployed")
+	cmd.PersistentFlags().BoolVar(&reconcilerOpts.DeploymentConfig.HelmReleases, "helm-releases", false,
+		"Record HELM release secrets of deployed components to keep HELM based tooling working")
+
 	startCommand := startCmd.NewCmd()
 	cmd.AddCommand(startCommand)
 	//register component reconcilers in start command:
@@ -4,21 +4,16 @@ import (
 	"fmt"
 	"github.com/kyma-incubator/reconciler/pkg/db"
 	file "github.com/kyma-incubator/reconciler/pkg/files"
-	"github.com/spf13/viper"
 	"io/ioutil"
 	"os"
-	"path/filepath"
 	"time"
 )
 
 func NewEncryptionKey(backup bool) (string, error) {
-	keyFile := viper.GetString("db.encryption.keyFile")
+	keyFile := db.EncryptionKeyFile() //absolute path (if relative, the config-file location is used as parent-dir)
 	if keyFile == "" {
 		return keyFile, fmt.Errorf("encryption key file not configured")
 	}
-	if !filepath.IsAbs(keyFile) { //ensure key file path is absolute (if not, use config-file location as parent-dir)
-		keyFile = filepath.Join(filepath.Dir(viper.ConfigFileUsed()), keyFile)
-	}
 
 	encKey, err := db.NewEncryptionKey()
 	if err != nil {
@@ -26,7 +21,7 @@ func NewEncryptionKey(backup bool) (string, error) {
 	}
 
 	if file.Exists(keyFile) && backup {
-		keyFileBackup := fmt.Sprintf("%s.%d.bak", keyFile, time.Now().Unix())
+		keyFileBackup := fmt.Sprintf("%s.%d%s", keyFile, time.Now().Unix(), db.EncryptionKeyBackupSuffix)
 		if err := os.Rename(keyFile, keyFileBackup); err != nil {
 			return keyFile, err
 		}
@@ -0,0 +1,7 @@
+package reconciler
+
+//DeploymentConfig enables optional steps of the default deployment of a component
+type DeploymentConfig struct {
+	ClusterCapabilities bool //render and validate charts against the capabilities of the target cluster
+	HelmReleases        bool //record HELM release secrets of the deployed components
+}
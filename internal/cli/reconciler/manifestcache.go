@@ -0,0 +1,17 @@
+package reconciler
+
+import (
+	"fmt"
+)
+
+type ManifestCacheConfig struct {
+	Dir        string
+	MaxEntries int
+}
+
+func (c *ManifestCacheConfig) validate() error {
+	if c.MaxEntries < 0 {
+		return fmt.Errorf("max entries of manifest cache cannot be < 0")
+	}
+	return nil
+}
@@ -12,6 +12,8 @@ type Options struct {
 	RetryConfig           *RetryConfig
 	HeartbeatSenderConfig *RecurringTaskConfig
 	ProgressTrackerConfig *RecurringTaskConfig
+	ManifestCacheConfig   *ManifestCacheConfig
+	DeploymentConfig      *DeploymentConfig
 }
 
 func NewOptions(o *cli.Options) *Options {
@@ -23,6 +25,8 @@ func NewOptions(o *cli.Options) *Options {
 		&RetryConfig{},
 		&RecurringTaskConfig{},
 		&RecurringTaskConfig{},
+		&ManifestCacheConfig{},
+		&DeploymentConfig{},
 	}
 }
 
@@ -45,5 +49,8 @@ func (o *Options) Validate() error {
 	if err := o.ProgressTrackerConfig.validate(); err != nil {
 		return err
 	}
+	if err := o.ManifestCacheConfig.validate(); err != nil {
+		return err
+	}
 	return nil
 }
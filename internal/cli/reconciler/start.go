@@ -26,7 +26,17 @@ func NewComponentReconciler(o *Options, reconcilerName string) (*service.Compone
 		//configure status updates send to mothership reconciler
 		WithHeartbeatSenderConfig(o.HeartbeatSenderConfig.Interval, o.HeartbeatSenderConfig.Timeout).
 		//configure reconciliation progress-checks applied on target K8s cluster
-		WithProgressTrackerConfig(o.ProgressTrackerConfig.Interval, o.ProgressTrackerConfig.Timeout)
+		WithProgressTrackerConfig(o.ProgressTrackerConfig.Interval, o.ProgressTrackerConfig.Timeout).
+		//configure cache of rendered manifests
+		WithManifestCache(o.ManifestCacheConfig.Dir, o.ManifestCacheConfig.MaxEntries)
+
+	//configure optional deployment steps
+	if o.DeploymentConfig.ClusterCapabilities {
+		recon.WithClusterCapabilities()
+	}
+	if o.DeploymentConfig.HelmReleases {
+		recon.WithHelmReleases()
+	}
 
 	return recon, nil
 }
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"github.com/kyma-incubator/reconciler/pkg/audit"
+	"github.com/kyma-incubator/reconciler/pkg/cache"
 	"github.com/kyma-incubator/reconciler/pkg/cluster"
 	"github.com/kyma-incubator/reconciler/pkg/db"
 	"github.com/kyma-incubator/reconciler/pkg/kv"
@@ -16,6 +18,8 @@ type ApplicationRegistry struct {
 	connectionFactory db.ConnectionFactory
 	inventory         cluster.Inventory
 	kvRepository      *kv.Repository
+	cacheRepository   *cache.Repository
+	auditRepository   *audit.Repository
 	operations        scheduler.OperationsRegistry
 	initialized       bool
 }
@@ -43,6 +47,12 @@ func (or *ApplicationRegistry) init() error {
 	if or.kvRepository, err = or.initRepository(); err != nil {
 		return err
 	}
+	if or.cacheRepository, err = or.initCacheRepository(); err != nil {
+		return err
+	}
+	if or.auditRepository, err = or.initAuditRepository(); err != nil {
+		return err
+	}
 	or.initOperationsRegistry()
 
 	or.initialized = true
@@ -50,14 +60,17 @@ func (or *ApplicationRegistry) init() error {
 	return nil
 }
 
+//Close closes the pooled database connections which are shared by all repositories of the registry
 func (or *ApplicationRegistry) Close() error {
 	if !or.initialized {
 		return nil
 	}
-	if err := or.kvRepository.Close(); err != nil {
-		return err
-	}
-	return nil
+	or.initialized = false
+	return or.connectionFactory.Close()
+}
+
+func (or *ApplicationRegistry) ConnectionFactory() db.ConnectionFactory {
+	return or.connectionFactory
 }
 
 func (or *ApplicationRegistry) Inventory() cluster.Inventory {
@@ -68,6 +81,14 @@ func (or *ApplicationRegistry) KVRepository() *kv.Repository {
 	return or.kvRepository
 }
 
+func (or *ApplicationRegistry) CacheRepository() *cache.Repository {
+	return or.cacheRepository
+}
+
+func (or *ApplicationRegistry) AuditRepository() *audit.Repository {
+	return or.auditRepository
+}
+
 func (or *ApplicationRegistry) OperationsRegistry() scheduler.OperationsRegistry {
 	return or.operations
 }
@@ -88,6 +109,30 @@ func (or *ApplicationRegistry) initRepository() (*kv.Repository, error) {
 	return repository, nil
 }
 
+func (or *ApplicationRegistry) initCacheRepository() (*cache.Repository, error) {
+	if or.connectionFactory == nil {
+		or.logger.Fatal("Failed to create cache repository because connection factory is undefined")
+	}
+	repository, err := cache.NewRepository(or.connectionFactory, or.debug)
+	if err != nil {
+		or.logger.Errorf("Failed to create cache repository: %s", err)
+		return nil, err
+	}
+	return repository, nil
+}
+
+func (or *ApplicationRegistry) initAuditRepository() (*audit.Repository, error) {
+	if or.connectionFactory == nil {
+		or.logger.Fatal("Failed to create audit repository because connection factory is undefined")
+	}
+	repository, err := audit.NewRepository(or.connectionFactory, or.debug)
+	if err != nil {
+		or.logger.Errorf("Failed to create audit repository: %s", err)
+		return nil, err
+	}
+	return repository, nil
+}
+
 func (or *ApplicationRegistry) initInventory() (cluster.Inventory, error) {
 	var err error
 
@@ -105,6 +150,7 @@ func (or *ApplicationRegistry) initInventory() (cluster.Inventory, error) {
 }
 
 func (or *ApplicationRegistry) initOperationsRegistry() scheduler.OperationsRegistry {
-	or.operations = scheduler.NewInMemoryOperationsRegistry()
+	//operations are kept in memory but their writes are recorded in the audit log
+	or.operations = scheduler.NewInMemoryOperationsRegistry().WithAuditConnection(or.auditRepository.Conn)
 	return or.operations
 }
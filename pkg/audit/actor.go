@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+)
+
+const (
+	//SystemActor is used for writes which are triggered by the reconciler itself (e.g. scheduler or retention job)
+	SystemActor = "system"
+	//AnonymousActor is used for requests which don't provide any caller identity
+	AnonymousActor = "anonymous"
+)
+
+//CLIActor returns the operating system user which executes the CLI
+func CLIActor() string {
+	if osUser, err := user.Current(); err == nil && osUser.Username != "" {
+		return osUser.Username
+	}
+	if username := os.Getenv("USER"); username != "" {
+		return username
+	}
+	return SystemActor
+}
+
+//RequestActor returns the caller identity of an HTTP request. The common name of a verified TLS client
+//certificate takes precedence. Otherwise the identity is read from the header (if defined) which has to be
+//set by an authenticating proxy in front of the reconciler.
+func RequestActor(r *http.Request, actorHeader string) string {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		if commonName := r.TLS.VerifiedChains[0][0].Subject.CommonName; commonName != "" {
+			return commonName
+		}
+	}
+	if actorHeader != "" {
+		if actor := strings.TrimSpace(r.Header.Get(actorHeader)); actor != "" {
+			return actor
+		}
+	}
+	return AnonymousActor
+}
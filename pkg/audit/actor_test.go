@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestActor(t *testing.T) {
+	t.Run("Anonymous request", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/v1/clusters", nil)
+		require.Equal(t, AnonymousActor, RequestActor(req, ""))
+		require.Equal(t, AnonymousActor, RequestActor(req, "X-Forwarded-User"))
+	})
+
+	t.Run("Actor header", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/v1/clusters", nil)
+		req.Header.Set("X-Forwarded-User", "alice")
+		require.Equal(t, "alice", RequestActor(req, "X-Forwarded-User"))
+		require.Equal(t, AnonymousActor, RequestActor(req, "")) //header is only trusted if configured
+	})
+
+	t.Run("Client certificate takes precedence", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/v1/clusters", nil)
+		req.Header.Set("X-Forwarded-User", "alice")
+		req.TLS = &tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "keb"}}}},
+		}
+		require.Equal(t, "keb", RequestActor(req, "X-Forwarded-User"))
+	})
+
+	t.Run("CLI user", func(t *testing.T) {
+		require.NotEmpty(t, CLIActor())
+	})
+}
+
+func TestParseSince(t *testing.T) {
+	since, err := ParseSince("24h")
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(-24*time.Hour), since, time.Minute)
+
+	since, err = ParseSince("2021-08-01T10:00:00Z")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2021, 8, 1, 10, 0, 0, 0, time.UTC), since)
+
+	_, err = ParseSince("-1h")
+	require.Error(t, err)
+	_, err = ParseSince("yesterday")
+	require.Error(t, err)
+}
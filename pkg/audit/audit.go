@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+)
+
+//Sink receives each recorded audit entry (e.g. to export it to an external log management system)
+type Sink interface {
+	Export(entry *model.AuditEntity)
+}
+
+var (
+	sinks   []Sink
+	sinksMu sync.RWMutex
+)
+
+//RegisterSink adds a sink which receives all audit entries recorded by this process
+func RegisterSink(sink Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = append(sinks, sink)
+}
+
+//UnregisterSink removes a previously registered sink
+func UnregisterSink(sink Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	for idx, registeredSink := range sinks {
+		if registeredSink == sink {
+			sinks = append(sinks[:idx], sinks[idx+1:]...)
+			return
+		}
+	}
+}
+
+func export(entry *model.AuditEntity) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, sink := range sinks {
+		sink.Export(entry)
+	}
+}
+
+//Record appends the entry to the audit log and exports it to the registered sinks.
+//If the connection is a transaction, the entry becomes part of it and is exported after the transaction
+//was committed. Writes which aren't persisted in the database (e.g. of in-memory registries) can pass
+//a nil connection: their entries are only exported to the sinks.
+func Record(conn db.Connection, entry *model.AuditEntity) error {
+	if entry.Actor == "" {
+		entry.Actor = SystemActor
+	}
+	if conn == nil {
+		entry.Created = time.Now().UTC()
+		export(entry)
+		return nil
+	}
+	q, err := db.NewQuery(conn, entry)
+	if err != nil {
+		return err
+	}
+	if err := q.Insert().Exec(); err != nil {
+		return err
+	}
+	if tx, ok := conn.(*db.Tx); ok {
+		tx.OnCommit(func() {
+			export(entry)
+		})
+		return nil
+	}
+	export(entry)
+	return nil
+}
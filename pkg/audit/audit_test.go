@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+type testSink struct {
+	entries []*model.AuditEntity
+	mu      sync.Mutex
+}
+
+func (s *testSink) Export(entry *model.AuditEntity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+}
+
+func (s *testSink) targets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []string
+	for _, entry := range s.entries {
+		result = append(result, entry.Target)
+	}
+	return result
+}
+
+func TestAudit(t *testing.T) {
+	connFact, err := db.NewTestConnectionFactory()
+	require.NoError(t, err)
+	repo, err := NewRepository(connFact, true)
+	require.NoError(t, err)
+
+	sink := &testSink{}
+	RegisterSink(sink)
+	defer UnregisterSink(sink)
+
+	target := fmt.Sprintf("audit-%d", time.Now().UnixNano())
+	newEntry := func(actor string, versionAfter int64) *model.AuditEntity {
+		return &model.AuditEntity{
+			Actor:        actor,
+			Action:       model.AuditActionCreate,
+			EntityType:   model.AuditEntityCluster,
+			Target:       target,
+			VersionAfter: versionAfter,
+		}
+	}
+
+	t.Run("Record entries", func(t *testing.T) {
+		require.NoError(t, Record(repo.Conn, newEntry("alice", 1)))
+		require.NoError(t, Record(repo.Conn, newEntry("", 2))) //system actor is used as fallback
+
+		entries, err := repo.List(&Filter{Target: target})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, SystemActor, entries[0].Actor) //latest entry first
+		require.Equal(t, int64(2), entries[0].VersionAfter)
+		require.Equal(t, "alice", entries[1].Actor)
+		require.Equal(t, model.AuditActionCreate, entries[1].Action)
+		require.False(t, entries[1].Created.IsZero())
+
+		require.Equal(t, []string{target, target}, sink.targets())
+	})
+
+	t.Run("Filter entries", func(t *testing.T) {
+		entries, err := repo.List(&Filter{Target: target, Actor: "alice"})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		entries, err = repo.List(&Filter{Target: target, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		entries, err = repo.List(&Filter{Target: target, EntityType: model.AuditEntityKey})
+		require.NoError(t, err)
+		require.Empty(t, entries)
+
+		entries, err = repo.List(&Filter{Target: target, Since: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("Export entries of transactions after commit", func(t *testing.T) {
+		sink.entries = nil
+		err := repo.Transactional(func(tx *db.Tx) error {
+			if err := Record(tx, newEntry("bob", 3)); err != nil {
+				return err
+			}
+			require.Empty(t, sink.targets())
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{target}, sink.targets())
+
+		//entries of rolled back transactions are neither stored nor exported
+		err = repo.Transactional(func(tx *db.Tx) error {
+			if err := Record(tx, newEntry("mallory", 4)); err != nil {
+				return err
+			}
+			return fmt.Errorf("fail")
+		})
+		require.Error(t, err)
+		require.Equal(t, []string{target}, sink.targets())
+		entries, err := repo.List(&Filter{Target: target, Actor: "mallory"})
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("Export entries without database", func(t *testing.T) {
+		sink.entries = nil
+		require.NoError(t, Record(nil, newEntry("carol", 5)))
+		require.Equal(t, []string{target}, sink.targets())
+		entries, err := repo.List(&Filter{Target: target, Actor: "carol"})
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+}
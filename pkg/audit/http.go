@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/model"
+)
+
+//HTTPAuditEntry is the model used to respond an audit entry
+type HTTPAuditEntry struct {
+	ID            int64     `json:"id"`
+	Actor         string    `json:"actor"`
+	Action        string    `json:"action"`
+	EntityType    string    `json:"entityType"`
+	Target        string    `json:"target"`
+	VersionBefore int64     `json:"versionBefore"`
+	VersionAfter  int64     `json:"versionAfter"`
+	Details       string    `json:"details,omitempty"`
+	Created       time.Time `json:"created"`
+}
+
+//HTTPAuditResponse is the model used to respond a list of audit entries (latest entries first)
+type HTTPAuditResponse struct {
+	Entries []*HTTPAuditEntry `json:"entries"`
+}
+
+func NewHTTPAuditEntry(entry *model.AuditEntity) *HTTPAuditEntry {
+	return &HTTPAuditEntry{
+		ID:            entry.ID,
+		Actor:         entry.Actor,
+		Action:        string(entry.Action),
+		EntityType:    entry.EntityType,
+		Target:        entry.Target,
+		VersionBefore: entry.VersionBefore,
+		VersionAfter:  entry.VersionAfter,
+		Details:       entry.Details,
+		Created:       entry.Created,
+	}
+}
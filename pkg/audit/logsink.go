@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"go.uber.org/zap"
+)
+
+//LogSink writes audit entries as structured log messages (e.g. to ship them with the log collector of the cluster)
+type LogSink struct {
+	logger *zap.SugaredLogger
+}
+
+func NewLogSink(logger *zap.SugaredLogger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Export(entry *model.AuditEntity) {
+	s.logger.Infow("Audit",
+		"id", entry.ID,
+		"actor", entry.Actor,
+		"action", entry.Action,
+		"entityType", entry.EntityType,
+		"target", entry.Target,
+		"versionBefore", entry.VersionBefore,
+		"versionAfter", entry.VersionAfter,
+		"details", entry.Details,
+		"created", entry.Created)
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/kyma-incubator/reconciler/pkg/repository"
+)
+
+const timestampFormat = "2006-01-02 15:04:05"
+
+//Filter restricts the listed audit entries: unset fields are ignored
+type Filter struct {
+	Since      time.Time
+	Actor      string
+	EntityType string
+	Target     string
+	Limit      int
+}
+
+//ParseSince converts either a duration relative to now (e.g. "24h") or a RFC3339 timestamp to a point in time
+func ParseSince(since string) (time.Time, error) {
+	if duration, err := time.ParseDuration(since); err == nil {
+		if duration < 0 {
+			return time.Time{}, fmt.Errorf("duration '%s' cannot be negative", since)
+		}
+		return time.Now().Add(-duration), nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' is neither a duration (e.g. '24h') nor a RFC3339 timestamp", since)
+	}
+	return timestamp, nil
+}
+
+//Repository provides read access to the audit log (entries are written by Record)
+type Repository struct {
+	*repository.Repository
+}
+
+func NewRepository(dbFac db.ConnectionFactory, debug bool) (*Repository, error) {
+	repo, err := repository.NewRepository(dbFac, debug)
+	if err != nil {
+		return nil, err
+	}
+	return &Repository{repo}, nil
+}
+
+//List returns the audit entries matching the filter (latest entries first)
+func (r *Repository) List(filter *Filter) ([]*model.AuditEntity, error) {
+	if filter == nil {
+		filter = &Filter{}
+	}
+	q, err := db.NewQuery(r.Conn, &model.AuditEntity{})
+	if err != nil {
+		return nil, err
+	}
+	var conds []db.Condition
+	if !filter.Since.IsZero() {
+		conds = append(conds, db.Ge("Created", filter.Since.UTC().Format(timestampFormat)))
+	}
+	if filter.Actor != "" {
+		conds = append(conds, db.Eq("Actor", filter.Actor))
+	}
+	if filter.EntityType != "" {
+		conds = append(conds, db.Eq("EntityType", filter.EntityType))
+	}
+	if filter.Target != "" {
+		conds = append(conds, db.Eq("Target", filter.Target))
+	}
+	selectQ := q.Select().
+		WhereCondition(conds...).
+		OrderBy(map[string]string{"ID": "DESC"})
+	if filter.Limit > 0 {
+		selectQ = selectQ.Limit(filter.Limit)
+	}
+	entities, err := selectQ.GetMany()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.AuditEntity, 0, len(entities))
+	for _, entity := range entities {
+		result = append(result, entity.(*model.AuditEntity))
+	}
+	return result, nil
+}
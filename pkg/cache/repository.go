@@ -18,6 +18,11 @@ func NewRepository(dbFac db.ConnectionFactory, debug bool) (*Repository, error)
 	return &Repository{repo}, nil
 }
 
+//WithTx returns a copy of the repository which executes all statements within the transaction
+func (cr *Repository) WithTx(tx *db.Tx) *Repository {
+	return &Repository{cr.Repository.WithTx(tx)}
+}
+
 func (cr *Repository) All() ([]*model.CacheEntryEntity, error) {
 	q, err := db.NewQuery(cr.Conn, &model.CacheEntryEntity{})
 	if err != nil {
@@ -85,15 +90,16 @@ func (cr *Repository) Add(cacheEntry *model.CacheEntryEntity, cacheDeps []*model
 	}
 
 	//create new cache entry and track its dependencies
-	dbOps := func() (interface{}, error) {
-		q, err := db.NewQuery(cr.Conn, cacheEntry)
+	dbOps := func(tx *db.Tx) (interface{}, error) {
+		txRepo := cr.WithTx(tx)
+		q, err := db.NewQuery(txRepo.Conn, cacheEntry)
 		if err != nil {
 			return cacheEntry, err
 		}
 		if err := q.Insert().Exec(); err != nil {
 			return cacheEntry, err
 		}
-		if err := cr.CacheDep.Record(cacheEntry, cacheDeps).Exec(false); err != nil {
+		if err := txRepo.CacheDep.Record(cacheEntry, cacheDeps).Exec(false); err != nil {
 			return cacheEntry, err
 		}
 		return cacheEntry, err
@@ -108,15 +114,16 @@ func (cr *Repository) Add(cacheEntry *model.CacheEntryEntity, cacheDeps []*model
 }
 
 func (cr *Repository) Invalidate(label, cluster string) error {
-	dbOps := func() error {
+	dbOps := func(tx *db.Tx) error {
+		txRepo := cr.WithTx(tx)
 		//invalidate the cache entity and drop all tracked dependencies
-		if err := cr.CacheDep.Invalidate().WithLabel(label).WithCluster(cluster).Exec(false); err != nil {
+		if err := txRepo.CacheDep.Invalidate().WithLabel(label).WithCluster(cluster).Exec(false); err != nil {
 			return err
 		}
 
 		//as cache dependencies are optional we cannot rely that the previous
 		//invalidation dropped the cache entity: delete the entity also explicitly
-		q, err := db.NewQuery(cr.Conn, &model.CacheEntryEntity{})
+		q, err := db.NewQuery(txRepo.Conn, &model.CacheEntryEntity{})
 		if err != nil {
 			return err
 		}
@@ -130,15 +137,16 @@ func (cr *Repository) Invalidate(label, cluster string) error {
 }
 
 func (cr *Repository) InvalidateByID(id int64) error {
-	dbOps := func() error {
+	dbOps := func(tx *db.Tx) error {
+		txRepo := cr.WithTx(tx)
 		//invalidate the cache entity and drop all tracked dependencies
-		if err := cr.CacheDep.Invalidate().WithCacheID(id).Exec(false); err != nil {
+		if err := txRepo.CacheDep.Invalidate().WithCacheID(id).Exec(false); err != nil {
 			return err
 		}
 
 		//as cache dependencies are optional we cannot rely that the previous
 		//invalidation dropped the cache entity: delete the entity also explicitly
-		q, err := db.NewQuery(cr.Conn, &model.CacheEntryEntity{})
+		q, err := db.NewQuery(txRepo.Conn, &model.CacheEntryEntity{})
 		if err != nil {
 			return err
 		}
@@ -1,7 +1,6 @@
 package cluster
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/kyma-incubator/reconciler/pkg/db"
@@ -12,23 +11,8 @@ type createdIntervalFilter struct {
 	interval time.Duration
 }
 
-func (rif *createdIntervalFilter) Filter(dbType db.Type, statusColHdr *db.ColumnHandler) (string, error) {
-	clusterColName, err := statusColHdr.ColumnName("Cluster")
-	if err != nil {
-		return "", err
-	}
-	createdColName, err := statusColHdr.ColumnName("Created")
-	if err != nil {
-		return "", err
-	}
-	switch dbType {
-	case db.Postgres:
-		return fmt.Sprintf(`%s = '%s' AND %s >= NOW() - INTERVAL '%.0f SECOND'`,
-			clusterColName, rif.cluster, createdColName, rif.interval.Seconds()), nil
-	case db.SQLite:
-		return fmt.Sprintf(`%s = '%s' AND %s >= DATETIME('now', '-%.0f SECONDS')`,
-			clusterColName, rif.cluster, createdColName, rif.interval.Seconds()), nil
-	default:
-		return "", fmt.Errorf("database type '%s' is not supported by this filter", dbType)
-	}
+func (rif *createdIntervalFilter) Filter() db.Condition {
+	return db.And(
+		db.Eq(statusField("Cluster"), rif.cluster),
+		db.Ge(statusField("Created"), time.Now().Add(-rif.interval).UTC().Format(timestampFormat)))
 }
@@ -0,0 +1,444 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyma-incubator/reconciler/pkg/cache"
+	"github.com/kyma-incubator/reconciler/pkg/keb"
+	"github.com/kyma-incubator/reconciler/pkg/kv"
+	"github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler"
+	"github.com/kyma-incubator/reconciler/pkg/repository"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	BucketPlaceholderGlobalAccountID = "${globalAccountID}"
+	BucketPlaceholderSubAccountID    = "${subAccountID}"
+	BucketPlaceholderCluster         = "${cluster}"
+
+	//PostRenderKeyPrefix is the prefix of the keys which contain the post-render declarations of a component
+	//(e.g. 'postRender.istio'): their values are not part of the component configuration
+	PostRenderKeyPrefix = "postRender."
+
+	//ValuesKeyPrefix is the prefix of the keys which contain YAML values documents of a component
+	//(e.g. 'values.istio'): their values are not part of the component configuration
+	ValuesKeyPrefix = "values."
+)
+
+//DefaultBucketSequence is used if no bucket sequence is configured
+var DefaultBucketSequence = []string{"default", BucketPlaceholderGlobalAccountID, BucketPlaceholderCluster}
+
+//ConfigurationManager calculates the effective configuration of a cluster component:
+//the values of an ordered sequence of buckets are merged (a value in a later bucket overrides
+//the value of the same key in a previous bucket) and the component configuration sent by KEB
+//is applied on top of it. The bucket sequence is defined by the bucket rules matching the cluster
+//(or the configured bucket sequence if no rule matches). The merge result of the buckets is cached per cluster and
+//component, its dependencies to the buckets and their values are tracked to invalidate the cache entry if a value changes.
+type ConfigurationManager struct {
+	kvRepo         *kv.Repository
+	cacheRepo      *cache.Repository
+	bucketSequence []string
+	logger         *zap.SugaredLogger
+}
+
+func NewConfigurationManager(kvRepo *kv.Repository, cacheRepo *cache.Repository, bucketSequence []string, debug bool) (*ConfigurationManager, error) {
+	if kvRepo == nil || cacheRepo == nil {
+		return nil, fmt.Errorf("key-value repository and cache repository are required")
+	}
+	log, err := logger.NewLogger(debug)
+	if err != nil {
+		return nil, err
+	}
+	if len(bucketSequence) == 0 {
+		bucketSequence = DefaultBucketSequence
+	}
+	return &ConfigurationManager{
+		kvRepo:         kvRepo,
+		cacheRepo:      cacheRepo,
+		bucketSequence: bucketSequence,
+		logger:         log,
+	}, nil
+}
+
+//ResolvedBucket is a bucket assigned to a cluster and the name of the bucket rule which
+//assigned it (empty if the bucket is part of the configured default bucket sequence)
+type ResolvedBucket struct {
+	Bucket string `json:"bucket"`
+	Rule   string `json:"rule,omitempty"`
+}
+
+//Buckets returns the resolved bucket names (in merge order) for the cluster.
+//Buckets whose placeholders cannot be resolved are skipped.
+func (cm *ConfigurationManager) Buckets(state *State) ([]string, error) {
+	resolvedBuckets, err := cm.EvaluateBuckets(state)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(resolvedBuckets))
+	for _, resolvedBucket := range resolvedBuckets {
+		result = append(result, resolvedBucket.Bucket)
+	}
+	return result, nil
+}
+
+//EvaluateBuckets returns the buckets (in merge order) assigned to the cluster by the bucket rules.
+//If no bucket rule matches the cluster, the configured bucket sequence is used.
+func (cm *ConfigurationManager) EvaluateBuckets(state *State) ([]*ResolvedBucket, error) {
+	metadata := &keb.Metadata{}
+	runtime := &keb.RuntimeInput{}
+	var cluster string
+	if state.Cluster != nil {
+		var err error
+		if metadata, err = state.Cluster.GetMetadata(); err != nil {
+			return nil, err
+		}
+		if runtime, err = state.Cluster.GetRuntime(); err != nil {
+			return nil, err
+		}
+		cluster = state.Cluster.Cluster
+	}
+
+	placeholders := map[string]string{
+		BucketPlaceholderGlobalAccountID: metadata.GlobalAccountID,
+		BucketPlaceholderSubAccountID:    metadata.SubAccountID,
+		BucketPlaceholderCluster:         cluster,
+	}
+
+	rules, err := cm.kvRepo.BucketRules()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve bucket rules")
+	}
+
+	var result []*ResolvedBucket
+	for _, rule := range rules { //rules are ordered by priority
+		selector, err := rule.GetSelector()
+		if err != nil {
+			return nil, err
+		}
+		if !selector.Matches(metadata, runtime) {
+			continue
+		}
+		buckets, err := rule.GetBuckets()
+		if err != nil {
+			return nil, err
+		}
+		cm.logger.Debugf("Bucket rule '%s' matches cluster '%s'", rule.Rule, cluster)
+		for _, bucket := range buckets {
+			if resolved, ok := cm.resolveBucket(bucket, cluster, placeholders); ok {
+				result = appendBucket(result, &ResolvedBucket{Bucket: resolved, Rule: rule.Rule})
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		cm.logger.Debugf("No bucket rule matches cluster '%s': using bucket sequence %v", cluster, cm.bucketSequence)
+		for _, bucket := range cm.bucketSequence {
+			if resolved, ok := cm.resolveBucket(bucket, cluster, placeholders); ok {
+				result = appendBucket(result, &ResolvedBucket{Bucket: resolved})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (cm *ConfigurationManager) resolveBucket(bucket, cluster string, placeholders map[string]string) (string, bool) {
+	resolved := bucket
+	for placeholder, value := range placeholders {
+		if !strings.Contains(resolved, placeholder) {
+			continue
+		}
+		if value == "" {
+			cm.logger.Debugf("Skipping bucket '%s' for cluster '%s': placeholder cannot be resolved", bucket, cluster)
+			return "", false
+		}
+		resolved = strings.ReplaceAll(resolved, placeholder, value)
+	}
+	return resolved, true
+}
+
+//appendBucket adds the bucket to the end of the list: if the bucket is already included,
+//the previous occurrence gets dropped as the bucket has now a higher precedence
+func appendBucket(buckets []*ResolvedBucket, bucket *ResolvedBucket) []*ResolvedBucket {
+	for idx, existing := range buckets {
+		if existing.Bucket == bucket.Bucket {
+			buckets = append(buckets[:idx], buckets[idx+1:]...)
+			break
+		}
+	}
+	return append(buckets, bucket)
+}
+
+//Configuration returns the effective configuration of a component of the cluster. The merge result of the buckets
+//is cached and reused as long as no value of the merged buckets changes.
+func (cm *ConfigurationManager) Configuration(component *keb.Components, state *State) ([]reconciler.Configuration, error) {
+	buckets, err := cm.Buckets(state)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketCfg, err := cm.cachedBucketConfiguration(component, state, buckets)
+	if err != nil {
+		return nil, err
+	}
+	if bucketCfg == nil {
+		if bucketCfg, err = cm.mergeBuckets(component, state, buckets); err != nil {
+			return nil, err
+		}
+	}
+
+	//KEB configuration overrides the bucket values
+	effectiveCfg := make(map[string]reconciler.Configuration, len(bucketCfg)+len(component.Configuration))
+	for _, cfg := range bucketCfg {
+		effectiveCfg[cfg.Key] = cfg
+	}
+	for _, kebCfg := range component.Configuration {
+		effectiveCfg[kebCfg.Key] = reconciler.Configuration{
+			Key:     kebCfg.Key,
+			Value:   kebCfg.Value,
+			Trigger: effectiveCfg[kebCfg.Key].Trigger, //trigger of the key is kept if KEB overrides its value
+			Secret:  kebCfg.Secret || effectiveCfg[kebCfg.Key].Secret,
+		}
+	}
+
+	result := make([]reconciler.Configuration, 0, len(effectiveCfg))
+	for _, cfg := range effectiveCfg {
+		result = append(result, cfg)
+	}
+	sortConfiguration(result)
+	return result, nil
+}
+
+//mergeBuckets merges the values of the buckets and caches the result
+func (cm *ConfigurationManager) mergeBuckets(component *keb.Components, state *State, buckets []string) ([]reconciler.Configuration, error) {
+	merger := &bucketMerger{}
+	for _, bucket := range buckets {
+		values, err := cm.kvRepo.ValuesByBucket(bucket)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to retrieve values of bucket '%s'", bucket))
+		}
+		if err := merger.Add(bucket, values); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]reconciler.Configuration, 0, merger.Len())
+	for key, value := range merger.Values() {
+		if strings.HasPrefix(key, PostRenderKeyPrefix) || strings.HasPrefix(key, ValuesKeyPrefix) {
+			continue
+		}
+		keyEntity, err := cm.kvRepo.Key(value.Key, value.KeyVersion)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to retrieve key '%s' (version %d)", value.Key, value.KeyVersion))
+		}
+		plainValue, err := cm.kvRepo.PlainValue(keyEntity, value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, reconciler.Configuration{
+			Key:      key,
+			Value:    plainValue,
+			DataType: value.DataType,
+			Trigger:  keyEntity.Trigger,
+			Secret:   keyEntity.Encrypted,
+		})
+	}
+	sortConfiguration(result)
+
+	if err := cm.cache(component, state, buckets, merger, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func sortConfiguration(cfg []reconciler.Configuration) {
+	sort.Slice(cfg, func(i, j int) bool {
+		return cfg[i].Key < cfg[j].Key
+	})
+}
+
+//PostRender returns the post-render declarations of a component of the cluster. They are defined as YAML list
+//in the value of the key 'postRender.<component>': the declarations of all buckets are applied in merge order.
+func (cm *ConfigurationManager) PostRender(component *keb.Components, state *State) ([]reconciler.PostRender, error) {
+	key := PostRenderKeyPrefix + component.Component
+	bucketValues, err := cm.bucketValues(key, state)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []reconciler.PostRender
+	for _, bucketValue := range bucketValues {
+		var postRenders []reconciler.PostRender
+		if err := yaml.Unmarshal([]byte(bucketValue.value), &postRenders); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("value of key '%s' in bucket '%s' is not a list of post-render declarations",
+				key, bucketValue.bucket))
+		}
+		for _, postRender := range postRenders {
+			for _, patch := range postRender.Patches {
+				if err := patch.Validate(); err != nil {
+					return nil, errors.Wrap(err, fmt.Sprintf("invalid post-render declaration in key '%s' of bucket '%s'",
+						key, bucketValue.bucket))
+				}
+			}
+		}
+		result = append(result, postRenders...)
+	}
+	return result, nil
+}
+
+//Values returns the YAML values documents of a component of the cluster. They are defined in the value of the key
+//'values.<component>' and are merged by the component reconciler between the chart profile and the configuration
+//(the documents of all buckets are merged in merge order).
+func (cm *ConfigurationManager) Values(component *keb.Components, state *State) ([]string, error) {
+	key := ValuesKeyPrefix + component.Component
+	bucketValues, err := cm.bucketValues(key, state)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, bucketValue := range bucketValues {
+		values := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(bucketValue.value), &values); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("value of key '%s' in bucket '%s' is not a YAML values document",
+				key, bucketValue.bucket))
+		}
+		result = append(result, bucketValue.value)
+	}
+	return result, nil
+}
+
+type bucketValue struct {
+	bucket string
+	value  string //plain value
+}
+
+//bucketValues returns the latest values of the key in the buckets of the cluster (ordered by the merge order)
+func (cm *ConfigurationManager) bucketValues(key string, state *State) ([]*bucketValue, error) {
+	buckets, err := cm.Buckets(state)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*bucketValue
+	for _, bucket := range buckets {
+		value, err := cm.kvRepo.LatestValue(bucket, key)
+		if err != nil {
+			if repository.IsNotFoundError(err) {
+				continue
+			}
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to retrieve value of key '%s' in bucket '%s'", key, bucket))
+		}
+		keyEntity, err := cm.kvRepo.Key(value.Key, value.KeyVersion)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to retrieve key '%s' (version %d)", value.Key, value.KeyVersion))
+		}
+		plainValue, err := cm.kvRepo.PlainValue(keyEntity, value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &bucketValue{bucket: bucket, value: plainValue})
+	}
+	return result, nil
+}
+
+//cachedConfiguration is the cached merge result of the buckets of a cluster component
+type cachedConfiguration struct {
+	Buckets       []string                   `json:"buckets"`
+	Configuration []reconciler.Configuration `json:"configuration"` //secret values are encrypted
+}
+
+//cachedBucketConfiguration returns the cached merge result of the buckets or nil if no cache entry exists
+//or if the cache entry was calculated for other buckets (e.g. bucket rules or cluster metadata were changed)
+func (cm *ConfigurationManager) cachedBucketConfiguration(component *keb.Components, state *State, buckets []string) ([]reconciler.Configuration, error) {
+	cacheEntry, err := cm.cacheRepo.Get(component.Component, state.Cluster.Cluster)
+	if err != nil {
+		if repository.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to retrieve cached configuration of component '%s' of cluster '%s'",
+			component.Component, state.Cluster.Cluster))
+	}
+
+	cached := &cachedConfiguration{}
+	if err := json.Unmarshal([]byte(cacheEntry.Data), cached); err != nil {
+		cm.logger.Warnf("Ignoring cached configuration of component '%s' of cluster '%s' as it cannot be decoded: %s",
+			component.Component, state.Cluster.Cluster, err)
+		return nil, nil
+	}
+	if strings.Join(cached.Buckets, ",") != strings.Join(buckets, ",") {
+		cm.logger.Debugf("Ignoring cached configuration of component '%s' of cluster '%s': it was merged from buckets %v "+
+			"but the cluster uses buckets %v", component.Component, state.Cluster.Cluster, cached.Buckets, buckets)
+		return nil, nil
+	}
+
+	encryptor := cm.kvRepo.Conn.Encryptor()
+	for idx, cfg := range cached.Configuration {
+		if !cfg.Secret {
+			continue
+		}
+		if cached.Configuration[idx].Value, err = encryptor.Decrypt(cfg.Value); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to decrypt cached value of key '%s'", cfg.Key))
+		}
+	}
+	return cached.Configuration, nil
+}
+
+func (cm *ConfigurationManager) cache(component *keb.Components, state *State, buckets []string, merger *bucketMerger, result []reconciler.Configuration) error {
+	//secret values are cached encrypted
+	encryptor := cm.kvRepo.Conn.Encryptor()
+	cached := &cachedConfiguration{
+		Buckets:       buckets,
+		Configuration: make([]reconciler.Configuration, 0, len(result)),
+	}
+	for _, cfg := range result {
+		if cfg.Secret {
+			encValue, err := encryptor.Encrypt(cfg.Value)
+			if err != nil {
+				return err
+			}
+			cfg.Value = encValue
+		}
+		cached.Configuration = append(cached.Configuration, cfg)
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+
+	//track a dependency for each merged key in each bucket: this ensures the cache entry gets also
+	//invalidated if a value is added to a bucket which overrides the currently used value
+	var cacheDeps []*model.ValueEntity
+	for key := range merger.Values() {
+		for _, bucket := range buckets {
+			cacheDeps = append(cacheDeps, &model.ValueEntity{
+				Key:    key,
+				Bucket: bucket,
+			})
+		}
+	}
+	//track a dependency on each bucket: a value of a new key invalidates the cache entry
+	for _, bucket := range buckets {
+		cacheDeps = append(cacheDeps, &model.ValueEntity{
+			Key:    model.CacheDependencyBucket,
+			Bucket: bucket,
+		})
+	}
+	_, err = cm.cacheRepo.Add(&model.CacheEntryEntity{
+		Label:   component.Component,
+		Cluster: state.Cluster.Cluster,
+		Data:    string(data),
+	}, cacheDeps)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to cache configuration of component '%s' of cluster '%s'",
+			component.Component, state.Cluster.Cluster))
+	}
+	return nil
+}
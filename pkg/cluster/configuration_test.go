@@ -0,0 +1,272 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/cache"
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/keb"
+	"github.com/kyma-incubator/reconciler/pkg/kv"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationManager(t *testing.T) {
+	connFact, err := db.NewTestConnectionFactory()
+	require.NoError(t, err)
+	kvRepo, err := kv.NewRepository(connFact, true)
+	require.NoError(t, err)
+	cacheRepo, err := cache.NewRepository(connFact, true)
+	require.NoError(t, err)
+
+	ts := time.Now().UnixNano()
+	defaultBucket := fmt.Sprintf("default-%d", ts)
+	keyString := fmt.Sprintf("key.string-%d", ts)
+	keyInt := fmt.Sprintf("key.int-%d", ts)
+	keyKEB := fmt.Sprintf("key.keb-%d", ts)
+
+	state := &State{
+		Cluster: &model.ClusterEntity{
+			Cluster:  fmt.Sprintf("cluster-%d", ts),
+			Metadata: fmt.Sprintf(`{"globalAccountID":"ga","shootName":"shoot-%d"}`, ts),
+			Runtime:  `{"name":"runtime"}`,
+			Contract: 1,
+		},
+	}
+	gaBucket := fmt.Sprintf("ga-%d", ts)
+	clusterBucket := state.Cluster.Cluster
+
+	configManager, err := NewConfigurationManager(kvRepo, cacheRepo, []string{
+		defaultBucket,
+		fmt.Sprintf("%s-%d", BucketPlaceholderGlobalAccountID, ts),
+		BucketPlaceholderSubAccountID, //will be skipped as cluster has no sub-account ID
+		BucketPlaceholderCluster,
+	}, true)
+	require.NoError(t, err)
+
+	//create test data
+	trigger := `restartPods("kyma-system", "app=test")`
+	for key, dataType := range map[string]model.DataType{keyString: model.String, keyInt: model.Integer, keyKEB: model.String} {
+		keyEntity := &model.KeyEntity{Key: key, DataType: dataType, Username: "test"}
+		if key == keyKEB {
+			keyEntity.Trigger = trigger
+		}
+		_, err := kvRepo.CreateKey(keyEntity)
+		require.NoError(t, err)
+	}
+	createValue := func(t *testing.T, bucket, key, value string) {
+		keyEntity, err := kvRepo.LatestKey(key)
+		require.NoError(t, err)
+		_, err = kvRepo.CreateValue(&model.ValueEntity{
+			Key: key, KeyVersion: keyEntity.Version, Bucket: bucket, Value: value, Username: "test",
+		})
+		require.NoError(t, err)
+	}
+	createValue(t, defaultBucket, keyString, "default value")
+	createValue(t, defaultBucket, keyInt, "1")
+	createValue(t, defaultBucket, keyKEB, "default value")
+	createValue(t, gaBucket, keyInt, "2")
+	createValue(t, clusterBucket, keyString, "cluster value")
+
+	defer func() {
+		for _, bucket := range []string{defaultBucket, gaBucket, clusterBucket} {
+			require.NoError(t, kvRepo.DeleteBucket(bucket))
+		}
+		for _, key := range []string{keyString, keyInt, keyKEB} {
+			require.NoError(t, kvRepo.DeleteKey(key))
+		}
+	}()
+
+	t.Run("Resolve buckets", func(t *testing.T) {
+		buckets, err := configManager.Buckets(state)
+		require.NoError(t, err)
+		require.Equal(t, []string{defaultBucket, gaBucket, clusterBucket}, buckets)
+	})
+
+	t.Run("Calculate and cache configuration", func(t *testing.T) {
+		component := &keb.Components{
+			Component: "component",
+			Configuration: []keb.Configuration{
+				{Key: keyKEB, Value: "keb value"},
+			},
+		}
+
+		configuration, err := configManager.Configuration(component, state)
+		require.NoError(t, err)
+
+		expected := []reconciler.Configuration{
+			{Key: keyInt, Value: "2", DataType: model.Integer},
+			{Key: keyKEB, Value: "keb value", Trigger: trigger}, //trigger of the key is kept
+			{Key: keyString, Value: "cluster value", DataType: model.String},
+		}
+		require.Equal(t, expected, configuration)
+
+		//verify cache entry (contains the merge result of the buckets)
+		cacheEntry, err := cacheRepo.Get(component.Component, state.Cluster.Cluster)
+		require.NoError(t, err)
+		cached := &cachedConfiguration{}
+		require.NoError(t, json.Unmarshal([]byte(cacheEntry.Data), cached))
+		require.Equal(t, []string{defaultBucket, gaBucket, clusterBucket}, cached.Buckets)
+		require.Equal(t, []reconciler.Configuration{
+			{Key: keyInt, Value: "2", DataType: model.Integer},
+			{Key: keyKEB, Value: "default value", DataType: model.String, Trigger: trigger},
+			{Key: keyString, Value: "cluster value", DataType: model.String},
+		}, cached.Configuration)
+
+		//cache entry is used
+		_, err = cacheRepo.Add(&model.CacheEntryEntity{
+			Label:   component.Component,
+			Cluster: state.Cluster.Cluster,
+			Data:    fmt.Sprintf(`{"buckets":["%s","%s","%s"],"configuration":[{"key":"%s","value":"cached value"}]}`, defaultBucket, gaBucket, clusterBucket, keyString),
+		}, []*model.ValueEntity{{Key: keyString, Bucket: gaBucket}})
+		require.NoError(t, err)
+		configuration, err = configManager.Configuration(component, state)
+		require.NoError(t, err)
+		require.Equal(t, []reconciler.Configuration{
+			{Key: keyKEB, Value: "keb value"},
+			{Key: keyString, Value: "cached value"},
+		}, configuration)
+
+		//a new value in a bucket invalidates the cache entry
+		createValue(t, gaBucket, keyString, "global account value")
+		_, err = cacheRepo.Get(component.Component, state.Cluster.Cluster)
+		require.Error(t, err)
+	})
+
+	t.Run("Invalidate cache by value of new key", func(t *testing.T) {
+		component := &keb.Components{Component: "component"}
+		_, err := configManager.Configuration(component, state)
+		require.NoError(t, err)
+		_, err = cacheRepo.Get(component.Component, state.Cluster.Cluster)
+		require.NoError(t, err)
+
+		keyNew := fmt.Sprintf("key.new-%d", ts)
+		_, err = kvRepo.CreateKey(&model.KeyEntity{Key: keyNew, DataType: model.String, Username: "test"})
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, kvRepo.DeleteKey(keyNew))
+		}()
+		createValue(t, gaBucket, keyNew, "new value")
+		_, err = cacheRepo.Get(component.Component, state.Cluster.Cluster)
+		require.Error(t, err)
+
+		configuration, err := configManager.Configuration(component, state)
+		require.NoError(t, err)
+		require.Contains(t, configuration, reconciler.Configuration{Key: keyNew, Value: "new value", DataType: model.String})
+	})
+
+	t.Run("Decrypt secret values", func(t *testing.T) {
+		keySecret := fmt.Sprintf("key.secret-%d", ts)
+		keyKEBSecret := fmt.Sprintf("key.keb.secret-%d", ts)
+		_, err := kvRepo.CreateKey(&model.KeyEntity{Key: keySecret, DataType: model.Secret, Username: "test"})
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, kvRepo.DeleteKey(keySecret))
+		}()
+		createValue(t, defaultBucket, keySecret, "secret value")
+
+		component := &keb.Components{
+			Component: "secret-component",
+			Configuration: []keb.Configuration{
+				{Key: keyKEBSecret, Value: "keb secret value", Secret: true},
+			},
+		}
+		configuration, err := configManager.Configuration(component, state)
+		require.NoError(t, err)
+
+		secrets := make(map[string]string)
+		for _, cfg := range configuration {
+			if cfg.Secret {
+				secrets[cfg.Key] = cfg.Value
+			}
+		}
+		require.Equal(t, map[string]string{keySecret: "secret value", keyKEBSecret: "keb secret value"}, secrets)
+
+		//secret values are cached encrypted
+		cacheEntry, err := cacheRepo.Get(component.Component, state.Cluster.Cluster)
+		require.NoError(t, err)
+		require.NotContains(t, cacheEntry.Data, "secret value")
+		cached := &cachedConfiguration{}
+		require.NoError(t, json.Unmarshal([]byte(cacheEntry.Data), cached))
+		for _, cfg := range cached.Configuration {
+			if cfg.Secret {
+				plainValue, err := kvRepo.Conn.Encryptor().Decrypt(cfg.Value)
+				require.NoError(t, err)
+				require.Equal(t, secrets[cfg.Key], plainValue)
+			}
+		}
+
+		//secret values are decrypted if the cache entry is used
+		cachedCfg, err := configManager.Configuration(component, state)
+		require.NoError(t, err)
+		require.Equal(t, configuration, cachedCfg)
+	})
+
+	t.Run("Evaluate bucket rules", func(t *testing.T) {
+		rules := []struct {
+			name     string
+			priority int64
+			selector model.BucketSelector
+			buckets  []string
+		}{
+			{
+				name:     fmt.Sprintf("shoot-%d", ts),
+				priority: 20,
+				selector: model.BucketSelector{
+					model.SelectorShootName:   fmt.Sprintf("shoot-%d", ts),
+					model.SelectorRuntimeName: "run*",
+				},
+				buckets: []string{clusterBucket, defaultBucket},
+			},
+			{
+				name:     fmt.Sprintf("ga-%d", ts),
+				priority: 10,
+				selector: model.BucketSelector{
+					model.SelectorShootName:       fmt.Sprintf("shoot-%d", ts),
+					model.SelectorGlobalAccountID: "g*",
+				},
+				buckets: []string{defaultBucket, gaBucket, BucketPlaceholderSubAccountID},
+			},
+			{
+				name:     fmt.Sprintf("other-%d", ts),
+				priority: 30,
+				selector: model.BucketSelector{
+					model.SelectorShootName:   fmt.Sprintf("shoot-%d", ts),
+					model.SelectorRuntimeName: "other",
+				},
+				buckets: []string{"other"},
+			},
+		}
+		for _, rule := range rules {
+			ruleEntity, err := model.NewBucketRuleEntity(rule.name, rule.priority, rule.selector, rule.buckets, "test")
+			require.NoError(t, err)
+			_, err = kvRepo.CreateBucketRule(ruleEntity)
+			require.NoError(t, err)
+		}
+		defer func() {
+			for _, rule := range rules {
+				require.NoError(t, kvRepo.DeleteBucketRule(rule.name, "test"))
+			}
+		}()
+
+		resolvedBuckets, err := configManager.EvaluateBuckets(state)
+		require.NoError(t, err)
+		require.Equal(t, []*ResolvedBucket{
+			{Bucket: gaBucket, Rule: rules[1].name},
+			{Bucket: clusterBucket, Rule: rules[0].name},
+			{Bucket: defaultBucket, Rule: rules[0].name},
+		}, resolvedBuckets)
+
+		configuration, err := configManager.Configuration(&keb.Components{Component: "component"}, state)
+		require.NoError(t, err)
+		require.Equal(t, []reconciler.Configuration{
+			{Key: keyInt, Value: "1", DataType: model.Integer},
+			{Key: keyKEB, Value: "default value", DataType: model.String, Trigger: trigger},
+			{Key: keyString, Value: "default value", DataType: model.String},
+		}, configuration)
+	})
+}
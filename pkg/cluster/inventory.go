@@ -1,11 +1,11 @@
 package cluster
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/kyma-incubator/reconciler/pkg/audit"
 	"github.com/kyma-incubator/reconciler/pkg/db"
 	"github.com/kyma-incubator/reconciler/pkg/keb"
 	"github.com/kyma-incubator/reconciler/pkg/model"
@@ -14,13 +14,20 @@ import (
 
 type Inventory interface {
 	CreateOrUpdate(contractVersion int64, cluster *keb.Cluster) (*State, error)
-	UpdateStatus(State *State, status model.Status) (*State, error)
+	//UpdateStatus is a compare-and-swap operation: the status is only changed if the expected status is still
+	//the latest status of the cluster (otherwise a StatusConflictError is returned) and if the state machine allows
+	//the transition (otherwise a model.InvalidStatusTransitionError is returned)
+	UpdateStatus(expectedStatusID int64, status model.Status) (*State, error)
 	Delete(cluster string) error
 	Get(cluster string, configVersion int64) (*State, error)
 	GetLatest(cluster string) (*State, error)
 	StatusChanges(cluster string, offset time.Duration) ([]*StatusChange, error)
 	ClustersToReconcile(reconcileInterval time.Duration) ([]*State, error)
 	ClustersNotReady() ([]*State, error)
+	List(filter *ListFilter, page *Page) (*ListResult, error)
+	Purge(policy *RetentionPolicy) (*PurgeResult, error)
+	//WithActor returns an inventory which records its writes in the audit log in the name of the actor
+	WithActor(actor string) Inventory
 }
 
 type DefaultInventory struct {
@@ -32,6 +39,23 @@ type metricsCollector interface {
 	OnClusterStateUpdate(state *State) error
 }
 
+//StatusConflictError indicates that the status of a cluster was changed concurrently
+type StatusConflictError struct {
+	Cluster          string
+	ExpectedStatusID int64
+	LatestStatus     *model.ClusterStatusEntity
+}
+
+func (e *StatusConflictError) Error() string {
+	return fmt.Sprintf("Status of cluster '%s' was changed concurrently: expected latest status ID was %d but is %d (%s)",
+		e.Cluster, e.ExpectedStatusID, e.LatestStatus.ID, e.LatestStatus)
+}
+
+func IsStatusConflictError(err error) bool {
+	_, ok := err.(*StatusConflictError)
+	return ok
+}
+
 func NewInventory(dbFac db.ConnectionFactory, debug bool, collector metricsCollector) (Inventory, error) {
 	repo, err := repository.NewRepository(dbFac, debug)
 	if err != nil {
@@ -40,20 +64,83 @@ func NewInventory(dbFac db.ConnectionFactory, debug bool, collector metricsColle
 	return &DefaultInventory{repo, collector}, nil
 }
 
+//EncryptKubeconfigs encrypts kubeconfigs which are stored in plain text (e.g. they were stored before
+//encryption at rest was introduced). Kubeconfigs are always encrypted when a cluster is created or updated.
+func (i *DefaultInventory) EncryptKubeconfigs() error {
+	return db.NewKeyRotation(i.Conn, i.Logger).Encrypt(&db.EncryptedColumn{
+		Entity:  &model.ClusterEntity{},
+		Field:   "Kubeconfig",
+		IDField: "Version",
+	})
+}
+
+//withTx returns a copy of the inventory which executes all statements within the transaction
+func (i *DefaultInventory) withTx(tx *db.Tx) *DefaultInventory {
+	return &DefaultInventory{i.Repository.WithTx(tx), i.metricsCollector}
+}
+
+func (i *DefaultInventory) WithActor(actor string) Inventory {
+	return &DefaultInventory{i.Repository.WithActor(actor), i.metricsCollector}
+}
+
+//audit records a write in the audit log (in the name of the actor of the inventory)
+func (i *DefaultInventory) audit(action model.AuditAction, entityType, target string, versionBefore, versionAfter int64, details string) error {
+	return audit.Record(i.Conn, &model.AuditEntity{
+		Actor:         i.Actor,
+		Action:        action,
+		EntityType:    entityType,
+		Target:        target,
+		VersionBefore: versionBefore,
+		VersionAfter:  versionAfter,
+		Details:       details,
+	})
+}
+
+//latestConfigVersion returns the version of the latest configuration of a cluster or 0 if the cluster doesn't exist
+func (i *DefaultInventory) latestConfigVersion(cluster string) (int64, error) {
+	latestStatus, err := i.latestClusterStatus(cluster)
+	if err != nil {
+		if repository.IsNotFoundError(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return latestStatus.ConfigVersion, nil
+}
+
 func (i *DefaultInventory) CreateOrUpdate(contractVersion int64, cluster *keb.Cluster) (*State, error) {
-	dbOps := func() (interface{}, error) {
-		clusterEntity, err := i.createCluster(contractVersion, cluster)
+	dbOps := func(tx *db.Tx) (interface{}, error) {
+		//serialize with concurrent status updates of the cluster
+		if err := tx.Lock(cluster.Cluster); err != nil {
+			return nil, err
+		}
+		txInventory := i.withTx(tx)
+		configVersionBefore, err := txInventory.latestConfigVersion(cluster.Cluster)
+		if err != nil {
+			return nil, err
+		}
+		clusterEntity, err := txInventory.createCluster(contractVersion, cluster)
 		if err != nil {
 			return nil, err
 		}
-		clusterConfigurationEntity, err := i.createConfiguration(contractVersion, cluster, clusterEntity)
+		clusterConfigurationEntity, err := txInventory.createConfiguration(contractVersion, cluster, clusterEntity)
 		if err != nil {
 			return nil, err
 		}
-		clusterStatusEntity, err := i.createStatus(clusterConfigurationEntity, model.ClusterStatusReconcilePending)
+		clusterStatusEntity, err := txInventory.createStatus(clusterConfigurationEntity, model.ClusterStatusReconcilePending)
 		if err != nil {
 			return nil, err
 		}
+		if configVersionBefore != clusterConfigurationEntity.Version { //unchanged clusters aren't audited
+			action := model.AuditActionUpdate
+			if configVersionBefore == 0 {
+				action = model.AuditActionCreate
+			}
+			if err := txInventory.audit(action, model.AuditEntityCluster, cluster.Cluster,
+				configVersionBefore, clusterConfigurationEntity.Version, ""); err != nil {
+				return nil, err
+			}
+		}
 		return &State{
 			Cluster:       clusterEntity,
 			Configuration: clusterConfigurationEntity,
@@ -191,64 +278,88 @@ func (i *DefaultInventory) createStatus(configEntity *model.ClusterConfiguration
 	return newStatusEntity, nil
 }
 
-func (i *DefaultInventory) UpdateStatus(state *State, status model.Status) (*State, error) {
-	newStatus, err := i.createStatus(state.Configuration, status)
-	if err != nil {
-		return state, err
-	}
-	state.Status = newStatus
-	err = i.metricsCollector.OnClusterStateUpdate(state)
-	if err != nil {
-		return state, err
-	}
-	return state, nil
-}
-
-func (i *DefaultInventory) Delete(cluster string) error {
-	dbOps := func() error {
-		newClusterName := fmt.Sprintf("deleted_%d_%s", time.Now().Unix(), cluster)
-		updateSQLTpl := "UPDATE %s SET %s=$1, %s='TRUE' WHERE %s=$2 OR %s=$3" //OR condition required for Postgres: new cluster-name is automatically cascaded to config-status table
-
-		//update name of all cluster entities
-		clusterEntity := &model.ClusterEntity{}
-		clusterColHandler, err := db.NewColumnHandler(clusterEntity, i.Conn)
+func (i *DefaultInventory) UpdateStatus(expectedStatusID int64, status model.Status) (*State, error) {
+	dbOps := func(tx *db.Tx) (interface{}, error) {
+		txInventory := i.withTx(tx)
+		expectedStatus, err := txInventory.status(expectedStatusID)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		clusterColName, err := clusterColHandler.ColumnName("Cluster")
-		if err != nil {
-			return err
+		//serialize status changes of the cluster and verify that nobody changed the status meanwhile
+		if err := tx.Lock(expectedStatus.Cluster); err != nil {
+			return nil, err
 		}
-		clusterDelColName, err := clusterColHandler.ColumnName("Deleted")
+		latestStatus, err := txInventory.latestClusterStatus(expectedStatus.Cluster)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		clusterUpdateSQL := fmt.Sprintf(updateSQLTpl, clusterEntity.Table(), clusterColName, clusterDelColName, clusterColName, clusterColName)
-		if _, err := i.Conn.Exec(clusterUpdateSQL, newClusterName, cluster, newClusterName); err != nil {
-			return err
+		if latestStatus.ID != expectedStatusID {
+			return nil, &StatusConflictError{
+				Cluster:          expectedStatus.Cluster,
+				ExpectedStatusID: expectedStatusID,
+				LatestStatus:     latestStatus,
+			}
 		}
-
-		//update cluster-name of all referenced cluster-config entities
-		configEntity := &model.ClusterConfigurationEntity{}
-		configColHandler, err := db.NewColumnHandler(configEntity, i.Conn)
+		if err := model.ValidateStatusTransition(latestStatus.Status, status); err != nil {
+			return nil, err
+		}
+		configEntity, err := txInventory.config(expectedStatus.Cluster, expectedStatus.ConfigVersion)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		configClusterColName, err := configColHandler.ColumnName("Cluster")
+		newStatus, err := txInventory.createStatus(configEntity, status)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		configDelColName, err := configColHandler.ColumnName("Deleted")
+		if newStatus.ID != latestStatus.ID {
+			if err := txInventory.audit(model.AuditActionUpdate, model.AuditEntityStatus, expectedStatus.Cluster,
+				latestStatus.ID, newStatus.ID, fmt.Sprintf("%s -> %s", latestStatus.Status, newStatus.Status)); err != nil {
+				return nil, err
+			}
+		}
+		return txInventory.Get(expectedStatus.Cluster, expectedStatus.ConfigVersion)
+	}
+	result, err := db.TransactionResult(i.Conn, dbOps, i.Logger)
+	if err != nil {
+		return nil, err
+	}
+	state := result.(*State)
+	if err := i.metricsCollector.OnClusterStateUpdate(state); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+func (i *DefaultInventory) Delete(cluster string) error {
+	dbOps := func(tx *db.Tx) error {
+		txInventory := i.withTx(tx)
+		configVersionBefore, err := txInventory.latestConfigVersion(cluster)
 		if err != nil {
 			return err
 		}
-		configUpdateSQL := fmt.Sprintf(updateSQLTpl, configEntity.Table(), configClusterColName, configDelColName, configClusterColName, configClusterColName)
-		if _, err := i.Conn.Exec(configUpdateSQL, newClusterName, cluster, newClusterName); err != nil {
-			return err
+		newClusterName := fmt.Sprintf("deleted_%d_%s", time.Now().Unix(), cluster)
+
+		//rename and flag all cluster entities and all referenced cluster-config entities
+		for _, entity := range []db.DatabaseEntity{&model.ClusterEntity{}, &model.ClusterConfigurationEntity{}} {
+			q, err := db.NewQuery(tx, entity)
+			if err != nil {
+				return err
+			}
+			_, err = q.UpdateMany(map[string]interface{}{
+				"Cluster": newClusterName,
+				"Deleted": true,
+			}).
+				WhereCondition(db.Or( //OR condition required for Postgres: new cluster-name is automatically cascaded to config-status table
+					db.Eq("Cluster", cluster),
+					db.Eq("Cluster", newClusterName))).
+				Exec()
+			if err != nil {
+				return err
+			}
 		}
 
-		//done
-		return nil
+		return txInventory.audit(model.AuditActionDelete, model.AuditEntityCluster, cluster, configVersionBefore, 0,
+			fmt.Sprintf("renamed to '%s'", newClusterName))
 	}
 	return db.Transaction(i.Conn, dbOps, i.Logger)
 }
@@ -312,6 +423,41 @@ func (i *DefaultInventory) latestStatus(configVersion int64) (*model.ClusterStat
 	return statusEntity.(*model.ClusterStatusEntity), nil
 }
 
+func (i *DefaultInventory) status(statusID int64) (*model.ClusterStatusEntity, error) {
+	q, err := db.NewQuery(i.Conn, &model.ClusterStatusEntity{})
+	if err != nil {
+		return nil, err
+	}
+	whereCond := map[string]interface{}{
+		"ID": statusID,
+	}
+	statusEntity, err := q.Select().
+		Where(whereCond).
+		GetOne()
+	if err != nil {
+		return nil, i.NewNotFoundError(err, statusEntity, whereCond)
+	}
+	return statusEntity.(*model.ClusterStatusEntity), nil
+}
+
+func (i *DefaultInventory) latestClusterStatus(cluster string) (*model.ClusterStatusEntity, error) {
+	q, err := db.NewQuery(i.Conn, &model.ClusterStatusEntity{})
+	if err != nil {
+		return nil, err
+	}
+	whereCond := map[string]interface{}{
+		"Cluster": cluster,
+	}
+	statusEntity, err := q.Select().
+		Where(whereCond).
+		OrderBy(map[string]string{"ID": "desc"}).
+		GetOne()
+	if err != nil {
+		return nil, i.NewNotFoundError(err, statusEntity, whereCond)
+	}
+	return statusEntity.(*model.ClusterStatusEntity), nil
+}
+
 func (i *DefaultInventory) config(cluster string, configVersion int64) (*model.ClusterConfigurationEntity, error) {
 	q, err := db.NewQuery(i.Conn, &model.ClusterConfigurationEntity{})
 	if err != nil {
@@ -408,100 +554,118 @@ func (i *DefaultInventory) ClustersNotReady() ([]*State, error) {
 }
 
 func (i *DefaultInventory) filterClusters(filters ...statusSQLFilter) ([]*State, error) {
-	//get DDL for sub-query
-	clusterStatus := &model.ClusterStatusEntity{}
-	statusColHandler, err := db.NewColumnHandler(clusterStatus, i.Conn)
-	if err != nil {
-		return nil, err
+	//if no filters are provided, all clusters match
+	filterCond := db.And()
+	if len(filters) > 0 {
+		var conds []db.Condition
+		for _, filter := range filters {
+			conds = append(conds, filter.Filter())
+		}
+		filterCond = db.Or(conds...)
 	}
-	idColName, err := statusColHandler.ColumnName("ID")
+
+	latestStatusIDs, err := i.latestStatusIDs()
 	if err != nil {
 		return nil, err
 	}
-	clusterColName, err := statusColHandler.ColumnName("Cluster")
+
+	//get cluster configurations whose latest status matches the filters
+	configEntity := &model.ClusterConfigurationEntity{}
+	q, err := db.NewQuery(i.Conn, configEntity)
 	if err != nil {
 		return nil, err
 	}
-	clusterVersionColName, err := statusColHandler.ColumnName("ClusterVersion")
+	clusterConfigs, err := q.Select().
+		Join(&model.ClusterStatusEntity{}, "Version", "ConfigVersion").
+		WhereCondition(
+			db.Eq(db.Field(configEntity, "Deleted"), false),
+			db.InQuery(statusField("ID"), latestStatusIDs),
+			filterCond).
+		GetMany()
 	if err != nil {
 		return nil, err
 	}
-	configVersionColName, err := statusColHandler.ColumnName("ConfigVersion")
-	if err != nil {
-		return nil, err
+
+	//retreive clusters which require a reconciliation
+	var result []*State
+	for _, clusterConfig := range clusterConfigs {
+		clusterConfigEntity := clusterConfig.(*model.ClusterConfigurationEntity)
+		state, err := i.Get(clusterConfigEntity.Cluster, clusterConfigEntity.Version)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, state)
 	}
 
-	//get cluster configurations of all "not-ready" clusters
-	q, err := db.NewQuery(i.Conn, &model.ClusterConfigurationEntity{})
+	return result, nil
+}
+
+//latestStatusIDs returns a sub-query which selects the ID of the latest status of each cluster
+func (i *DefaultInventory) latestStatusIDs() (*db.Select, error) {
+	q, err := db.NewQuery(i.Conn, &model.ClusterStatusEntity{})
 	if err != nil {
 		return nil, err
 	}
+	return q.Select().
+		Aggregate(db.AggregateMax, "ID").
+		GroupBy([]string{"Cluster"}), nil
+}
 
-	/*
-		select config_version from inventory_cluster_config_statuses where id in (
-			select maxid from (
-				select max(cluster_version) as maxcfg, max(id) as maxid from inventory_cluster_config_statuses group by cluster
-			) as maxid
-		) and (status in ('xxx', 'yyy') or (status = 'ready' AND created >=  NOW() - INTERVAL '12345 SECOND'))
-	*/
-	var sqlFilterStmt bytes.Buffer
-	if len(filters) == 0 {
-		sqlFilterStmt.WriteString("1=1") //if no filters are provided, use 1=1 as placeholder to ensure valid SQL query
+//List returns the latest state of all clusters matching the filter, ordered by the cluster name
+func (i *DefaultInventory) List(filter *ListFilter, page *Page) (*ListResult, error) {
+	if filter == nil {
+		filter = &ListFilter{}
 	}
-	for _, filter := range filters {
-		sqlCond, err := filter.Filter(i.Conn.Type(), statusColHandler)
-		if err != nil {
+	var lastCluster string
+	if page != nil && page.Cursor != "" {
+		var err error
+		if lastCluster, err = decodeCursor(page.Cursor); err != nil {
 			return nil, err
 		}
-		if sqlFilterStmt.Len() > 0 {
-			sqlFilterStmt.WriteString(" OR ")
-		}
-		sqlFilterStmt.WriteRune('(')
-		sqlFilterStmt.WriteString(sqlCond)
-		sqlFilterStmt.WriteRune(')')
 	}
 
+	conds, err := i.listConditions(filter, lastCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	configEntity := &model.ClusterConfigurationEntity{}
+	q, err := db.NewQuery(i.Conn, configEntity)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := page.size()
 	clusterConfigs, err := q.Select().
-		WhereIn("Version",
-			fmt.Sprintf(`SELECT %s FROM %s WHERE %s IN (
-							SELECT maxid FROM (
-								SELECT MAX(%s) AS maxcfg, MAX(%s) AS maxid FROM %s GROUP BY %s
-							) AS maxid
-						) AND (%s)`,
-				configVersionColName, clusterStatus.Table(), idColName,
-				clusterVersionColName, idColName, clusterStatus.Table(), clusterColName,
-				sqlFilterStmt.String())).
-		Where(map[string]interface{}{
-			"Deleted": false,
-		}).
+		Join(&model.ClusterStatusEntity{}, "Version", "ConfigVersion").
+		WhereCondition(conds...).
+		OrderBy(map[string]string{db.Field(configEntity, "Cluster"): "ASC"}).
+		Limit(pageSize + 1). //fetch one additional entry to detect whether a next page exists
 		GetMany()
 	if err != nil {
 		return nil, err
 	}
 
-	//retreive clusters which require a reconciliation
-	var result []*State
-	for _, clusterConfig := range clusterConfigs {
+	result := &ListResult{
+		States: []*State{},
+	}
+	for idx, clusterConfig := range clusterConfigs {
+		if idx == pageSize {
+			result.NextCursor = encodeCursor(result.States[pageSize-1].Cluster.Cluster)
+			break
+		}
 		clusterConfigEntity := clusterConfig.(*model.ClusterConfigurationEntity)
 		state, err := i.Get(clusterConfigEntity.Cluster, clusterConfigEntity.Version)
 		if err != nil {
 			return nil, err
 		}
-		result = append(result, state)
+		result.States = append(result.States, state)
 	}
-
 	return result, nil
 }
 
 func (i *DefaultInventory) StatusChanges(cluster string, offset time.Duration) ([]*StatusChange, error) {
 	clusterStatusEntity := &model.ClusterStatusEntity{}
-
-	//build sub-query
-	statusColHandler, err := db.NewColumnHandler(clusterStatusEntity, i.Conn)
-	if err != nil {
-		return nil, err
-	}
-	idColName, err := statusColHandler.ColumnName("ID")
+	q, err := db.NewQuery(i.Conn, clusterStatusEntity)
 	if err != nil {
 		return nil, err
 	}
@@ -510,19 +674,8 @@ func (i *DefaultInventory) StatusChanges(cluster string, offset time.Duration) (
 		interval: offset,
 		cluster:  cluster,
 	}
-	sqlCond, err := filter.Filter(i.Conn.Type(), statusColHandler)
-	if err != nil {
-		return nil, err
-	}
-
-	//query status entities (using sub-query in WHERE condition)
-	q, err := db.NewQuery(i.Conn, clusterStatusEntity)
-	if err != nil {
-		return nil, err
-	}
-
 	clusterStatuses, err := q.Select().
-		WhereIn("ID", fmt.Sprintf("SELECT %s FROM %s WHERE %s", idColName, clusterStatusEntity.Table(), sqlCond)).
+		WhereCondition(filter.Filter()).
 		OrderBy(map[string]string{"Created": "DESC"}).
 		GetMany()
 	if err != nil {
@@ -8,10 +8,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/kyma-incubator/reconciler/pkg/audit"
 	"github.com/kyma-incubator/reconciler/pkg/db"
 	"github.com/kyma-incubator/reconciler/pkg/keb"
 	"github.com/kyma-incubator/reconciler/pkg/model"
 	"github.com/kyma-incubator/reconciler/pkg/repository"
+	"github.com/kyma-incubator/reconciler/pkg/test"
 	"github.com/stretchr/testify/require"
 )
 
@@ -22,7 +24,66 @@ const (
 var clusterJSONFile = filepath.Join(".", "test", "cluster.json")
 var clusterStatuses = []model.Status{model.ClusterStatusError, model.ClusterStatusReady, model.ClusterStatusReconcileFailed, model.ClusterStatusReconcilePending, model.ClusterStatusReconciling}
 
+//statusPaths lists the status transitions allowed by the state machine which lead from 'reconcile_pending' to a status
+var statusPaths = map[model.Status][]model.Status{
+	model.ClusterStatusReconcilePending: {},
+	model.ClusterStatusReconciling:      {model.ClusterStatusReconciling},
+	model.ClusterStatusReady:            {model.ClusterStatusReconciling, model.ClusterStatusReady},
+	model.ClusterStatusReconcileFailed:  {model.ClusterStatusReconciling, model.ClusterStatusReconcileFailed},
+	model.ClusterStatusError:            {model.ClusterStatusError},
+}
+
+//updateStatus changes the status of a cluster in status 'reconcile_pending' by allowed status transitions
+func updateStatus(t *testing.T, inventory Inventory, state *State, status model.Status) *State {
+	require.Equal(t, model.ClusterStatusReconcilePending, state.Status.Status)
+	for _, nextStatus := range statusPaths[status] {
+		var err error
+		state, err = inventory.UpdateStatus(state.Status.ID, nextStatus)
+		require.NoError(t, err)
+	}
+	return state
+}
+
+//inventoryBackend is an implementation of the inventory which has to pass the conformance tests
+type inventoryBackend struct {
+	name         string
+	integration  bool //database is only available in integration tests
+	newInventory func(t *testing.T) Inventory
+}
+
+var databaseBackends = []inventoryBackend{
+	{name: "SQLite", newInventory: newInventory},
+	{name: "Postgres", integration: true, newInventory: newPostgresInventory},
+}
+
+var inventoryBackends = []inventoryBackend{
+	databaseBackends[0],
+	databaseBackends[1],
+	{name: "In-memory", newInventory: newInMemoryInventory},
+}
+
+//runConformanceTest executes the test for each backend: the test has to create its inventories by the passed function
+func runConformanceTest(t *testing.T, backends []inventoryBackend, testFct func(t *testing.T, newInventory func(t *testing.T) Inventory)) {
+	for _, backend := range backends {
+		backend := backend
+		t.Run(backend.name, func(t *testing.T) {
+			if backend.integration {
+				test.IntegrationTest(t)
+			}
+			testFct(t, backend.newInventory)
+		})
+	}
+}
+
 func TestInventory(t *testing.T) {
+	runConformanceTest(t, inventoryBackends, testInventory)
+}
+
+func TestInventoryEncryption(t *testing.T) {
+	runConformanceTest(t, databaseBackends, testInventoryEncryption)
+}
+
+func testInventory(t *testing.T, newInventory func(t *testing.T) Inventory) {
 	inventory := newInventory(t)
 
 	t.Run("Create a cluster", func(t *testing.T) {
@@ -73,15 +134,53 @@ func TestInventory(t *testing.T) {
 		require.Equal(t, clusterState.Status.Status, model.ClusterStatusReconcilePending)
 		oldStatusID := clusterState.Status.ID
 		//update status with same status (should NOT cause a status change)
-		newState, err := inventory.UpdateStatus(clusterState, model.ClusterStatusReconcilePending)
+		newState, err := inventory.UpdateStatus(clusterState.Status.ID, model.ClusterStatusReconcilePending)
 		require.NoError(t, err)
 		require.Equal(t, newState.Status.Status, model.ClusterStatusReconcilePending)
 		require.Equal(t, oldStatusID, newState.Status.ID)
 		//update status with new status (has to cause a status change)
-		newState2, err := inventory.UpdateStatus(clusterState, model.ClusterStatusReconciling)
+		newState2, err := inventory.UpdateStatus(clusterState.Status.ID, model.ClusterStatusReconciling)
 		require.NoError(t, err)
 		require.Equal(t, newState2.Status.Status, model.ClusterStatusReconciling)
 		require.True(t, oldStatusID < newState2.Status.ID)
+		//transition is not allowed by the state machine
+		_, err = inventory.UpdateStatus(newState2.Status.ID, model.ClusterStatusReconcilePending)
+		require.Error(t, err)
+		require.True(t, model.IsInvalidStatusTransitionError(err))
+		//status of an unknown status ID cannot be updated
+		_, err = inventory.UpdateStatus(-1, model.ClusterStatusReady)
+		require.Error(t, err)
+		require.True(t, repository.IsNotFoundError(err))
+	})
+
+	t.Run("Update cluster status using outdated state", func(t *testing.T) {
+		cluster := newCluster(t, 1, maxVersion)
+		state1, err := inventory.GetLatest(cluster.Cluster)
+		require.NoError(t, err)
+		state2, err := inventory.GetLatest(cluster.Cluster)
+		require.NoError(t, err)
+
+		require.Equal(t, model.ClusterStatusReconciling, state1.Status.Status)
+
+		//state1 changes the status: state2 is outdated afterwards
+		newState1, err := inventory.UpdateStatus(state1.Status.ID, model.ClusterStatusReady)
+		require.NoError(t, err)
+		_, err = inventory.UpdateStatus(state2.Status.ID, model.ClusterStatusError)
+		require.Error(t, err)
+		require.True(t, IsStatusConflictError(err))
+		require.Equal(t, newState1.Status.ID, err.(*StatusConflictError).LatestStatus.ID)
+
+		//new configuration makes the state outdated
+		newState2, err := inventory.CreateOrUpdate(1, newCluster(t, 1, maxVersion+1))
+		require.NoError(t, err)
+		_, err = inventory.UpdateStatus(newState1.Status.ID, model.ClusterStatusReconcilePending)
+		require.Error(t, err)
+		require.True(t, IsStatusConflictError(err))
+		require.Equal(t, newState2.Configuration.Version, err.(*StatusConflictError).LatestStatus.ConfigVersion)
+
+		latestState, err := inventory.GetLatest(cluster.Cluster)
+		require.NoError(t, err)
+		require.Equal(t, newState2.Status.ID, latestState.Status.ID)
 	})
 
 	t.Run("Delete a cluster", func(t *testing.T) {
@@ -106,12 +205,8 @@ func TestInventory(t *testing.T) {
 			clusterState, err := inventory.CreateOrUpdate(1, newCluster)
 			require.NoError(t, err)
 			expectedClusters = append(expectedClusters, newCluster)
-			//add another status to verify that SQL query works correctly
-			_, err = inventory.UpdateStatus(clusterState, model.ClusterStatusReconcileFailed)
-			require.NoError(t, err)
-			//add expected status
-			_, err = inventory.UpdateStatus(clusterState, clusterStatus)
-			require.NoError(t, err)
+			//add expected status (interim statuses verify that SQL query works correctly)
+			updateStatus(t, inventory, clusterState, clusterStatus)
 		}
 
 		defer func() {
@@ -145,8 +240,7 @@ func TestInventory(t *testing.T) {
 		cluster1State1a, err := inventory.CreateOrUpdate(1, cluster1v1)
 		require.NoError(t, err)
 		require.Equal(t, model.ClusterStatusReconcilePending, cluster1State1a.Status.Status)
-		cluster1State1b, err := inventory.UpdateStatus(cluster1State1a, model.ClusterStatusReady)
-		require.NoError(t, err)
+		cluster1State1b := updateStatus(t, inventory, cluster1State1a, model.ClusterStatusReady)
 		require.Equal(t, model.ClusterStatusReady, cluster1State1b.Status.Status)
 
 		//create cluster1, version2, status: ReconcileFailed
@@ -154,8 +248,7 @@ func TestInventory(t *testing.T) {
 		cluster1State2a, err := inventory.CreateOrUpdate(1, cluster1v2)
 		require.NoError(t, err)
 		require.Equal(t, model.ClusterStatusReconcilePending, cluster1State2a.Status.Status)
-		cluster1State2b, err := inventory.UpdateStatus(cluster1State2a, model.ClusterStatusReconcileFailed)
-		require.NoError(t, err)
+		cluster1State2b := updateStatus(t, inventory, cluster1State2a, model.ClusterStatusReconcileFailed)
 		require.Equal(t, model.ClusterStatusReconcileFailed, cluster1State2b.Status.Status)
 
 		//create cluster1, version3, status: ReconcilePending
@@ -169,19 +262,17 @@ func TestInventory(t *testing.T) {
 		cluster2State1a, err := inventory.CreateOrUpdate(1, cluster2v1)
 		require.NoError(t, err)
 		require.Equal(t, model.ClusterStatusReconcilePending, cluster2State1a.Status.Status)
-		cluster2State1b, err := inventory.UpdateStatus(cluster2State1a, model.ClusterStatusError)
-		require.NoError(t, err)
+		cluster2State1b := updateStatus(t, inventory, cluster2State1a, model.ClusterStatusError)
 		require.Equal(t, model.ClusterStatusError, cluster2State1b.Status.Status)
 
-		//create cluster3, version1, status: Error
+		//create cluster3, version1, status: Reconciling -> Error
 		cluster3v1 := newCluster(t, int64(3), 1)
 		cluster3State1a, err := inventory.CreateOrUpdate(1, cluster3v1)
 		require.NoError(t, err)
 		require.Equal(t, model.ClusterStatusReconcilePending, cluster3State1a.Status.Status)
-		cluster3State1b, err := inventory.UpdateStatus(cluster3State1a, model.ClusterStatusReady)
-		require.NoError(t, err)
-		require.Equal(t, model.ClusterStatusReady, cluster3State1b.Status.Status)
-		expectedCluster3State1c, err := inventory.UpdateStatus(cluster3State1b, model.ClusterStatusError) //<- EXPECTED STATE
+		cluster3State1b := updateStatus(t, inventory, cluster3State1a, model.ClusterStatusReconciling)
+		require.Equal(t, model.ClusterStatusReconciling, cluster3State1b.Status.Status)
+		expectedCluster3State1c, err := inventory.UpdateStatus(cluster3State1b.Status.ID, model.ClusterStatusError) //<- EXPECTED STATE
 		require.NoError(t, err)
 		require.Equal(t, model.ClusterStatusError, expectedCluster3State1c.Status.Status)
 
@@ -190,8 +281,7 @@ func TestInventory(t *testing.T) {
 		cluster2State2a, err := inventory.CreateOrUpdate(1, cluster2v2)
 		require.NoError(t, err)
 		require.Equal(t, model.ClusterStatusReconcilePending, cluster2State2a.Status.Status)
-		expectedCluster2State2b, err := inventory.UpdateStatus(cluster2State2a, model.ClusterStatusReconcileFailed) //<- EXPECTED STATE
-		require.NoError(t, err)
+		expectedCluster2State2b := updateStatus(t, inventory, cluster2State2a, model.ClusterStatusReconcileFailed) //<- EXPECTED STATE
 		require.Equal(t, model.ClusterStatusReconcileFailed, expectedCluster2State2b.Status.Status)
 
 		defer func() {
@@ -212,20 +302,146 @@ func TestInventory(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, statesNotReady, 2)
 		require.ElementsMatch(t, []*State{expectedCluster2State2b, expectedCluster3State1c}, statesNotReady)
+	})
+
+	t.Run("Get ready clusters outside of reconcile interval", func(t *testing.T) {
+		inventory := newInventory(t)
+		readyCluster := newCluster(t, int64(1), 1)
+		state, err := inventory.CreateOrUpdate(1, readyCluster)
+		require.NoError(t, err)
+		updateStatus(t, inventory, state, model.ClusterStatusReady)
+		defer func() {
+			require.NoError(t, inventory.Delete(readyCluster.Cluster))
+		}()
+
+		//ready cluster is inside of the reconcile interval
+		statesReconcile, err := inventory.ClustersToReconcile(time.Hour)
+		require.NoError(t, err)
+		require.Empty(t, statesReconcile)
+
+		//ready cluster is outside of the reconcile interval (timestamps are compared with a precision of seconds)
+		time.Sleep(1 * time.Second)
+		statesReconcile, err = inventory.ClustersToReconcile(time.Millisecond)
+		require.NoError(t, err)
+		require.Len(t, statesReconcile, 1)
+		require.Equal(t, readyCluster.Cluster, statesReconcile[0].Cluster.Cluster)
+	})
+
+	t.Run("List clusters", func(t *testing.T) {
+		inventory := newInventory(t)
+		var expectedClusters []string
+
+		//create for each cluster-status a new cluster (the cluster version defines Kyma version and metadata)
+		for idx, clusterStatus := range clusterStatuses {
+			newCluster := newCluster(t, int64(idx+1), int64(idx+1))
+			clusterState, err := inventory.CreateOrUpdate(1, newCluster)
+			require.NoError(t, err)
+			expectedClusters = append(expectedClusters, newCluster.Cluster)
+			updateStatus(t, inventory, clusterState, clusterStatus)
+		}
+
+		defer func() {
+			//cleanup
+			for _, cluster := range expectedClusters {
+				require.NoError(t, inventory.Delete(cluster))
+			}
+		}()
 
-		//TODO: test for clusters which are inside and outside of filter interval
+		t.Run("Without filter", func(t *testing.T) {
+			result, err := inventory.List(nil, nil)
+			require.NoError(t, err)
+			require.Equal(t, expectedClusters, listClusters(result.States))
+			require.Empty(t, result.NextCursor)
+		})
+
+		t.Run("Filter by status", func(t *testing.T) {
+			result, err := inventory.List(&ListFilter{
+				Statuses: []model.Status{model.ClusterStatusError, model.ClusterStatusReady},
+			}, nil)
+			require.NoError(t, err)
+			require.Equal(t, []string{"cluster1", "cluster2"}, listClusters(result.States))
+			require.ElementsMatch(t,
+				[]model.Status{model.ClusterStatusError, model.ClusterStatusReady},
+				listStatuses(result.States))
+		})
+
+		t.Run("Filter by Kyma version and profile", func(t *testing.T) {
+			result, err := inventory.List(&ListFilter{KymaVersion: "kymaVersion3"}, nil)
+			require.NoError(t, err)
+			require.Equal(t, []string{"cluster3"}, listClusters(result.States))
+
+			result, err = inventory.List(&ListFilter{KymaVersion: "kymaVersion3", KymaProfile: "kymaProfile4"}, nil)
+			require.NoError(t, err)
+			require.Empty(t, result.States)
+		})
+
+		t.Run("Filter by metadata", func(t *testing.T) {
+			result, err := inventory.List(&ListFilter{GlobalAccountID: "globalAccountId2"}, nil)
+			require.NoError(t, err)
+			require.Equal(t, []string{"cluster2"}, listClusters(result.States))
+
+			//wildcards are not interpreted
+			result, err = inventory.List(&ListFilter{GlobalAccountID: "globalAccountId%"}, nil)
+			require.NoError(t, err)
+			require.Empty(t, result.States)
+		})
+
+		t.Run("Filter by update time", func(t *testing.T) {
+			result, err := inventory.List(&ListFilter{UpdatedAfter: time.Now().Add(-1 * time.Hour)}, nil)
+			require.NoError(t, err)
+			require.Equal(t, expectedClusters, listClusters(result.States))
+
+			result, err = inventory.List(&ListFilter{UpdatedBefore: time.Now().Add(-1 * time.Hour)}, nil)
+			require.NoError(t, err)
+			require.Empty(t, result.States)
+		})
+
+		t.Run("Paginate", func(t *testing.T) {
+			var clusters []string
+			var pages int
+			page := &Page{Size: 2}
+			for {
+				result, err := inventory.List(nil, page)
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(result.States), 2)
+				clusters = append(clusters, listClusters(result.States)...)
+				pages++
+				if result.NextCursor == "" {
+					break
+				}
+				page.Cursor = result.NextCursor
+			}
+			require.Equal(t, 3, pages)
+			require.Equal(t, expectedClusters, clusters)
+		})
+
+		t.Run("Invalid cursor", func(t *testing.T) {
+			_, err := inventory.List(nil, &Page{Cursor: "%%%"})
+			require.Error(t, err)
+			require.True(t, IsInvalidCursorError(err))
+		})
+
+		t.Run("Deleted clusters are ignored", func(t *testing.T) {
+			require.NoError(t, inventory.Delete(expectedClusters[0]))
+			expectedClusters = expectedClusters[1:]
+			result, err := inventory.List(nil, nil)
+			require.NoError(t, err)
+			require.Equal(t, expectedClusters, listClusters(result.States))
+		})
 	})
 
 	t.Run("Get status changes", func(t *testing.T) {
 		inventory := newInventory(t)
-		expectedStatuses := append(clusterStatuses, model.ClusterStatusReconcilePending)
+		//each cluster-status is reached by transitions allowed by the state machine
+		statuses := []model.Status{model.ClusterStatusReconciling, model.ClusterStatusReconcileFailed,
+			model.ClusterStatusReconciling, model.ClusterStatusReady, model.ClusterStatusReconcilePending, model.ClusterStatusError}
+		expectedStatuses := append(statuses, model.ClusterStatusReconcilePending)
 		newCluster := newCluster(t, 1, 1)
 		clusterState, err := inventory.CreateOrUpdate(1, newCluster)
 		require.NoError(t, err)
-		// //create for each cluster-status a new cluster
-		for _, clusterStatus := range clusterStatuses {
+		for _, clusterStatus := range statuses {
 			//add expected status
-			_, err = inventory.UpdateStatus(clusterState, clusterStatus)
+			clusterState, err = inventory.UpdateStatus(clusterState.Status.ID, clusterStatus)
 			require.NoError(t, err)
 		}
 
@@ -238,11 +454,104 @@ func TestInventory(t *testing.T) {
 		changes, err := inventory.StatusChanges("cluster1", duration)
 		require.NoError(t, err)
 
-		require.Len(t, changes, 6)
+		require.Len(t, changes, 7)
 		require.ElementsMatch(t,
 			listStatusesForStatusChanges(changes),
 			expectedStatuses)
 	})
+	t.Run("Writes are audited in the name of the actor", func(t *testing.T) {
+		inventory := newInventory(t)
+		actorInventory := inventory.WithActor("keb")
+		clusterV1 := newCluster(t, 1, 1)
+		clusterV1.Cluster = fmt.Sprintf("auditcluster%d", time.Now().UnixNano())
+		sink := &auditSink{target: clusterV1.Cluster}
+		audit.RegisterSink(sink)
+		defer audit.UnregisterSink(sink)
+
+		stateV1, err := actorInventory.CreateOrUpdate(1, clusterV1)
+		require.NoError(t, err)
+		_, err = actorInventory.CreateOrUpdate(1, clusterV1) //unchanged cluster is not audited
+		require.NoError(t, err)
+
+		clusterV2 := newCluster(t, 1, 2)
+		clusterV2.Cluster = clusterV1.Cluster
+		stateV2, err := actorInventory.CreateOrUpdate(1, clusterV2)
+		require.NoError(t, err)
+		statusBefore := stateV2.Status.ID
+		stateV2, err = actorInventory.UpdateStatus(stateV2.Status.ID, model.ClusterStatusReconciling)
+		require.NoError(t, err)
+		require.NoError(t, actorInventory.Delete(clusterV1.Cluster))
+
+		require.Len(t, sink.entries, 4)
+		expected := []struct {
+			action        model.AuditAction
+			entityType    string
+			versionBefore int64
+			versionAfter  int64
+		}{
+			{model.AuditActionCreate, model.AuditEntityCluster, 0, stateV1.Configuration.Version},
+			{model.AuditActionUpdate, model.AuditEntityCluster, stateV1.Configuration.Version, stateV2.Configuration.Version},
+			{model.AuditActionUpdate, model.AuditEntityStatus, statusBefore, stateV2.Status.ID},
+			{model.AuditActionDelete, model.AuditEntityCluster, stateV2.Configuration.Version, 0},
+		}
+		for idx, entry := range sink.entries {
+			require.Equal(t, "keb", entry.Actor)
+			require.Equal(t, expected[idx].action, entry.Action)
+			require.Equal(t, expected[idx].entityType, entry.EntityType)
+			require.Equal(t, expected[idx].versionBefore, entry.VersionBefore)
+			require.Equal(t, expected[idx].versionAfter, entry.VersionAfter)
+		}
+		require.Equal(t, "reconcile_pending -> reconciling", sink.entries[2].Details)
+	})
+}
+
+func testInventoryEncryption(t *testing.T, newInventory func(t *testing.T) Inventory) {
+	t.Run("Kubeconfig is encrypted at rest", func(t *testing.T) {
+		inventory := newInventory(t)
+		newCluster := newCluster(t, 1, 1)
+		clusterState, err := inventory.CreateOrUpdate(1, newCluster)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, inventory.Delete(newCluster.Cluster))
+		}()
+
+		conn := inventory.(*DefaultInventory).Conn
+		var kubeconfig string
+		require.NoError(t, conn.QueryRow("SELECT kubeconfig FROM inventory_clusters WHERE version=$1", clusterState.Cluster.Version).
+			Scan(&kubeconfig))
+		require.NotEqual(t, newCluster.Kubeconfig, kubeconfig)
+		require.True(t, conn.Encryptor().EncryptedWithActiveKey(kubeconfig))
+
+		//kubeconfig is transparently decrypted
+		clusterState, err = inventory.GetLatest(newCluster.Cluster)
+		require.NoError(t, err)
+		require.Equal(t, newCluster.Kubeconfig, clusterState.Cluster.Kubeconfig)
+		require.NotContains(t, clusterState.String(), newCluster.Kubeconfig)
+		require.NotContains(t, clusterState.Cluster.String(), newCluster.Kubeconfig)
+	})
+
+	t.Run("Encrypt plain text kubeconfigs", func(t *testing.T) {
+		inventory := newInventory(t)
+		newCluster := newCluster(t, 1, 1)
+		clusterState, err := inventory.CreateOrUpdate(1, newCluster)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, inventory.Delete(newCluster.Cluster))
+		}()
+
+		//store kubeconfig in plain text (like before encryption at rest was introduced)
+		conn := inventory.(*DefaultInventory).Conn
+		_, err = conn.Exec("UPDATE inventory_clusters SET kubeconfig=$1 WHERE version=$2",
+			newCluster.Kubeconfig, clusterState.Cluster.Version)
+		require.NoError(t, err)
+		_, err = inventory.GetLatest(newCluster.Cluster)
+		require.Error(t, err)
+
+		require.NoError(t, inventory.(*DefaultInventory).EncryptKubeconfigs())
+		clusterState, err = inventory.GetLatest(newCluster.Cluster)
+		require.NoError(t, err)
+		require.Equal(t, newCluster.Kubeconfig, clusterState.Cluster.Kubeconfig)
+	})
 }
 
 func listStatuses(states []*State) []model.Status {
@@ -253,6 +562,14 @@ func listStatuses(states []*State) []model.Status {
 	return result
 }
 
+func listClusters(states []*State) []string {
+	result := []string{}
+	for _, state := range states {
+		result = append(result, state.Cluster.Cluster)
+	}
+	return result
+}
+
 func listStatusesForStatusChanges(states []*StatusChange) []model.Status {
 	var result []model.Status
 	for _, state := range states {
@@ -267,6 +584,18 @@ func (collector fakeMetricsCollector) OnClusterStateUpdate(state *State) error {
 	return nil
 }
 
+//auditSink collects the exported audit entries of a target
+type auditSink struct {
+	target  string
+	entries []*model.AuditEntity
+}
+
+func (s *auditSink) Export(entry *model.AuditEntity) {
+	if entry.Target == s.target {
+		s.entries = append(s.entries, entry)
+	}
+}
+
 func newInventory(t *testing.T) Inventory {
 	connFact, err := db.NewTestConnectionFactory()
 	require.NoError(t, err)
@@ -276,6 +605,19 @@ func newInventory(t *testing.T) Inventory {
 	return inventory
 }
 
+func newPostgresInventory(t *testing.T) Inventory {
+	connFact, err := db.NewTestPostgresConnectionFactory()
+	require.NoError(t, err)
+
+	inventory, err := NewInventory(connFact, true, fakeMetricsCollector{})
+	require.NoError(t, err)
+	return inventory
+}
+
+func newInMemoryInventory(t *testing.T) Inventory {
+	return NewInMemoryInventory(fakeMetricsCollector{})
+}
+
 func newCluster(t *testing.T, clusterID, clusterVersion int64) *keb.Cluster {
 	cluster := &keb.Cluster{}
 	data, err := ioutil.ReadFile(clusterJSONFile)
@@ -307,6 +649,8 @@ func compareState(t *testing.T, state *State, cluster *keb.Cluster) {
 	runtime, err := state.Cluster.GetRuntime()
 	require.NoError(t, err)
 	require.Equal(t, &cluster.RuntimeInput, runtime) //compare runtime-object
+	//compare kubeconfig (it's stored encrypted and has to be decrypted transparently)
+	require.Equal(t, cluster.Kubeconfig, state.Cluster.Kubeconfig)
 
 	// *** ClusterConfigurationEntity ***
 	require.Equal(t, int64(1), state.Configuration.Contract)
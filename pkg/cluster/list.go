@@ -0,0 +1,174 @@
+package cluster
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+	timestampFormat = "2006-01-02 15:04:05"
+)
+
+//ListFilter defines the criteria a cluster has to fulfil to be returned by Inventory.List.
+//Empty fields are ignored. Statuses and update times are evaluated against the latest status of a cluster.
+type ListFilter struct {
+	Statuses        []model.Status
+	KymaVersion     string
+	KymaProfile     string
+	GlobalAccountID string
+	SubAccountID    string
+	ServicePlanID   string
+	UpdatedAfter    time.Time
+	UpdatedBefore   time.Time
+}
+
+type metadataFilter struct {
+	field string
+	value string
+}
+
+//metadata returns the filtered metadata fields (only fields with a value are returned)
+func (f *ListFilter) metadata() []metadataFilter {
+	var result []metadataFilter
+	for _, md := range []metadataFilter{
+		{"globalAccountID", f.GlobalAccountID},
+		{"subAccountID", f.SubAccountID},
+		{"servicePlanID", f.ServicePlanID},
+	} {
+		if md.value != "" {
+			result = append(result, md)
+		}
+	}
+	return result
+}
+
+//Page defines the requested page of a listing: the cursor is the NextCursor of the previous result
+//(empty for the first page) and the size is limited by MaxPageSize (DefaultPageSize is used if it is not set)
+type Page struct {
+	Cursor string
+	Size   int
+}
+
+func (p *Page) size() int {
+	if p == nil || p.Size <= 0 {
+		return DefaultPageSize
+	}
+	if p.Size > MaxPageSize {
+		return MaxPageSize
+	}
+	return p.Size
+}
+
+//ListResult contains the clusters of a page ordered by their name.
+//NextCursor is empty if no further pages exist.
+type ListResult struct {
+	States     []*State
+	NextCursor string
+}
+
+type InvalidCursorError struct {
+	cursor string
+}
+
+func (e *InvalidCursorError) Error() string {
+	return fmt.Sprintf("Page cursor '%s' is invalid", e.cursor)
+}
+
+func IsInvalidCursorError(err error) bool {
+	_, ok := err.(*InvalidCursorError)
+	return ok
+}
+
+func encodeCursor(cluster string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cluster))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	cluster, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(cluster) == 0 {
+		return "", &InvalidCursorError{cursor: cursor}
+	}
+	return string(cluster), nil
+}
+
+//listConditions returns the conditions of the cluster configurations (joined with their statuses) matching the filter
+func (i *DefaultInventory) listConditions(filter *ListFilter, lastCluster string) ([]db.Condition, error) {
+	configEntity := &model.ClusterConfigurationEntity{}
+	latestStatusIDs, err := i.latestStatusIDs()
+	if err != nil {
+		return nil, err
+	}
+	conds := []db.Condition{
+		db.Eq(db.Field(configEntity, "Deleted"), false),
+		db.InQuery(statusField("ID"), latestStatusIDs),
+	}
+
+	//conditions for the latest status of each cluster
+	if len(filter.Statuses) > 0 {
+		var statuses []interface{}
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		conds = append(conds, db.In(statusField("Status"), statuses...))
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		conds = append(conds, db.Ge(statusField("Created"), filter.UpdatedAfter.UTC().Format(timestampFormat)))
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		conds = append(conds, db.Le(statusField("Created"), filter.UpdatedBefore.UTC().Format(timestampFormat)))
+	}
+
+	//conditions for the cluster configuration
+	if filter.KymaVersion != "" {
+		conds = append(conds, db.Eq(db.Field(configEntity, "KymaVersion"), filter.KymaVersion))
+	}
+	if filter.KymaProfile != "" {
+		conds = append(conds, db.Eq(db.Field(configEntity, "KymaProfile"), filter.KymaProfile))
+	}
+	if lastCluster != "" {
+		conds = append(conds, db.Gt(db.Field(configEntity, "Cluster"), lastCluster))
+	}
+
+	//metadata is stored as JSON string: match the JSON encoded key-value pairs
+	for _, md := range filter.metadata() {
+		pattern, err := metadataPattern(md.field, md.value)
+		if err != nil {
+			return nil, err
+		}
+		clusterQuery, err := db.NewQuery(i.Conn, &model.ClusterEntity{})
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, db.InQuery(db.Field(configEntity, "ClusterVersion"), clusterQuery.Select().
+			Columns("Version").
+			WhereCondition(db.Like("Metadata", pattern))))
+	}
+
+	return conds, nil
+}
+
+func metadataPattern(field, value string) (string, error) {
+	keyValue, err := metadataKeyValue(field, value)
+	if err != nil {
+		return "", err
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return fmt.Sprintf(`%%%s%%`, escaper.Replace(keyValue)), nil
+}
+
+//metadataKeyValue returns the JSON encoded key-value pair which is contained by the metadata of a matching cluster
+func metadataKeyValue(field, value string) (string, error) {
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`"%s":%s`, field, jsonValue), nil
+}
@@ -0,0 +1,742 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/audit"
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/keb"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/kyma-incubator/reconciler/pkg/repository"
+)
+
+//InMemoryInventory is a thread-safe inventory which keeps all entities in memory (e.g. for tests and the local mode).
+//It behaves like the DefaultInventory: updates create new versions of the entities, status updates are
+//compare-and-swap operations and deleted clusters are renamed and flagged until they get purged.
+type InMemoryInventory struct {
+	*inMemoryEntities
+	actor     string
+	auditConn db.Connection //without connection the audit entries are only exported to the audit sinks
+	metricsCollector
+}
+
+//inMemoryEntities is shared by all copies of an in-memory inventory (see WithActor)
+type inMemoryEntities struct {
+	clusters []*model.ClusterEntity //all slices are ordered by the version/ID of their entities
+	configs  []*model.ClusterConfigurationEntity
+	statuses []*model.ClusterStatusEntity
+	//last assigned versions and IDs (like database sequences, they are not reused)
+	clusterVersion int64
+	configVersion  int64
+	statusID       int64
+	mu             sync.Mutex
+}
+
+//NewInMemoryInventory creates an empty inventory (the collector is optional and can be nil)
+func NewInMemoryInventory(collector metricsCollector) *InMemoryInventory {
+	return &InMemoryInventory{
+		inMemoryEntities: &inMemoryEntities{},
+		metricsCollector: collector,
+	}
+}
+
+//WithAuditConnection returns a copy of the inventory which appends its audit entries to the audit log in the database
+func (i *InMemoryInventory) WithAuditConnection(conn db.Connection) *InMemoryInventory {
+	return &InMemoryInventory{
+		inMemoryEntities: i.inMemoryEntities,
+		actor:            i.actor,
+		auditConn:        conn,
+		metricsCollector: i.metricsCollector,
+	}
+}
+
+func (i *InMemoryInventory) WithActor(actor string) Inventory {
+	return &InMemoryInventory{
+		inMemoryEntities: i.inMemoryEntities,
+		actor:            actor,
+		auditConn:        i.auditConn,
+		metricsCollector: i.metricsCollector,
+	}
+}
+
+func (i *InMemoryInventory) audit(action model.AuditAction, entityType, target string, versionBefore, versionAfter int64, details string) error {
+	return audit.Record(i.auditConn, &model.AuditEntity{
+		Actor:         i.actor,
+		Action:        action,
+		EntityType:    entityType,
+		Target:        target,
+		VersionBefore: versionBefore,
+		VersionAfter:  versionAfter,
+		Details:       details,
+	})
+}
+
+func (i *InMemoryInventory) onClusterStateUpdate(state *State) error {
+	if i.metricsCollector == nil {
+		return nil
+	}
+	return i.metricsCollector.OnClusterStateUpdate(state)
+}
+
+func (i *InMemoryInventory) CreateOrUpdate(contractVersion int64, cluster *keb.Cluster) (*State, error) {
+	state, err := i.createOrUpdate(contractVersion, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.onClusterStateUpdate(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (i *InMemoryInventory) createOrUpdate(contractVersion int64, cluster *keb.Cluster) (*State, error) {
+	metadata, err := json.Marshal(cluster.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	runtime, err := json.Marshal(cluster.RuntimeInput)
+	if err != nil {
+		return nil, err
+	}
+	components, err := json.Marshal(cluster.KymaConfig.Components)
+	if err != nil {
+		return nil, err
+	}
+	administrators, err := json.Marshal(cluster.KymaConfig.Administrators)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var configVersionBefore int64
+	if latestStatus := i.latestClusterStatus(cluster.Cluster); latestStatus != nil {
+		configVersionBefore = latestStatus.ConfigVersion
+	}
+	now := time.Now().UTC()
+
+	//new entities are only stored after the write was audited successfully
+	var newCluster *model.ClusterEntity
+	var newConfig *model.ClusterConfigurationEntity
+	var newStatus *model.ClusterStatusEntity
+
+	clusterEntity := &model.ClusterEntity{
+		Cluster:    cluster.Cluster,
+		Runtime:    string(runtime),
+		Metadata:   string(metadata),
+		Kubeconfig: cluster.Kubeconfig,
+		Contract:   contractVersion,
+	}
+	if oldClusterEntity := i.latestCluster(cluster.Cluster); oldClusterEntity != nil && oldClusterEntity.Equal(clusterEntity) {
+		clusterEntity = oldClusterEntity
+	} else {
+		i.clusterVersion++
+		clusterEntity.Version = i.clusterVersion
+		clusterEntity.Created = now
+		newCluster = clusterEntity
+	}
+
+	configEntity := &model.ClusterConfigurationEntity{
+		Cluster:        clusterEntity.Cluster,
+		ClusterVersion: clusterEntity.Version,
+		KymaVersion:    cluster.KymaConfig.Version,
+		KymaProfile:    cluster.KymaConfig.Profile,
+		Components:     string(components),
+		Administrators: string(administrators),
+		Contract:       contractVersion,
+	}
+	if oldConfigEntity := i.latestConfig(clusterEntity.Version); oldConfigEntity != nil && oldConfigEntity.Equal(configEntity) {
+		configEntity = oldConfigEntity
+	} else {
+		i.configVersion++
+		configEntity.Version = i.configVersion
+		configEntity.Created = now
+		newConfig = configEntity
+	}
+
+	statusEntity := i.newStatus(configEntity, model.ClusterStatusReconcilePending, now)
+	if oldStatusEntity := i.latestStatus(configEntity.Version); oldStatusEntity != nil && oldStatusEntity.Equal(statusEntity) {
+		statusEntity = oldStatusEntity
+	} else {
+		newStatus = statusEntity
+	}
+
+	if configVersionBefore != configEntity.Version { //unchanged clusters aren't audited
+		action := model.AuditActionUpdate
+		if configVersionBefore == 0 {
+			action = model.AuditActionCreate
+		}
+		if err := i.audit(action, model.AuditEntityCluster, cluster.Cluster,
+			configVersionBefore, configEntity.Version, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if newCluster != nil {
+		i.clusters = append(i.clusters, newCluster)
+	}
+	if newConfig != nil {
+		i.configs = append(i.configs, newConfig)
+	}
+	if newStatus != nil {
+		i.statuses = append(i.statuses, newStatus)
+	}
+	return newState(clusterEntity, configEntity, statusEntity), nil
+}
+
+//newStatus returns a status entity with a new ID (the entity is not stored)
+func (i *InMemoryInventory) newStatus(configEntity *model.ClusterConfigurationEntity, status model.Status, created time.Time) *model.ClusterStatusEntity {
+	i.statusID++
+	return &model.ClusterStatusEntity{
+		ID:             i.statusID,
+		Cluster:        configEntity.Cluster,
+		ClusterVersion: configEntity.ClusterVersion,
+		ConfigVersion:  configEntity.Version,
+		Status:         status,
+		Created:        created,
+	}
+}
+
+func (i *InMemoryInventory) UpdateStatus(expectedStatusID int64, status model.Status) (*State, error) {
+	state, err := i.updateStatus(expectedStatusID, status)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.onClusterStateUpdate(state); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+func (i *InMemoryInventory) updateStatus(expectedStatusID int64, status model.Status) (*State, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var expectedStatus *model.ClusterStatusEntity
+	for _, statusEntity := range i.statuses {
+		if statusEntity.ID == expectedStatusID {
+			expectedStatus = statusEntity
+			break
+		}
+	}
+	if expectedStatus == nil {
+		return nil, repository.NewEntityNotFoundError(nil, &model.ClusterStatusEntity{}, map[string]interface{}{
+			"ID": expectedStatusID,
+		})
+	}
+	latestStatus := i.latestClusterStatus(expectedStatus.Cluster)
+	if latestStatus.ID != expectedStatusID {
+		return nil, &StatusConflictError{
+			Cluster:          expectedStatus.Cluster,
+			ExpectedStatusID: expectedStatusID,
+			LatestStatus:     copyStatus(latestStatus),
+		}
+	}
+	if err := model.ValidateStatusTransition(latestStatus.Status, status); err != nil {
+		return nil, err
+	}
+
+	state, err := i.get(expectedStatus.Cluster, expectedStatus.ConfigVersion)
+	if err != nil {
+		return nil, err
+	}
+	newStatus := i.newStatus(state.Configuration, status, time.Now().UTC())
+	if latestStatus.Equal(newStatus) {
+		return state, nil
+	}
+	if err := i.audit(model.AuditActionUpdate, model.AuditEntityStatus, expectedStatus.Cluster,
+		latestStatus.ID, newStatus.ID, fmt.Sprintf("%s -> %s", latestStatus.Status, newStatus.Status)); err != nil {
+		return nil, err
+	}
+	i.statuses = append(i.statuses, newStatus)
+	state.Status = copyStatus(newStatus)
+	return state, nil
+}
+
+func (i *InMemoryInventory) Delete(cluster string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var configVersionBefore int64
+	if latestStatus := i.latestClusterStatus(cluster); latestStatus != nil {
+		configVersionBefore = latestStatus.ConfigVersion
+	}
+	newClusterName := fmt.Sprintf("deleted_%d_%s", time.Now().Unix(), cluster)
+	if err := i.audit(model.AuditActionDelete, model.AuditEntityCluster, cluster, configVersionBefore, 0,
+		fmt.Sprintf("renamed to '%s'", newClusterName)); err != nil {
+		return err
+	}
+
+	//rename and flag all cluster entities and the referenced entities
+	//(statuses are renamed like the cascading foreign key does it in Postgres)
+	for _, clusterEntity := range i.clusters {
+		if clusterEntity.Cluster == cluster {
+			clusterEntity.Cluster = newClusterName
+			clusterEntity.Deleted = true
+		}
+	}
+	for _, configEntity := range i.configs {
+		if configEntity.Cluster == cluster {
+			configEntity.Cluster = newClusterName
+			configEntity.Deleted = true
+		}
+	}
+	for _, statusEntity := range i.statuses {
+		if statusEntity.Cluster == cluster {
+			statusEntity.Cluster = newClusterName
+		}
+	}
+	return nil
+}
+
+func (i *InMemoryInventory) Get(cluster string, configVersion int64) (*State, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.get(cluster, configVersion)
+}
+
+func (i *InMemoryInventory) get(cluster string, configVersion int64) (*State, error) {
+	var configEntity *model.ClusterConfigurationEntity
+	for _, entity := range i.configs {
+		if entity.Version == configVersion && entity.Cluster == cluster {
+			configEntity = entity
+			break
+		}
+	}
+	if configEntity == nil {
+		return nil, repository.NewEntityNotFoundError(nil, &model.ClusterConfigurationEntity{}, map[string]interface{}{
+			"Version": configVersion,
+			"Cluster": cluster,
+		})
+	}
+	statusEntity := i.latestStatus(configVersion)
+	if statusEntity == nil {
+		return nil, repository.NewEntityNotFoundError(nil, &model.ClusterStatusEntity{}, map[string]interface{}{
+			"ConfigVersion": configVersion,
+		})
+	}
+	var clusterEntity *model.ClusterEntity
+	for _, entity := range i.clusters {
+		if entity.Version == configEntity.ClusterVersion && !entity.Deleted {
+			clusterEntity = entity
+			break
+		}
+	}
+	if clusterEntity == nil {
+		return nil, repository.NewEntityNotFoundError(nil, &model.ClusterEntity{}, map[string]interface{}{
+			"Version": configEntity.ClusterVersion,
+			"Deleted": false,
+		})
+	}
+	return newState(clusterEntity, configEntity, statusEntity), nil
+}
+
+func (i *InMemoryInventory) GetLatest(cluster string) (*State, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	clusterEntity := i.latestCluster(cluster)
+	if clusterEntity == nil {
+		return nil, repository.NewEntityNotFoundError(nil, &model.ClusterEntity{}, map[string]interface{}{
+			"Cluster": cluster,
+			"Deleted": false,
+		})
+	}
+	configEntity := i.latestConfig(clusterEntity.Version)
+	if configEntity == nil {
+		return nil, repository.NewEntityNotFoundError(nil, &model.ClusterConfigurationEntity{}, map[string]interface{}{
+			"ClusterVersion": clusterEntity.Version,
+		})
+	}
+	statusEntity := i.latestStatus(configEntity.Version)
+	if statusEntity == nil {
+		return nil, repository.NewEntityNotFoundError(nil, &model.ClusterStatusEntity{}, map[string]interface{}{
+			"ConfigVersion": configEntity.Version,
+		})
+	}
+	return newState(clusterEntity, configEntity, statusEntity), nil
+}
+
+func (i *InMemoryInventory) latestCluster(cluster string) *model.ClusterEntity {
+	for idx := len(i.clusters) - 1; idx >= 0; idx-- {
+		if i.clusters[idx].Cluster == cluster && !i.clusters[idx].Deleted {
+			return i.clusters[idx]
+		}
+	}
+	return nil
+}
+
+func (i *InMemoryInventory) latestConfig(clusterVersion int64) *model.ClusterConfigurationEntity {
+	for idx := len(i.configs) - 1; idx >= 0; idx-- {
+		if i.configs[idx].ClusterVersion == clusterVersion {
+			return i.configs[idx]
+		}
+	}
+	return nil
+}
+
+func (i *InMemoryInventory) latestStatus(configVersion int64) *model.ClusterStatusEntity {
+	for idx := len(i.statuses) - 1; idx >= 0; idx-- {
+		if i.statuses[idx].ConfigVersion == configVersion {
+			return i.statuses[idx]
+		}
+	}
+	return nil
+}
+
+func (i *InMemoryInventory) latestClusterStatus(cluster string) *model.ClusterStatusEntity {
+	for idx := len(i.statuses) - 1; idx >= 0; idx-- {
+		if i.statuses[idx].Cluster == cluster {
+			return i.statuses[idx]
+		}
+	}
+	return nil
+}
+
+func (i *InMemoryInventory) ClustersToReconcile(reconcileInterval time.Duration) ([]*State, error) {
+	reconcileBefore := formatTimestamp(time.Now().Add(-reconcileInterval))
+	return i.filterClusters(func(_ *model.ClusterConfigurationEntity, status *model.ClusterStatusEntity) bool {
+		if reconcileInterval > 0 && status.Status == model.ClusterStatusReady && formatTimestamp(status.Created) <= reconcileBefore {
+			return true
+		}
+		return containsStatus(status.Status, model.ClusterStatusReconcilePending, model.ClusterStatusReconcileFailed)
+	})
+}
+
+func (i *InMemoryInventory) ClustersNotReady() ([]*State, error) {
+	return i.filterClusters(func(_ *model.ClusterConfigurationEntity, status *model.ClusterStatusEntity) bool {
+		return containsStatus(status.Status, model.ClusterStatusReconciling, model.ClusterStatusReconcileFailed, model.ClusterStatusError)
+	})
+}
+
+//filterClusters returns the states of all clusters (ordered by their name) whose configuration and latest status match
+func (i *InMemoryInventory) filterClusters(match func(*model.ClusterConfigurationEntity, *model.ClusterStatusEntity) bool) ([]*State, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	//latest status of each cluster
+	latestStatuses := make(map[string]*model.ClusterStatusEntity)
+	for _, statusEntity := range i.statuses {
+		latestStatuses[statusEntity.Cluster] = statusEntity
+	}
+
+	var configs []*model.ClusterConfigurationEntity
+	for _, configEntity := range i.configs {
+		if configEntity.Deleted {
+			continue
+		}
+		statusEntity, ok := latestStatuses[configEntity.Cluster]
+		if ok && statusEntity.ConfigVersion == configEntity.Version && match(configEntity, statusEntity) {
+			configs = append(configs, configEntity)
+		}
+	}
+	sort.Slice(configs, func(a, b int) bool {
+		return configs[a].Cluster < configs[b].Cluster
+	})
+
+	result := []*State{}
+	for _, configEntity := range configs {
+		state, err := i.get(configEntity.Cluster, configEntity.Version)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, state)
+	}
+	return result, nil
+}
+
+//List returns the latest state of all clusters matching the filter, ordered by the cluster name
+func (i *InMemoryInventory) List(filter *ListFilter, page *Page) (*ListResult, error) {
+	if filter == nil {
+		filter = &ListFilter{}
+	}
+	var lastCluster string
+	if page != nil && page.Cursor != "" {
+		var err error
+		if lastCluster, err = decodeCursor(page.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	var metadata []string
+	for _, md := range filter.metadata() {
+		keyValue, err := metadataKeyValue(md.field, md.value)
+		if err != nil {
+			return nil, err
+		}
+		metadata = append(metadata, keyValue)
+	}
+
+	states, err := i.filterClusters(func(config *model.ClusterConfigurationEntity, status *model.ClusterStatusEntity) bool {
+		if lastCluster != "" && config.Cluster <= lastCluster {
+			return false
+		}
+		if len(filter.Statuses) > 0 && !containsStatus(status.Status, filter.Statuses...) {
+			return false
+		}
+		if !filter.UpdatedAfter.IsZero() && formatTimestamp(status.Created) < formatTimestamp(filter.UpdatedAfter) {
+			return false
+		}
+		if !filter.UpdatedBefore.IsZero() && formatTimestamp(status.Created) > formatTimestamp(filter.UpdatedBefore) {
+			return false
+		}
+		if filter.KymaVersion != "" && config.KymaVersion != filter.KymaVersion {
+			return false
+		}
+		if filter.KymaProfile != "" && config.KymaProfile != filter.KymaProfile {
+			return false
+		}
+		return len(metadata) == 0 || i.clusterMetadataContains(config.ClusterVersion, metadata)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListResult{
+		States: states,
+	}
+	if pageSize := page.size(); len(states) > pageSize {
+		result.States = states[:pageSize]
+		result.NextCursor = encodeCursor(result.States[pageSize-1].Cluster.Cluster)
+	}
+	return result, nil
+}
+
+//clusterMetadataContains checks whether the metadata of the cluster entity contains all key-value pairs
+func (i *InMemoryInventory) clusterMetadataContains(clusterVersion int64, keyValues []string) bool {
+	for _, clusterEntity := range i.clusters {
+		if clusterEntity.Version != clusterVersion {
+			continue
+		}
+		for _, keyValue := range keyValues {
+			if !strings.Contains(clusterEntity.Metadata, keyValue) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (i *InMemoryInventory) StatusChanges(cluster string, offset time.Duration) ([]*StatusChange, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	createdAfter := formatTimestamp(time.Now().Add(-offset))
+	var statusChanges []*StatusChange
+	var createdPrevStatus time.Time
+	for idx := len(i.statuses) - 1; idx >= 0; idx-- { //latest status first
+		statusEntity := i.statuses[idx]
+		if statusEntity.Cluster != cluster || formatTimestamp(statusEntity.Created) < createdAfter {
+			continue
+		}
+		var duration time.Duration
+		if createdPrevStatus.IsZero() {
+			duration = time.Since(statusEntity.Created)
+		} else {
+			duration = createdPrevStatus.Sub(statusEntity.Created)
+		}
+		statusChanges = append(statusChanges, &StatusChange{
+			Status:   copyStatus(statusEntity),
+			Duration: duration,
+		})
+		createdPrevStatus = statusEntity.Created
+	}
+
+	if len(statusChanges) == 0 {
+		//invalid state: there cannot be a cluster without any state
+		return nil, repository.NewEntityNotFoundError(
+			fmt.Errorf("no status found for cluster '%s'", cluster),
+			&model.ClusterStatusEntity{},
+			map[string]interface{}{
+				"Cluster": cluster,
+			})
+	}
+	return statusChanges, nil
+}
+
+//Purge removes the entities which are no longer required according to the retention policy.
+//The in-memory inventory doesn't store operations: their amount in the result is always 0.
+func (i *InMemoryInventory) Purge(policy *RetentionPolicy) (*PurgeResult, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	//work on copies of the entity lists: they replace the stored lists after the purge was audited
+	purged := &inMemoryEntities{
+		clusters: append([]*model.ClusterEntity{}, i.clusters...),
+		configs:  append([]*model.ClusterConfigurationEntity{}, i.configs...),
+		statuses: append([]*model.ClusterStatusEntity{}, i.statuses...),
+	}
+	result := &PurgeResult{}
+	if policy.DeletedGracePeriod > 0 {
+		result.add(purged.purgeDeletedClusters(time.Now().Add(-policy.DeletedGracePeriod)))
+	}
+	if policy.KeepConfigs > 0 {
+		result.add(purged.purgeConfigs(policy.KeepConfigs))
+	}
+	if policy.StatusMaxAge > 0 {
+		result.add(purged.purgeStatuses(time.Now().Add(-policy.StatusMaxAge)))
+	}
+	if *result == (PurgeResult{}) { //nothing was removed
+		return result, nil
+	}
+	if err := i.audit(model.AuditActionPurge, model.AuditEntityInventory, "clusters", 0, 0, result.String()); err != nil {
+		return nil, err
+	}
+	i.clusters, i.configs, i.statuses = purged.clusters, purged.configs, purged.statuses
+	return result, nil
+}
+
+//purgeDeletedClusters removes all entities of clusters which were deleted before the given time
+func (e *inMemoryEntities) purgeDeletedClusters(deletedBefore time.Time) *PurgeResult {
+	clusters := make(map[string]bool)
+	for _, clusterEntity := range e.clusters {
+		matches := deletedClusterRegex.FindStringSubmatch(clusterEntity.Cluster)
+		if !clusterEntity.Deleted || len(matches) < 2 {
+			continue
+		}
+		deletedAt, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil || time.Unix(deletedAt, 0).After(deletedBefore) {
+			continue
+		}
+		clusters[clusterEntity.Cluster] = true
+	}
+
+	result := e.purge(func(configEntity *model.ClusterConfigurationEntity) bool {
+		return clusters[configEntity.Cluster]
+	})
+	var keptClusters []*model.ClusterEntity
+	for _, clusterEntity := range e.clusters {
+		if clusters[clusterEntity.Cluster] {
+			result.Clusters++
+		} else {
+			keptClusters = append(keptClusters, clusterEntity)
+		}
+	}
+	e.clusters = keptClusters
+	return result
+}
+
+//purgeConfigs removes all configurations except the latest ones of each cluster
+//and all cluster entities which are no longer referenced
+func (e *inMemoryEntities) purgeConfigs(keep int) *PurgeResult {
+	newerConfigs := make(map[int64]int) //amount of newer configurations per configuration version
+	for idx, configEntity := range e.configs {
+		for _, otherConfigEntity := range e.configs[idx+1:] {
+			if otherConfigEntity.Cluster == configEntity.Cluster && otherConfigEntity.Version > configEntity.Version {
+				newerConfigs[configEntity.Version]++
+			}
+		}
+	}
+	result := e.purge(func(configEntity *model.ClusterConfigurationEntity) bool {
+		return newerConfigs[configEntity.Version] >= keep
+	})
+
+	//remove cluster entities which are neither referenced by a configuration nor the latest version of a cluster
+	referenced := make(map[int64]bool)
+	for _, configEntity := range e.configs {
+		referenced[configEntity.ClusterVersion] = true
+	}
+	latestVersions := make(map[string]int64)
+	for _, clusterEntity := range e.clusters {
+		if clusterEntity.Version > latestVersions[clusterEntity.Cluster] {
+			latestVersions[clusterEntity.Cluster] = clusterEntity.Version
+		}
+	}
+	var keptClusters []*model.ClusterEntity
+	for _, clusterEntity := range e.clusters {
+		if referenced[clusterEntity.Version] || latestVersions[clusterEntity.Cluster] == clusterEntity.Version {
+			keptClusters = append(keptClusters, clusterEntity)
+		} else {
+			result.Clusters++
+		}
+	}
+	e.clusters = keptClusters
+	return result
+}
+
+//purgeStatuses removes statuses created before the given time which were superseded by a newer status
+func (e *inMemoryEntities) purgeStatuses(createdBefore time.Time) *PurgeResult {
+	latestIDs := make(map[int64]int64) //latest status ID per configuration version
+	for _, statusEntity := range e.statuses {
+		latestIDs[statusEntity.ConfigVersion] = statusEntity.ID
+	}
+	result := &PurgeResult{}
+	var keptStatuses []*model.ClusterStatusEntity
+	for _, statusEntity := range e.statuses {
+		if formatTimestamp(statusEntity.Created) < formatTimestamp(createdBefore) && latestIDs[statusEntity.ConfigVersion] != statusEntity.ID {
+			result.Statuses++
+		} else {
+			keptStatuses = append(keptStatuses, statusEntity)
+		}
+	}
+	e.statuses = keptStatuses
+	return result
+}
+
+//purge removes the matching configurations including their statuses
+func (e *inMemoryEntities) purge(match func(*model.ClusterConfigurationEntity) bool) *PurgeResult {
+	result := &PurgeResult{}
+	configVersions := make(map[int64]bool)
+	var keptConfigs []*model.ClusterConfigurationEntity
+	for _, configEntity := range e.configs {
+		if match(configEntity) {
+			configVersions[configEntity.Version] = true
+			result.Configurations++
+		} else {
+			keptConfigs = append(keptConfigs, configEntity)
+		}
+	}
+	e.configs = keptConfigs
+
+	var keptStatuses []*model.ClusterStatusEntity
+	for _, statusEntity := range e.statuses {
+		if configVersions[statusEntity.ConfigVersion] {
+			result.Statuses++
+		} else {
+			keptStatuses = append(keptStatuses, statusEntity)
+		}
+	}
+	e.statuses = keptStatuses
+	return result
+}
+
+//newState returns a state containing copies of the entities (stored entities must not be changed by callers)
+func newState(clusterEntity *model.ClusterEntity, configEntity *model.ClusterConfigurationEntity, statusEntity *model.ClusterStatusEntity) *State {
+	clusterCopy := *clusterEntity
+	configCopy := *configEntity
+	return &State{
+		Cluster:       &clusterCopy,
+		Configuration: &configCopy,
+		Status:        copyStatus(statusEntity),
+	}
+}
+
+func copyStatus(statusEntity *model.ClusterStatusEntity) *model.ClusterStatusEntity {
+	statusCopy := *statusEntity
+	return &statusCopy
+}
+
+func containsStatus(status model.Status, statuses ...model.Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+//formatTimestamp formats the time like timestamps are compared in the database (with a precision of seconds)
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(timestampFormat)
+}
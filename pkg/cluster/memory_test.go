@@ -0,0 +1,63 @@
+package cluster
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryInventory(t *testing.T) {
+	t.Run("Concurrent status updates", func(t *testing.T) {
+		inventory := NewInMemoryInventory(nil)
+		state, err := inventory.CreateOrUpdate(1, newCluster(t, 1, 1))
+		require.NoError(t, err)
+
+		//all updates expect the same latest status: only one of them can succeed
+		var wg sync.WaitGroup
+		statuses := []model.Status{model.ClusterStatusReconciling, model.ClusterStatusError}
+		errs := make(chan error, len(statuses)*5)
+		for i := 0; i < 5; i++ {
+			for _, status := range statuses {
+				wg.Add(1)
+				go func(status model.Status) {
+					defer wg.Done()
+					_, err := inventory.UpdateStatus(state.Status.ID, status)
+					errs <- err
+				}(status)
+			}
+		}
+		wg.Wait()
+		close(errs)
+
+		var updates int
+		for err := range errs {
+			if err == nil {
+				updates++
+				continue
+			}
+			require.True(t, IsStatusConflictError(err))
+		}
+		require.Equal(t, 1, updates)
+
+		changes, err := inventory.StatusChanges(state.Cluster.Cluster, time.Hour)
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+	})
+
+	t.Run("Returned states are copies", func(t *testing.T) {
+		inventory := NewInMemoryInventory(nil)
+		cluster := newCluster(t, 1, 1)
+		state, err := inventory.CreateOrUpdate(1, cluster)
+		require.NoError(t, err)
+
+		state.Cluster.Deleted = true
+		state.Status.Status = model.ClusterStatusError
+		latestState, err := inventory.GetLatest(cluster.Cluster)
+		require.NoError(t, err)
+		require.False(t, latestState.Cluster.Deleted)
+		require.Equal(t, model.ClusterStatusReconcilePending, latestState.Status.Status)
+	})
+}
@@ -21,13 +21,15 @@ type MockInventory struct {
 	DeleteResult              error
 	UpdateStatusResult        *State
 	ChangesResult             []*StatusChange
+	ListResult                *ListResult
+	PurgeResult               *PurgeResult
 }
 
 func (i *MockInventory) CreateOrUpdate(contractVersion int64, cluster *keb.Cluster) (*State, error) {
 	return i.CreateOrUpdateResult, nil
 }
 
-func (i *MockInventory) UpdateStatus(State *State, status model.Status) (*State, error) {
+func (i *MockInventory) UpdateStatus(expectedStatusID int64, status model.Status) (*State, error) {
 	return i.UpdateStatusResult, nil
 }
 
@@ -55,6 +57,18 @@ func (i *MockInventory) StatusChanges(cluster string, offset time.Duration) ([]*
 	return i.ChangesResult, nil
 }
 
+func (i *MockInventory) List(filter *ListFilter, page *Page) (*ListResult, error) {
+	return i.ListResult, nil
+}
+
+func (i *MockInventory) Purge(policy *RetentionPolicy) (*PurgeResult, error) {
+	return i.PurgeResult, nil
+}
+
+func (i *MockInventory) WithActor(actor string) Inventory {
+	return i
+}
+
 type MockKubeconfigProvider struct {
 	KubeconfigResult string
 }
@@ -0,0 +1,292 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+)
+
+//deletedClusterRegex matches the name a cluster gets when it is deleted (see DefaultInventory.Delete)
+var deletedClusterRegex = regexp.MustCompile(`^deleted_(\d+)_`)
+
+//RetentionPolicy defines which historical entities are removed from the inventory.
+//Setting a field to zero disables the corresponding cleanup.
+type RetentionPolicy struct {
+	StatusMaxAge       time.Duration //superseded statuses older than this age are removed
+	KeepConfigs        int           //number of configurations kept per cluster
+	DeletedGracePeriod time.Duration //deleted clusters are purged after this period
+}
+
+func (p *RetentionPolicy) Validate() error {
+	if p.StatusMaxAge < 0 {
+		return errors.New("max age of statuses cannot be < 0")
+	}
+	if p.KeepConfigs < 0 {
+		return errors.New("amount of kept configurations cannot be < 0")
+	}
+	if p.DeletedGracePeriod < 0 {
+		return errors.New("grace period of deleted clusters cannot be < 0")
+	}
+	return nil
+}
+
+//PurgeResult contains the amount of removed rows per entity
+type PurgeResult struct {
+	Clusters       int64
+	Configurations int64
+	Statuses       int64
+	Operations     int64
+}
+
+func (r *PurgeResult) add(other *PurgeResult) {
+	r.Clusters += other.Clusters
+	r.Configurations += other.Configurations
+	r.Statuses += other.Statuses
+	r.Operations += other.Operations
+}
+
+func (r *PurgeResult) String() string {
+	return fmt.Sprintf("PurgeResult [Clusters=%d,Configurations=%d,Statuses=%d,Operations=%d]",
+		r.Clusters, r.Configurations, r.Statuses, r.Operations)
+}
+
+//Purge removes the entities which are no longer required according to the retention policy.
+//The latest configuration and the latest status of each configuration are never removed.
+func (i *DefaultInventory) Purge(policy *RetentionPolicy) (*PurgeResult, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	dbOps := func(tx *db.Tx) (interface{}, error) {
+		txInventory := i.withTx(tx)
+		result := &PurgeResult{}
+		if policy.DeletedGracePeriod > 0 {
+			purged, err := txInventory.purgeDeletedClusters(time.Now().Add(-policy.DeletedGracePeriod))
+			if err != nil {
+				return nil, err
+			}
+			result.add(purged)
+		}
+		if policy.KeepConfigs > 0 {
+			purged, err := txInventory.purgeConfigs(policy.KeepConfigs)
+			if err != nil {
+				return nil, err
+			}
+			result.add(purged)
+		}
+		if policy.StatusMaxAge > 0 {
+			purged, err := txInventory.purgeStatuses(time.Now().Add(-policy.StatusMaxAge))
+			if err != nil {
+				return nil, err
+			}
+			result.add(purged)
+		}
+		if *result == (PurgeResult{}) { //nothing was removed
+			return result, nil
+		}
+		return result, txInventory.audit(model.AuditActionPurge, model.AuditEntityInventory, "clusters", 0, 0, result.String())
+	}
+	result, err := db.TransactionResult(i.Conn, dbOps, i.Logger)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*PurgeResult), nil
+}
+
+//purgeDeletedClusters removes all entities of clusters which were deleted before the given time
+func (i *DefaultInventory) purgeDeletedClusters(deletedBefore time.Time) (*PurgeResult, error) {
+	clusterEntity := &model.ClusterEntity{}
+	q, err := db.NewQuery(i.Conn, clusterEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	//the deletion time is encoded in the name of a deleted cluster
+	rows, err := q.Select().
+		Columns("Cluster").
+		WhereCondition(db.Like("Cluster", `deleted\_%`), db.Eq("Deleted", true)).
+		GroupBy([]string{"Cluster"}).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var clusters []string
+	for rows.Next() {
+		var cluster string
+		if err := rows.Scan(&cluster); err != nil {
+			return nil, err
+		}
+		matches := deletedClusterRegex.FindStringSubmatch(cluster)
+		if len(matches) < 2 {
+			continue
+		}
+		deletedAt, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil || time.Unix(deletedAt, 0).After(deletedBefore) {
+			continue
+		}
+		clusters = append(clusters, cluster)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := rows.Close(); err != nil { //release the connection before further statements are executed in the transaction
+		return nil, err
+	}
+
+	result := &PurgeResult{}
+	for _, cluster := range clusters {
+		configQuery, err := db.NewQuery(i.Conn, &model.ClusterConfigurationEntity{})
+		if err != nil {
+			return nil, err
+		}
+		purged, err := i.purge(configQuery.Select().
+			Columns("Version").
+			Where(map[string]interface{}{"Cluster": cluster}))
+		if err != nil {
+			return nil, err
+		}
+		result.add(purged)
+
+		//remove the cluster entities
+		deleted, err := q.Delete().
+			Where(map[string]interface{}{"Cluster": cluster}).
+			Exec()
+		if err != nil {
+			return nil, err
+		}
+		result.Clusters += deleted
+		i.Logger.Debugf("Purged deleted cluster '%s'", cluster)
+	}
+	return result, nil
+}
+
+//purgeConfigs removes all configurations except the latest ones of each cluster
+//and all cluster entities which are no longer referenced
+func (i *DefaultInventory) purgeConfigs(keep int) (*PurgeResult, error) {
+	configQuery, err := db.NewQuery(i.Conn, &model.ClusterConfigurationEntity{})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := configQuery.Select().
+		Columns("Cluster").
+		GroupBy([]string{"Cluster"}).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var clusters []string
+	for rows.Next() {
+		var cluster string
+		if err := rows.Scan(&cluster); err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, cluster)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := rows.Close(); err != nil { //release the connection before further statements are executed in the transaction
+		return nil, err
+	}
+
+	//remove the configurations of each cluster which have at least 'keep' newer configurations
+	result := &PurgeResult{}
+	for _, cluster := range clusters {
+		purged, err := i.purge(configQuery.Select().
+			Columns("Version").
+			Where(map[string]interface{}{"Cluster": cluster}).
+			OrderBy(map[string]string{"Version": "DESC"}).
+			Offset(keep))
+		if err != nil {
+			return nil, err
+		}
+		result.add(purged)
+	}
+
+	//remove cluster entities which are neither referenced by a configuration nor the latest version of a cluster
+	clusterQuery, err := db.NewQuery(i.Conn, &model.ClusterEntity{})
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := clusterQuery.Delete().
+		WhereCondition(
+			db.Not(db.InQuery("Version", configQuery.Select().Columns("ClusterVersion"))),
+			db.Not(db.InQuery("Version", clusterQuery.Select().
+				Aggregate(db.AggregateMax, "Version").
+				GroupBy([]string{"Cluster"}))),
+		).
+		Exec()
+	if err != nil {
+		return nil, err
+	}
+	result.Clusters += deleted
+	return result, nil
+}
+
+//purgeStatuses removes statuses created before the given time which were superseded by a newer status
+func (i *DefaultInventory) purgeStatuses(createdBefore time.Time) (*PurgeResult, error) {
+	q, err := db.NewQuery(i.Conn, &model.ClusterStatusEntity{})
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := q.Delete().
+		WhereCondition(
+			db.Lt("Created", createdBefore.UTC().Format(timestampFormat)),
+			db.Not(db.InQuery("ID", q.Select().
+				Aggregate(db.AggregateMax, "ID").
+				GroupBy([]string{"ConfigVersion"}))),
+		).
+		Exec()
+	if err != nil {
+		return nil, err
+	}
+	return &PurgeResult{Statuses: deleted}, nil
+}
+
+//purge removes the configurations returned by the sub-query including their statuses and operations
+//(dependent rows are deleted explicitly as SQLite doesn't enforce the cascading foreign keys)
+func (i *DefaultInventory) purge(configVersions *db.Select) (*PurgeResult, error) {
+	result := &PurgeResult{}
+	deletes := []struct {
+		entity  db.DatabaseEntity
+		field   string
+		counter *int64
+	}{
+		{
+			entity:  &model.OperationEntity{},
+			field:   "ConfigVersion",
+			counter: &result.Operations,
+		},
+		{
+			entity:  &model.ClusterStatusEntity{},
+			field:   "ConfigVersion",
+			counter: &result.Statuses,
+		},
+		{
+			entity:  &model.ClusterConfigurationEntity{},
+			field:   "Version",
+			counter: &result.Configurations,
+		},
+	}
+	for _, del := range deletes {
+		q, err := db.NewQuery(i.Conn, del.entity)
+		if err != nil {
+			return nil, err
+		}
+		deleted, err := q.Delete().
+			WhereCondition(db.InQuery(del.field, configVersions)).
+			Exec()
+		if err != nil {
+			return nil, err
+		}
+		*del.counter += deleted
+	}
+	return result, nil
+}
@@ -0,0 +1,188 @@
+package cluster
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetention(t *testing.T) {
+	t.Run("Validate policy", func(t *testing.T) {
+		require.NoError(t, (&RetentionPolicy{}).Validate())
+		require.Error(t, (&RetentionPolicy{StatusMaxAge: -1 * time.Second}).Validate())
+		require.Error(t, (&RetentionPolicy{KeepConfigs: -1}).Validate())
+		require.Error(t, (&RetentionPolicy{DeletedGracePeriod: -1 * time.Second}).Validate())
+	})
+
+	runConformanceTest(t, inventoryBackends, testRetention)
+}
+
+func testRetention(t *testing.T, newInventory func(t *testing.T) Inventory) {
+	t.Run("Keep latest configurations", func(t *testing.T) {
+		inventory := newInventory(t)
+		cluster := newCluster(t, 1, 1).Cluster
+		defer func() {
+			require.NoError(t, inventory.Delete(cluster))
+		}()
+
+		//create 4 versions of the cluster (each version has two statuses)
+		for version := int64(1); version <= 4; version++ {
+			state, err := inventory.CreateOrUpdate(1, newCluster(t, 1, version))
+			require.NoError(t, err)
+			_, err = inventory.UpdateStatus(state.Status.ID, model.ClusterStatusReconciling)
+			require.NoError(t, err)
+		}
+
+		result, err := inventory.Purge(&RetentionPolicy{KeepConfigs: 2})
+		require.NoError(t, err)
+		require.Equal(t, &PurgeResult{Clusters: 2, Configurations: 2, Statuses: 4}, result)
+		require.Equal(t, 2, countEntities(t, inventory, &model.ClusterEntity{}, cluster, false))
+		require.Equal(t, 2, countEntities(t, inventory, &model.ClusterConfigurationEntity{}, cluster, false))
+		require.Equal(t, 4, countEntities(t, inventory, &model.ClusterStatusEntity{}, cluster, false))
+
+		//latest state is untouched
+		state, err := inventory.GetLatest(cluster)
+		require.NoError(t, err)
+		require.Equal(t, newCluster(t, 1, 4).KymaConfig.Version, state.Configuration.KymaVersion)
+		require.Equal(t, model.ClusterStatusReconciling, state.Status.Status)
+
+		//nothing left to purge
+		result, err = inventory.Purge(&RetentionPolicy{KeepConfigs: 2})
+		require.NoError(t, err)
+		require.Equal(t, &PurgeResult{}, result)
+	})
+
+	t.Run("Remove superseded statuses", func(t *testing.T) {
+		inventory := newInventory(t)
+		cluster := newCluster(t, 1, 1)
+		state, err := inventory.CreateOrUpdate(1, cluster)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, inventory.Delete(cluster.Cluster))
+		}()
+		for _, status := range []model.Status{model.ClusterStatusReconciling, model.ClusterStatusError, model.ClusterStatusReconcilePending} {
+			state, err = inventory.UpdateStatus(state.Status.ID, status)
+			require.NoError(t, err)
+		}
+
+		//statuses are too young to be purged
+		result, err := inventory.Purge(&RetentionPolicy{StatusMaxAge: 24 * time.Hour})
+		require.NoError(t, err)
+		require.Equal(t, &PurgeResult{}, result)
+
+		//age all statuses
+		ageStatuses(t, inventory, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+
+		result, err = inventory.Purge(&RetentionPolicy{StatusMaxAge: 24 * time.Hour})
+		require.NoError(t, err)
+		require.Equal(t, &PurgeResult{Statuses: 3}, result)
+		require.Equal(t, 1, countEntities(t, inventory, &model.ClusterStatusEntity{}, cluster.Cluster, false))
+
+		state, err = inventory.GetLatest(cluster.Cluster)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStatusReconcilePending, state.Status.Status)
+	})
+
+	t.Run("Purge deleted clusters", func(t *testing.T) {
+		inventory := newInventory(t)
+		deletedCluster := newCluster(t, 1, 1)
+		_, err := inventory.CreateOrUpdate(1, deletedCluster)
+		require.NoError(t, err)
+		require.NoError(t, inventory.Delete(deletedCluster.Cluster))
+
+		activeCluster := newCluster(t, 2, 1)
+		_, err = inventory.CreateOrUpdate(1, activeCluster)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, inventory.Delete(activeCluster.Cluster))
+		}()
+
+		//grace period not expired
+		result, err := inventory.Purge(&RetentionPolicy{DeletedGracePeriod: time.Hour})
+		require.NoError(t, err)
+		require.Equal(t, &PurgeResult{}, result)
+
+		result, err = inventory.Purge(&RetentionPolicy{DeletedGracePeriod: time.Nanosecond})
+		require.NoError(t, err)
+		require.Equal(t, &PurgeResult{Clusters: 1, Configurations: 1, Statuses: 1}, result)
+		require.Equal(t, 0, countEntities(t, inventory, &model.ClusterEntity{}, deletedCluster.Cluster, true))
+		require.Equal(t, 0, countEntities(t, inventory, &model.ClusterConfigurationEntity{}, deletedCluster.Cluster, true))
+		require.Equal(t, 0, countEntities(t, inventory, &model.ClusterStatusEntity{}, deletedCluster.Cluster, true))
+
+		//active cluster is untouched
+		_, err = inventory.GetLatest(activeCluster.Cluster)
+		require.NoError(t, err)
+	})
+}
+
+//countEntities counts the entities of a cluster (deleted clusters are matched by their original name)
+func countEntities(t *testing.T, inventory Inventory, entity db.DatabaseEntity, cluster string, deleted bool) int {
+	switch inv := inventory.(type) {
+	case *DefaultInventory:
+		clusterPattern := cluster
+		if deleted {
+			clusterPattern = fmt.Sprintf("deleted\\_%%\\_%s", cluster)
+		}
+		q, err := db.NewQuery(inv.Conn, entity)
+		require.NoError(t, err)
+		count, err := q.Select().WhereCondition(db.Like("Cluster", clusterPattern)).Count()
+		require.NoError(t, err)
+		return int(count)
+	case *InMemoryInventory:
+		clusterRegex := regexp.MustCompile(fmt.Sprintf("^%s$", regexp.QuoteMeta(cluster)))
+		if deleted {
+			clusterRegex = regexp.MustCompile(fmt.Sprintf(`^deleted_\d+_%s$`, regexp.QuoteMeta(cluster)))
+		}
+		inv.mu.Lock()
+		defer inv.mu.Unlock()
+		var clusters []string
+		switch entity.(type) {
+		case *model.ClusterEntity:
+			for _, clusterEntity := range inv.clusters {
+				clusters = append(clusters, clusterEntity.Cluster)
+			}
+		case *model.ClusterConfigurationEntity:
+			for _, configEntity := range inv.configs {
+				clusters = append(clusters, configEntity.Cluster)
+			}
+		case *model.ClusterStatusEntity:
+			for _, statusEntity := range inv.statuses {
+				clusters = append(clusters, statusEntity.Cluster)
+			}
+		default:
+			t.Fatalf("Entity type %T is not supported", entity)
+		}
+		var count int
+		for _, name := range clusters {
+			if clusterRegex.MatchString(name) {
+				count++
+			}
+		}
+		return count
+	}
+	t.Fatalf("Inventory type %T is not supported", inventory)
+	return 0
+}
+
+//ageStatuses sets the creation time of all statuses
+func ageStatuses(t *testing.T, inventory Inventory, created time.Time) {
+	switch inv := inventory.(type) {
+	case *DefaultInventory:
+		_, err := inv.Conn.Exec(fmt.Sprintf("UPDATE %s SET created=$1", (&model.ClusterStatusEntity{}).Table()),
+			created.UTC().Format(timestampFormat))
+		require.NoError(t, err)
+	case *InMemoryInventory:
+		inv.mu.Lock()
+		defer inv.mu.Unlock()
+		for _, statusEntity := range inv.statuses {
+			statusEntity.Created = created
+		}
+	default:
+		t.Fatalf("Inventory type %T is not supported", inventory)
+	}
+}
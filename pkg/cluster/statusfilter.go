@@ -1,8 +1,6 @@
 package cluster
 
 import (
-	"fmt"
-	"strings"
 	"time"
 
 	"github.com/kyma-incubator/reconciler/pkg/db"
@@ -10,50 +8,33 @@ import (
 )
 
 type statusSQLFilter interface {
-	Filter(dbType db.Type, colHdr *db.ColumnHandler) (string, error)
+	//Filter returns a condition on the fields of the cluster status entity
+	Filter() db.Condition
 }
 
 type statusFilter struct {
 	allowedStatuses []model.Status
 }
 
-func (sf *statusFilter) Filter(dbType db.Type, statusColHdr *db.ColumnHandler) (string, error) {
-	statusColName, err := statusColHdr.ColumnName("Status")
-	if err != nil {
-		return "", err
-	}
-	return fmt.Sprintf("%s IN ('%s')", statusColName, strings.Join(sf.stausesToStrings(), "','")), nil
-}
-
-func (sf *statusFilter) stausesToStrings() []string {
-	result := []string{}
+func (sf *statusFilter) Filter() db.Condition {
+	var statuses []interface{}
 	for _, status := range sf.allowedStatuses {
-		result = append(result, string(status))
+		statuses = append(statuses, string(status))
 	}
-	return result
+	return db.In(statusField("Status"), statuses...)
 }
 
 type reconcileIntervalFilter struct {
 	reconcileInterval time.Duration
 }
 
-func (rif *reconcileIntervalFilter) Filter(dbType db.Type, statusColHdr *db.ColumnHandler) (string, error) {
-	statusColName, err := statusColHdr.ColumnName("Status")
-	if err != nil {
-		return "", err
-	}
-	createdColName, err := statusColHdr.ColumnName("Created")
-	if err != nil {
-		return "", err
-	}
-	switch dbType {
-	case db.Postgres:
-		return fmt.Sprintf(`%s = '%s' AND %s <= NOW() - INTERVAL '%.0f SECOND'`,
-			statusColName, model.ClusterStatusReady, createdColName, rif.reconcileInterval.Seconds()), nil
-	case db.SQLite:
-		return fmt.Sprintf(`%s = '%s' AND %s <= DATETIME('now', '-%.0f SECONDS')`,
-			statusColName, model.ClusterStatusReady, createdColName, rif.reconcileInterval.Seconds()), nil
-	default:
-		return "", fmt.Errorf("database type '%s' is not supported by this filter", dbType)
-	}
+func (rif *reconcileIntervalFilter) Filter() db.Condition {
+	return db.And(
+		db.Eq(statusField("Status"), string(model.ClusterStatusReady)),
+		db.Le(statusField("Created"), time.Now().Add(-rif.reconcileInterval).UTC().Format(timestampFormat)))
+}
+
+//statusField qualifies the field by the table of the cluster status entity (required if the query joins other entities)
+func statusField(field string) string {
+	return db.Field(&model.ClusterStatusEntity{}, field)
 }
@@ -49,6 +49,7 @@ type column struct {
 type ColumnHandler struct {
 	entity      DatabaseEntity
 	encryptor   *Encryptor
+	dbType      Type
 	columns     []*column
 	columnNames map[string]string //cache for column names (to increase lookup speed)
 }
@@ -60,6 +61,7 @@ func NewColumnHandler(entity DatabaseEntity, conn Connection) (*ColumnHandler, e
 		entity:      entity,
 		columnNames: make(map[string]string, len(fields)),
 		encryptor:   conn.Encryptor(),
+		dbType:      conn.Type(),
 	}
 
 	//get marshalled values of entity fields
@@ -140,6 +142,11 @@ func (ch *ColumnHandler) ColumnName(field string) (string, error) {
 
 //ColumnNamesCsv returns the CSV string of the column names
 func (ch *ColumnHandler) ColumnNamesCsv(onlyWriteable bool) string {
+	return ch.columnNamesCsv(onlyWriteable, "")
+}
+
+//columnNamesCsv returns the CSV string of the column names prefixed by the given qualifier (e.g. table name)
+func (ch *ColumnHandler) columnNamesCsv(onlyWriteable bool, qualifier string) string {
 	var buffer bytes.Buffer
 	for _, col := range ch.columns {
 		if onlyWriteable && col.readOnly {
@@ -148,6 +155,7 @@ func (ch *ColumnHandler) ColumnNamesCsv(onlyWriteable bool) string {
 		if buffer.Len() > 0 {
 			buffer.WriteString(", ")
 		}
+		buffer.WriteString(qualifier)
 		buffer.WriteString(col.name)
 	}
 	return buffer.String()
@@ -185,7 +193,7 @@ func (ch *ColumnHandler) columnValuesCsvRenderer(onlyWriteable, placeholder bool
 		}
 		if placeholder {
 			placeholderIdx++
-			buffer.WriteString(fmt.Sprintf("$%d", placeholderIdx))
+			buffer.WriteString(Placeholder(ch.dbType, placeholderIdx))
 		} else {
 			value, err := ch.serializeValue(col)
 			if err != nil {
@@ -219,7 +227,7 @@ func (ch *ColumnHandler) columnEntriesCsvRenderer(onlyWriteable, placeholder boo
 		}
 		if placeholder {
 			placeholderIdx++
-			buffer.WriteString(fmt.Sprintf("%s=$%d", col.name, placeholderIdx))
+			buffer.WriteString(fmt.Sprintf("%s=%s", col.name, Placeholder(ch.dbType, placeholderIdx)))
 		} else {
 			value, err := ch.serializeValue(col)
 			if err != nil {
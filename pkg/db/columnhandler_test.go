@@ -1,6 +1,7 @@
 package db
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
@@ -108,11 +109,12 @@ func TestColumnHandler(t *testing.T) {
 	t.Run("Get column values as placeholder CSV", func(t *testing.T) {
 		colValsPlcHdrsAll, err := colHdr.ColumnValuesPlaceholderCsv(false)
 		require.NoError(t, err)
-		require.Equal(t, "$1, $2, $3", colValsPlcHdrsAll)
+		require.Equal(t, fmt.Sprintf("%s, %s, %s", Placeholder(conn.Type(), 1), Placeholder(conn.Type(), 2), Placeholder(conn.Type(), 3)),
+			colValsPlcHdrsAll)
 
 		colValsPlcHdrsWriteable, err := colHdr.ColumnValuesPlaceholderCsv(true)
 		require.NoError(t, err)
-		require.Equal(t, "$1, $2", colValsPlcHdrsWriteable)
+		require.Equal(t, fmt.Sprintf("%s, %s", Placeholder(conn.Type(), 1), Placeholder(conn.Type(), 2)), colValsPlcHdrsWriteable)
 	})
 
 	t.Run("Get column entries as CSV", func(t *testing.T) {
@@ -133,12 +135,12 @@ func TestColumnHandler(t *testing.T) {
 	t.Run("Get column entries as placeholder CSV", func(t *testing.T) {
 		kvPairsPlcHdrsAll, plcHdrCnt, err := colHdr.ColumnEntriesPlaceholderCsv(false)
 		require.NoError(t, err)
-		require.Regexp(t, regexp.MustCompile(`((col_1|col_2|col_3)=\$[1-3](, )?){3}`), kvPairsPlcHdrsAll)
+		require.Regexp(t, regexp.MustCompile(`((col_1|col_2|col_3)=[$?][1-3](, )?){3}`), kvPairsPlcHdrsAll)
 		require.Equal(t, 3, plcHdrCnt)
 
 		kvPairsPlcHdrsWriteonly, plcHdrCnt, err := colHdr.ColumnEntriesPlaceholderCsv(true)
 		require.NoError(t, err)
-		require.Regexp(t, regexp.MustCompile(`((col_1|col_3)=\$[1-2](, )?){2}`), kvPairsPlcHdrsWriteonly)
+		require.Regexp(t, regexp.MustCompile(`((col_1|col_3)=[$?][1-2](, )?){2}`), kvPairsPlcHdrsWriteonly)
 		require.Equal(t, 2, plcHdrCnt)
 	})
 }
@@ -0,0 +1,262 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var rawPlaceholderRegex = regexp.MustCompile(`\$(\d+)`)
+
+//Placeholder returns the placeholder of the n-th query argument (starting with 1) in the SQL dialect of the database
+func Placeholder(dbType Type, n int) string {
+	if dbType == SQLite {
+		//SQLite binds '$N' placeholders in order of their appearance, only '?N' is bound by index
+		return fmt.Sprintf("?%d", n)
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
+//Condition is a criteria of a WHERE clause. Fields are referenced by their entity field name (e.g. "ConfigVersion")
+//and can be qualified by the table of a joined entity (e.g. "inventory_clusters.Cluster", see Field()).
+type Condition interface {
+	sqlRenderer
+}
+
+type sqlRenderer interface {
+	render(r *renderer) (string, error)
+}
+
+//Field returns the field name qualified by the table of the entity
+func Field(entity DatabaseEntity, field string) string {
+	return fmt.Sprintf("%s.%s", entity.Table(), field)
+}
+
+//renderer converts conditions into SQL and collects their arguments
+type renderer struct {
+	dbType Type
+	column func(field string) (string, error)
+	args   *[]interface{}
+}
+
+func newRenderer(dbType Type, column func(field string) (string, error), args ...interface{}) *renderer {
+	return &renderer{
+		dbType: dbType,
+		column: column,
+		args:   &args,
+	}
+}
+
+//withColumns returns a renderer which shares the arguments but resolves the columns of another entity (e.g. sub-query)
+func (r *renderer) withColumns(column func(field string) (string, error)) *renderer {
+	return &renderer{
+		dbType: r.dbType,
+		column: column,
+		args:   r.args,
+	}
+}
+
+func (r *renderer) placeholder(arg interface{}) string {
+	*r.args = append(*r.args, arg)
+	return Placeholder(r.dbType, len(*r.args))
+}
+
+//raw replaces the placeholders '$1'...'$N' of a raw SQL fragment by the placeholders of its arguments
+func (r *renderer) raw(sql string, args []interface{}) (string, error) {
+	var err error
+	result := rawPlaceholderRegex.ReplaceAllStringFunc(sql, func(plcHdr string) string {
+		idx, _ := strconv.Atoi(plcHdr[1:])
+		if idx < 1 || idx > len(args) {
+			err = fmt.Errorf("placeholder '%s' of SQL fragment '%s' has no argument (got %d arguments)", plcHdr, sql, len(args))
+			return plcHdr
+		}
+		return r.placeholder(args[idx-1])
+	})
+	return result, err
+}
+
+func (r *renderer) conditions(conds []Condition, operator string) (string, error) {
+	var rendered []string
+	for _, cond := range conds {
+		sql, err := cond.render(r)
+		if err != nil {
+			return "", err
+		}
+		rendered = append(rendered, sql)
+	}
+	return strings.Join(rendered, fmt.Sprintf(" %s ", operator)), nil
+}
+
+//comparison:
+type comparison struct {
+	field    string
+	operator string
+	value    interface{}
+}
+
+func (c *comparison) render(r *renderer) (string, error) {
+	col, err := r.column(c.field)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s%s", col, c.operator, r.placeholder(c.value)), nil
+}
+
+func Eq(field string, value interface{}) Condition {
+	return &comparison{field, "=", value}
+}
+
+func Ne(field string, value interface{}) Condition {
+	return &comparison{field, "<>", value}
+}
+
+func Lt(field string, value interface{}) Condition {
+	return &comparison{field, "<", value}
+}
+
+func Le(field string, value interface{}) Condition {
+	return &comparison{field, "<=", value}
+}
+
+func Gt(field string, value interface{}) Condition {
+	return &comparison{field, ">", value}
+}
+
+func Ge(field string, value interface{}) Condition {
+	return &comparison{field, ">=", value}
+}
+
+//equals converts a field-value map into equal-conditions (sorted by field name to get a stable SQL statement)
+func equals(fieldValues map[string]interface{}) []Condition {
+	fields := make([]string, 0, len(fieldValues))
+	for field := range fieldValues {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	conds := make([]Condition, 0, len(fields))
+	for _, field := range fields {
+		conds = append(conds, Eq(field, fieldValues[field]))
+	}
+	return conds
+}
+
+//like:
+type like struct {
+	field   string
+	pattern string
+}
+
+func (l *like) render(r *renderer) (string, error) {
+	col, err := r.column(l.field)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`%s LIKE %s ESCAPE '\'`, col, r.placeholder(l.pattern)), nil
+}
+
+//Like matches the field against the pattern: wildcards '%' and '_' can be escaped by a backslash
+func Like(field, pattern string) Condition {
+	return &like{field, pattern}
+}
+
+//in:
+type in struct {
+	field    string
+	values   []interface{}
+	subQuery sqlRenderer
+}
+
+func (i *in) render(r *renderer) (string, error) {
+	col, err := r.column(i.field)
+	if err != nil {
+		return "", err
+	}
+	if i.subQuery != nil {
+		subQuery, err := i.subQuery.render(r)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s IN (%s)", col, subQuery), nil
+	}
+	if len(i.values) == 0 {
+		return "1=0", nil //no value can match an empty list
+	}
+	plcHdrs := make([]string, 0, len(i.values))
+	for _, value := range i.values {
+		plcHdrs = append(plcHdrs, r.placeholder(value))
+	}
+	return fmt.Sprintf("%s IN (%s)", col, strings.Join(plcHdrs, ", ")), nil
+}
+
+func In(field string, values ...interface{}) Condition {
+	return &in{field: field, values: values}
+}
+
+//InQuery matches the field against the results of a sub-query (use Select.Columns() or Select.Aggregate() to
+//define its result column)
+func InQuery(field string, subQuery *Select) Condition {
+	return &in{field: field, subQuery: subQuery}
+}
+
+//logical operators:
+type logical struct {
+	operator string
+	conds    []Condition
+}
+
+func (l *logical) render(r *renderer) (string, error) {
+	if len(l.conds) == 0 {
+		if l.operator == "OR" {
+			return "1=0", nil
+		}
+		return "1=1", nil
+	}
+	sql, err := r.conditions(l.conds, l.operator)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(%s)", sql), nil
+}
+
+func And(conds ...Condition) Condition {
+	return &logical{"AND", conds}
+}
+
+func Or(conds ...Condition) Condition {
+	return &logical{"OR", conds}
+}
+
+type not struct {
+	cond Condition
+}
+
+func (n *not) render(r *renderer) (string, error) {
+	sql, err := n.cond.render(r)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("NOT (%s)", sql), nil
+}
+
+func Not(cond Condition) Condition {
+	return &not{cond}
+}
+
+//raw:
+type raw struct {
+	sql  string
+	args []interface{}
+}
+
+func (rw *raw) render(r *renderer) (string, error) {
+	return r.raw(rw.sql, rw.args)
+}
+
+//Raw is an escape hatch for conditions which cannot be expressed by the query builder. Its placeholders
+//have to be numbered $1...$N relative to the passed arguments and are converted into the SQL dialect of the database.
+func Raw(sql string, args ...interface{}) Condition {
+	return &raw{sql, args}
+}
@@ -9,17 +9,21 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"io"
+	"sort"
 	"strings"
 )
 
 const keyIDLength = 15
 
+//Encryptor encrypts data with the active key. Data encrypted with a previous key
+//(e.g. before a key rotation) can still be decrypted: the key is selected by the key ID prefix of the data.
 type Encryptor struct {
-	keyID [16]byte
-	aead  cipher.AEAD
+	keyID        [16]byte
+	aead         cipher.AEAD
+	previousKeys map[string]cipher.AEAD //AEADs of previous keys by key ID
 }
 
-func NewEncryptor(key string) (*Encryptor, error) {
+func NewEncryptor(key string, previousKeys ...string) (*Encryptor, error) {
 	if len(key) == 0 {
 		return nil, fmt.Errorf("cannot create new encryptor instance because encryption key was an empty string")
 	}
@@ -29,10 +33,24 @@ func NewEncryptor(key string) (*Encryptor, error) {
 		return nil, err
 	}
 
-	return &Encryptor{
-		aead:  aead,
-		keyID: md5.Sum([]byte(key)), //nolint: gosec //using MD5 just for generating a checksum of the key
-	}, nil
+	encryptor := &Encryptor{
+		aead:         aead,
+		keyID:        md5.Sum([]byte(key)), //nolint: gosec //using MD5 just for generating a checksum of the key
+		previousKeys: make(map[string]cipher.AEAD, len(previousKeys)),
+	}
+
+	for idx, previousKey := range previousKeys {
+		previousAEAD, err := newAEAD(previousKey)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("previous encryption key #%d is invalid", idx+1))
+		}
+		previousKeyID := keyID(previousKey)
+		if previousKeyID != encryptor.KeyID() {
+			encryptor.previousKeys[previousKeyID] = previousAEAD
+		}
+	}
+
+	return encryptor, nil
 }
 
 //NewEncryptionKey generates a random 32 byte key for AES-256
@@ -59,6 +77,20 @@ func (e *Encryptor) KeyID() string {
 	return fmt.Sprintf("%x", e.keyID)[:keyIDLength]
 }
 
+//PreviousKeyIDs returns the IDs of the previous keys
+func (e *Encryptor) PreviousKeyIDs() []string {
+	result := make([]string, 0, len(e.previousKeys))
+	for previousKeyID := range e.previousKeys {
+		result = append(result, previousKeyID)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func keyID(key string) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(key)))[:keyIDLength] //nolint: gosec //using MD5 just for generating a checksum of the key
+}
+
 func (e *Encryptor) Encrypt(data string) (string, error) {
 	nonce := make([]byte, e.aead.NonceSize())
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
@@ -69,19 +101,23 @@ func (e *Encryptor) Encrypt(data string) (string, error) {
 }
 
 func (e *Encryptor) Decrypt(encData string) (string, error) {
-	if !e.Decryptable(encData) {
+	aead := e.aeadFor(encData)
+	if aead == nil {
 		return "", fmt.Errorf("data cannot be decrypted because encryption key does not match")
 	}
 
-	enc, err := hex.DecodeString(strings.TrimPrefix(encData, e.KeyID())) //remove keyID from encrypted data
+	enc, err := hex.DecodeString(encData[keyIDLength:]) //remove keyID from encrypted data
 	if err != nil {
 		return "", fmt.Errorf("failed to decode HEX string to bytes")
 	}
 
-	nonceSize := e.aead.NonceSize()
+	nonceSize := aead.NonceSize()
+	if len(enc) < nonceSize {
+		return "", fmt.Errorf("encrypted data is too short")
+	}
 	nonce, cipherText := enc[:nonceSize], enc[nonceSize:]
 
-	data, err := e.aead.Open(nil, nonce, cipherText, nil)
+	data, err := aead.Open(nil, nonce, cipherText, nil)
 	if err != nil {
 		return "", err
 	}
@@ -89,7 +125,41 @@ func (e *Encryptor) Decrypt(encData string) (string, error) {
 	return fmt.Sprintf("%s", data), nil
 }
 
+//Reencrypt decrypts the data and encrypts it with the active key
+func (e *Encryptor) Reencrypt(encData string) (string, error) {
+	data, err := e.Decrypt(encData)
+	if err != nil {
+		return "", err
+	}
+	return e.Encrypt(data)
+}
+
 //Decryptable verifies whether the encrypted data can be decrypted by this Encryptor instance
 func (e *Encryptor) Decryptable(encData string) bool {
-	return strings.HasPrefix(encData, e.KeyID()) //KeyID prefix of encrypted data has to match with current KeyID
+	return e.aeadFor(encData) != nil //KeyID prefix of encrypted data has to match with the active or a previous KeyID
+}
+
+//EncryptedWithActiveKey verifies whether the data was encrypted with the active key
+func (e *Encryptor) EncryptedWithActiveKey(encData string) bool {
+	return strings.HasPrefix(encData, e.KeyID())
+}
+
+//looksEncrypted verifies whether the data has the format of encrypted data (key ID prefix followed by
+//the HEX encoded nonce and cipher text), regardless whether the key is known or not
+func looksEncrypted(data string) bool {
+	if len(data) <= keyIDLength {
+		return false
+	}
+	_, err := hex.DecodeString(data[keyIDLength:])
+	return err == nil && strings.Trim(data[:keyIDLength], "0123456789abcdef") == ""
+}
+
+func (e *Encryptor) aeadFor(encData string) cipher.AEAD {
+	if len(encData) < keyIDLength {
+		return nil
+	}
+	if e.EncryptedWithActiveKey(encData) {
+		return e.aead
+	}
+	return e.previousKeys[encData[:keyIDLength]]
 }
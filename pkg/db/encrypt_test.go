@@ -71,6 +71,71 @@ func TestEncryptor(t *testing.T) {
 		require.Equal(t, decData1, decData2)
 	})
 
+	t.Run("Decrypt with previous keys", func(t *testing.T) {
+		oldKey, err := NewEncryptionKey()
+		require.NoError(t, err)
+		oldEnc, err := NewEncryptor(oldKey)
+		require.NoError(t, err)
+		encData, err := oldEnc.Encrypt(data)
+		require.NoError(t, err)
+
+		newKey, err := NewEncryptionKey()
+		require.NoError(t, err)
+		newEnc, err := NewEncryptor(newKey, oldKey)
+		require.NoError(t, err)
+		require.Equal(t, []string{oldEnc.KeyID()}, newEnc.PreviousKeyIDs())
+
+		require.True(t, newEnc.Decryptable(encData))
+		require.False(t, newEnc.EncryptedWithActiveKey(encData))
+		decData, err := newEnc.Decrypt(encData)
+		require.NoError(t, err)
+		require.Equal(t, data, decData)
+
+		//new data is encrypted with the active key
+		encData, err = newEnc.Encrypt(data)
+		require.NoError(t, err)
+		require.True(t, newEnc.EncryptedWithActiveKey(encData))
+		require.False(t, oldEnc.Decryptable(encData))
+	})
+
+	t.Run("Reencrypt with active key", func(t *testing.T) {
+		oldKey, err := NewEncryptionKey()
+		require.NoError(t, err)
+		oldEnc, err := NewEncryptor(oldKey)
+		require.NoError(t, err)
+		encData, err := oldEnc.Encrypt(data)
+		require.NoError(t, err)
+
+		newKey, err := NewEncryptionKey()
+		require.NoError(t, err)
+		newEnc, err := NewEncryptor(newKey, oldKey)
+		require.NoError(t, err)
+
+		reencData, err := newEnc.Reencrypt(encData)
+		require.NoError(t, err)
+		require.True(t, newEnc.EncryptedWithActiveKey(reencData))
+
+		activeEnc, err := NewEncryptor(newKey)
+		require.NoError(t, err)
+		decData, err := activeEnc.Decrypt(reencData)
+		require.NoError(t, err)
+		require.Equal(t, data, decData)
+	})
+
+	t.Run("Works not with invalid previous key", func(t *testing.T) {
+		key, err := NewEncryptionKey()
+		require.NoError(t, err)
+		_, err = NewEncryptor(key, "abc123!")
+		require.Error(t, err)
+	})
+
+	t.Run("Decrypt too short data", func(t *testing.T) {
+		enc := newEncryptor(t)
+		_, err := enc.Decrypt("abc")
+		require.Error(t, err)
+		_, err = enc.Decrypt(enc.KeyID())
+		require.Error(t, err)
+	})
 }
 
 func newEncryptor(t *testing.T) *Encryptor {
@@ -7,28 +7,36 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
+//EncryptionKeyBackupSuffix is the file suffix of encryption key backups
+const EncryptionKeyBackupSuffix = ".bak"
+
 func NewConnectionFactory(configFile string, debug bool) (ConnectionFactory, error) {
 	viper.SetConfigFile(configFile)
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, err
 	}
 
-	encKey, err := readEncryptionKey()
+	return newConnectionFactory(viper.GetString("db.driver"), debug)
+}
+
+//newConnectionFactory creates the factory of the driver using the settings of the previously read configuration file
+func newConnectionFactory(dbToUse string, debug bool) (ConnectionFactory, error) {
+	encKey, previousEncKeys, err := readEncryptionKeys()
 	if err != nil {
 		return nil, err
 	}
 
-	dbToUse := viper.GetString("db.driver")
-
 	switch dbToUse {
 	case "postgres":
-		connFact := createPostgresConnectionFactory(encKey, debug)
+		connFact := createPostgresConnectionFactory(encKey, previousEncKeys, debug)
 		return connFact, connFact.Init()
 
 	case "sqlite":
-		connFact, err := createSqliteConnectionFactory(encKey, debug)
+		connFact, err := createSqliteConnectionFactory(encKey, previousEncKeys, debug)
 		if err != nil {
 			return nil, err
 		}
@@ -39,7 +47,89 @@ func NewConnectionFactory(configFile string, debug bool) (ConnectionFactory, err
 	}
 }
 
-func readEncryptionKey() (string, error) {
+//readEncryptionKeys returns the active encryption key and the previous keys. Previous keys are read from the files
+//(or directories, e.g. a mounted secret) configured in 'db.encryption.previousKeyFiles' and from the backups of
+//the key file which are created when a new key is generated locally.
+func readEncryptionKeys() (string, []string, error) {
+	encKeyFile := EncryptionKeyFile()
+	if !file.Exists(encKeyFile) {
+		return "", nil, fmt.Errorf("encryption key file '%s' not found", encKeyFile)
+	}
+
+	encKeyBytes, err := ioutil.ReadFile(encKeyFile)
+	if err != nil {
+		return "", nil, err
+	}
+
+	previousKeyFiles, err := PreviousEncryptionKeyFiles()
+	if err != nil {
+		return "", nil, err
+	}
+	backupFiles, err := filepath.Glob(fmt.Sprintf("%s.*%s", encKeyFile, EncryptionKeyBackupSuffix))
+	if err != nil {
+		return "", nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backupFiles))) //newest backup first
+	previousKeyFiles = append(previousKeyFiles, backupFiles...)
+
+	var previousEncKeys []string
+	for _, previousKeyFile := range previousKeyFiles {
+		previousEncKeyBytes, err := ioutil.ReadFile(previousKeyFile)
+		if err != nil {
+			return "", nil, err
+		}
+		previousEncKeys = append(previousEncKeys, strings.TrimSpace(string(previousEncKeyBytes)))
+	}
+
+	return strings.TrimSpace(string(encKeyBytes)), previousEncKeys, nil
+}
+
+//PreviousEncryptionKeyFiles returns the absolute paths of the configured previous encryption keys.
+//Configured directories are resolved to the files they contain (hidden entries are ignored).
+func PreviousEncryptionKeyFiles() ([]string, error) {
+	paths := viper.GetStringSlice("db.encryption.previousKeyFiles")
+	//overwrite previous key files if env-var is defined (comma separated list)
+	if viper.IsSet("DATABASE_ENCRYPTION_PREVIOUS_KEYFILES") {
+		paths = strings.Split(viper.GetString("DATABASE_ENCRYPTION_PREVIOUS_KEYFILES"), ",")
+	}
+
+	var result []string
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			//define absolute path relative to config-file directory
+			path = filepath.Join(filepath.Dir(viper.ConfigFileUsed()), path)
+		}
+		if !file.DirExists(path) {
+			if !file.Exists(path) {
+				return nil, fmt.Errorf("previous encryption key file '%s' not found", path)
+			}
+			result = append(result, path)
+			continue
+		}
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			//Kubernetes mounts the keys of a secret as symlinks next to hidden data directories
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			keyFile := filepath.Join(path, entry.Name())
+			if file.Exists(keyFile) {
+				result = append(result, keyFile)
+			}
+		}
+	}
+	return result, nil
+}
+
+//EncryptionKeyFile returns the absolute path of the configured encryption key file
+func EncryptionKeyFile() string {
 	encKeyFile := viper.GetString("db.encryption.keyFile")
 	if encKeyFile != "" {
 		if !filepath.IsAbs(encKeyFile) {
@@ -53,18 +143,10 @@ func readEncryptionKey() (string, error) {
 		encKeyFile = viper.GetString("DATABASE_ENCRYPTION_KEYFILE")
 	}
 
-	if !file.Exists(encKeyFile) {
-		return "", fmt.Errorf("encryption key file '%s' not found", encKeyFile)
-	}
-
-	encKeyBytes, err := ioutil.ReadFile(encKeyFile)
-	if err != nil {
-		return "", err
-	}
-	return string(encKeyBytes), nil
+	return encKeyFile
 }
 
-func createSqliteConnectionFactory(encKey string, debug bool) (*SqliteConnectionFactory, error) {
+func createSqliteConnectionFactory(encKey string, previousEncKeys []string, debug bool) (*SqliteConnectionFactory, error) {
 	dbFile := viper.GetString("db.sqlite.file")
 	//ensure directory structure of db-file exists
 	dbFileDir := filepath.Dir(dbFile)
@@ -74,18 +156,17 @@ func createSqliteConnectionFactory(encKey string, debug bool) (*SqliteConnection
 		}
 	}
 	connFact := &SqliteConnectionFactory{
-		File:          dbFile,
-		Debug:         debug,
-		Reset:         viper.GetBool("db.sqlite.resetDatabase"),
-		EncryptionKey: encKey,
-	}
-	if viper.GetBool("db.sqlite.deploySchema") {
-		connFact.SchemaFile = filepath.Join(filepath.Dir(viper.ConfigFileUsed()), "db", "sqlite", "reconciler.sql")
+		File:                   dbFile,
+		Debug:                  debug,
+		Reset:                  viper.GetBool("db.sqlite.resetDatabase"),
+		DeploySchema:           viper.GetBool("db.sqlite.deploySchema"),
+		EncryptionKey:          encKey,
+		PreviousEncryptionKeys: previousEncKeys,
 	}
 	return connFact, nil
 }
 
-func createPostgresConnectionFactory(encKey string, debug bool) *PostgresConnectionFactory {
+func createPostgresConnectionFactory(encKey string, previousEncKeys []string, debug bool) *PostgresConnectionFactory {
 	host := viper.GetString("db.postgres.host")
 	port := viper.GetInt("db.postgres.port")
 	database := viper.GetString("db.postgres.database")
@@ -113,13 +194,15 @@ func createPostgresConnectionFactory(encKey string, debug bool) *PostgresConnect
 	}
 
 	return &PostgresConnectionFactory{
-		Host:          host,
-		Port:          port,
-		Database:      database,
-		User:          user,
-		Password:      password,
-		SslMode:       sslMode,
-		EncryptionKey: encKey,
-		Debug:         debug,
+		Host:                   host,
+		Port:                   port,
+		Database:               database,
+		User:                   user,
+		Password:               password,
+		SslMode:                sslMode,
+		EncryptionKey:          encKey,
+		PreviousEncryptionKeys: previousEncKeys,
+		Debug:                  debug,
+		Pool:                   readPoolSettings(),
 	}
 }
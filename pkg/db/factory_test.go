@@ -0,0 +1,53 @@
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEncryptionKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0700))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	writeFile("active.key", "active\n")
+	writeFile("previous.key", "previous")
+	writeFile("secret/key1", "secret1") //directory of a mounted secret
+	writeFile("secret/..data/key1", "hidden")
+	writeFile("active.key.1600000000.bak", "backup")
+	writeFile("reconciler.yaml", `
+db:
+  encryption:
+    keyFile: "active.key"
+    previousKeyFiles:
+    - "previous.key"
+    - "secret"
+`)
+	viper.SetConfigFile(filepath.Join(dir, "reconciler.yaml"))
+	require.NoError(t, viper.ReadInConfig())
+
+	key, previousKeys, err := readEncryptionKeys()
+	require.NoError(t, err)
+	require.Equal(t, "active", key)
+	require.Equal(t, []string{"previous", "secret1", "backup"}, previousKeys)
+
+	t.Run("Missing previous key file", func(t *testing.T) {
+		writeFile("missing.yaml", `
+db:
+  encryption:
+    keyFile: "active.key"
+    previousKeyFiles:
+    - "missing.key"
+`)
+		viper.SetConfigFile(filepath.Join(dir, "missing.yaml"))
+		require.NoError(t, viper.ReadInConfig())
+		_, _, err := readEncryptionKeys()
+		require.Error(t, err)
+	})
+}
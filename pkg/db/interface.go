@@ -15,14 +15,18 @@ type Connection interface {
 	QueryRow(query string, args ...interface{}) DataRow
 	Query(query string, args ...interface{}) (DataRows, error)
 	Exec(query string, args ...interface{}) (sql.Result, error)
-	Begin() (*sql.Tx, error)
+	Begin() (*Tx, error)
 	Close() error
 	Type() Type
 }
 
+//ConnectionFactory manages a pooled connection which is shared by all users of the factory
 type ConnectionFactory interface {
 	Init() error
 	NewConnection() (Connection, error)
+	Ping() error        //health check of the database
+	Stats() sql.DBStats //statistics of the connection pool
+	Close() error       //closes the pooled connections
 }
 
 type DatabaseEntity interface {
@@ -38,7 +42,10 @@ type DataRow interface {
 	Scan(dest ...interface{}) error
 }
 
+//DataRows is implemented by sql.Rows: the rows have to be closed by the caller
 type DataRows interface {
 	Scan(dest ...interface{}) error
 	Next() bool
+	Err() error
+	Close() error
 }
@@ -0,0 +1,375 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	//MigrationTable tracks the applied schema migrations
+	MigrationTable = "schema_versions"
+	//LegacyMigrationTable contains the schema version of databases which were migrated by golang-migrate
+	LegacyMigrationTable = "schema_migrations"
+	//migrationLockID identifies the Postgres advisory lock which serializes migrations of multiple replicas
+	migrationLockID = 7245910831
+)
+
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+var migrationFileRegex = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+//Migration is a schema change: migrations are embedded into the binary and have to exist for all database types
+//with the same version and name (files 'migrations/<type>/<version>_<name>.up.sql' and '...down.sql')
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+func (m *Migration) String() string {
+	return fmt.Sprintf("Migration [Version=%d,Name=%s]", m.Version, m.Name)
+}
+
+type MigrationStatus struct {
+	*Migration
+	Applied bool
+	Created time.Time //timestamp when the migration was applied
+}
+
+func (ms *MigrationStatus) String() string {
+	return fmt.Sprintf("MigrationStatus [Version=%d,Name=%s,Applied=%t]", ms.Version, ms.Name, ms.Applied)
+}
+
+//Migrations returns the migrations of a database type ordered by version
+func Migrations(dbType Type) ([]*Migration, error) {
+	migrationsByType := make(map[Type][]*Migration)
+	for _, t := range []Type{Postgres, SQLite} {
+		migrations, err := readMigrations(t)
+		if err != nil {
+			return nil, err
+		}
+		migrationsByType[t] = migrations
+	}
+	if err := verifyLockstep(migrationsByType[Postgres], migrationsByType[SQLite]); err != nil {
+		return nil, err
+	}
+	migrations, ok := migrationsByType[dbType]
+	if !ok {
+		return nil, fmt.Errorf("migrations for database type '%s' are not supported", dbType)
+	}
+	return migrations, nil
+}
+
+func readMigrations(dbType Type) ([]*Migration, error) {
+	dir := path.Join("migrations", string(dbType))
+	files, err := migrationFiles.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsByVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		matches := migrationFileRegex.FindStringSubmatch(file.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("migration file '%s' of database type '%s' has an invalid name", file.Name(), dbType)
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := migrationsByVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			migrationsByVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d of database type '%s' is used by '%s' and '%s'",
+				version, dbType, migration.Name, matches[2])
+		}
+		ddl, err := migrationFiles.ReadFile(path.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if matches[3] == "up" {
+			migration.Up = string(ddl)
+		} else {
+			migration.Down = string(ddl)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(migrationsByVersion))
+	for _, migration := range migrationsByVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("%s of database type '%s' requires an up and a down file", migration, dbType)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+//verifyLockstep ensures that each migration exists for all database types
+func verifyLockstep(migrations, otherMigrations []*Migration) error {
+	if len(migrations) != len(otherMigrations) {
+		return fmt.Errorf("number of migrations differs between database types: %d != %d",
+			len(migrations), len(otherMigrations))
+	}
+	for idx, migration := range migrations {
+		if migration.Version != otherMigrations[idx].Version || migration.Name != otherMigrations[idx].Name {
+			return fmt.Errorf("migrations differ between database types: %s != %s", migration, otherMigrations[idx])
+		}
+	}
+	return nil
+}
+
+//Migrator applies and reverts the schema migrations of a database
+type Migrator struct {
+	conn       Connection
+	logger     *zap.SugaredLogger
+	migrations []*Migration
+}
+
+func NewMigrator(conn Connection, logger *zap.SugaredLogger) (*Migrator, error) {
+	migrations, err := Migrations(conn.Type())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		conn:       conn,
+		logger:     logger,
+		migrations: migrations,
+	}, nil
+}
+
+//Up applies all pending migrations and returns the number of applied migrations. Each migration is applied
+//in its own transaction which holds the migration lock: concurrent migrators skip migrations applied in the meantime.
+func (m *Migrator) Up() (int, error) {
+	var count int
+	for _, migration := range m.migrations {
+		migration := migration
+		dbOps := func(tx *Tx) (interface{}, error) {
+			versions, err := m.lock(tx)
+			if err != nil {
+				return false, err
+			}
+			if _, ok := versions[migration.Version]; ok {
+				return false, nil
+			}
+			m.logger.Infof("Applying %s", migration)
+			if _, err := tx.Exec(migration.Up); err != nil {
+				return false, err
+			}
+			_, err = tx.Exec(fmt.Sprintf(`INSERT INTO %s ("version", "name") VALUES ($1, $2)`, MigrationTable),
+				migration.Version, migration.Name)
+			return true, err
+		}
+		applied, err := TransactionResult(m.conn, dbOps, m.logger)
+		if err != nil {
+			return count, errors.Wrap(err, fmt.Sprintf("failed to apply %s", migration))
+		}
+		if applied.(bool) {
+			count++
+		}
+	}
+	if count == 0 {
+		m.logger.Debug("Database schema is up to date")
+	}
+	return count, nil
+}
+
+//Down reverts the latest applied migrations and returns the number of reverted migrations
+func (m *Migrator) Down(steps int) (int, error) {
+	var count int
+	for count < steps {
+		dbOps := func(tx *Tx) (interface{}, error) {
+			versions, err := m.lock(tx)
+			if err != nil {
+				return nil, err
+			}
+			var latestVersion int64
+			for version := range versions {
+				if version > latestVersion {
+					latestVersion = version
+				}
+			}
+			if latestVersion == 0 {
+				return nil, nil
+			}
+			migration := m.migration(latestVersion)
+			if migration == nil {
+				return nil, fmt.Errorf("applied migration version %d is unknown: cannot revert it", latestVersion)
+			}
+			m.logger.Infof("Reverting %s", migration)
+			if _, err := tx.Exec(migration.Down); err != nil {
+				return nil, err
+			}
+			_, err = tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE "version"=$1`, MigrationTable), migration.Version)
+			return migration, err
+		}
+		migration, err := TransactionResult(m.conn, dbOps, m.logger)
+		if err != nil {
+			return count, errors.Wrap(err, "failed to revert migration")
+		}
+		if migration == nil { //no applied migrations left
+			break
+		}
+		count++
+	}
+	return count, nil
+}
+
+//Status returns the status of all known migrations ordered by version
+func (m *Migrator) Status() ([]*MigrationStatus, error) {
+	exists, err := tableExists(m.conn, MigrationTable)
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[int64]time.Time)
+	var legacyVersion int64
+	if exists {
+		if versions, err = m.versions(m.conn); err != nil {
+			return nil, err
+		}
+	} else if legacyVersion, err = m.legacyVersion(m.conn); err != nil { //gets adopted by the first migration
+		return nil, err
+	}
+	var result []*MigrationStatus
+	for _, migration := range m.migrations {
+		created, applied := versions[migration.Version]
+		applied = applied || migration.Version <= legacyVersion
+		result = append(result, &MigrationStatus{
+			Migration: migration,
+			Applied:   applied,
+			Created:   created,
+		})
+	}
+	return result, nil
+}
+
+func (m *Migrator) migration(version int64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+	return nil
+}
+
+//lock acquires the migration lock (released at the end of the transaction) and returns the applied versions
+func (m *Migrator) lock(tx *Tx) (map[int64]time.Time, error) {
+	if tx.Type() == Postgres {
+		if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", migrationLockID); err != nil {
+			return nil, err
+		}
+	} //SQLite locks the whole database file when the transaction writes
+	exists, err := tableExists(tx, MigrationTable)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.createTable(tx); err != nil {
+		return nil, err
+	}
+	if !exists { //first migration of this runner
+		return m.adoptLegacyVersion(tx)
+	}
+	return m.versions(tx)
+}
+
+//adoptLegacyVersion records the migrations which were applied by golang-migrate as applied migrations
+//(otherwise they would be applied again) and returns the applied versions
+func (m *Migrator) adoptLegacyVersion(tx *Tx) (map[int64]time.Time, error) {
+	legacyVersion, err := m.legacyVersion(tx)
+	if err != nil || legacyVersion == 0 {
+		return map[int64]time.Time{}, err
+	}
+	for _, migration := range m.migrations {
+		if migration.Version > legacyVersion {
+			break
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`INSERT INTO %s ("version", "name") VALUES ($1, $2)`, MigrationTable),
+			migration.Version, migration.Name); err != nil {
+			return nil, err
+		}
+	}
+	m.logger.Infof("Adopted schema version %d of table '%s' as baseline of the applied migrations",
+		legacyVersion, LegacyMigrationTable)
+	return m.versions(tx)
+}
+
+//legacyVersion returns the schema version recorded by golang-migrate (0 if the database wasn't migrated by it)
+func (m *Migrator) legacyVersion(conn Connection) (int64, error) {
+	exists, err := tableExists(conn, LegacyMigrationTable)
+	if err != nil || !exists {
+		return 0, err
+	}
+	var version int64
+	var dirty bool
+	err = conn.QueryRow(fmt.Sprintf(`SELECT "version", "dirty" FROM %s`, LegacyMigrationTable)).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("schema version %d in table '%s' is dirty: the failed migration has to be fixed manually",
+			version, LegacyMigrationTable)
+	}
+	return version, nil
+}
+
+func tableExists(conn Connection, table string) (bool, error) {
+	query := "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=$1"
+	if conn.Type() == Postgres {
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema=current_schema() AND table_name=$1"
+	}
+	var count int
+	err := conn.QueryRow(query, table).Scan(&count)
+	return count > 0, err
+}
+
+func (m *Migrator) createTable(conn Connection) error {
+	createdType := "TIMESTAMP DEFAULT CURRENT_TIMESTAMP"
+	if conn.Type() == Postgres {
+		createdType = "TIMESTAMP WITHOUT TIME ZONE DEFAULT (NOW() AT TIME ZONE 'utc')"
+	}
+	_, err := conn.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	"version" bigint PRIMARY KEY,
+	"name" text NOT NULL,
+	"created" %s
+)`, MigrationTable, createdType))
+	return err
+}
+
+func (m *Migrator) versions(conn Connection) (map[int64]time.Time, error) {
+	rows, err := conn.Query(fmt.Sprintf(`SELECT "version", "created" FROM %s`, MigrationTable))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	versions := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var created time.Time
+		if err := rows.Scan(&version, &created); err != nil {
+			return nil, err
+		}
+		versions[version] = created
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return versions, rows.Close()
+}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	t.Run("Migrations are in lockstep", func(t *testing.T) {
+		pgMigrations, err := Migrations(Postgres)
+		require.NoError(t, err)
+		require.NotEmpty(t, pgMigrations)
+		sqliteMigrations, err := Migrations(SQLite)
+		require.NoError(t, err)
+		require.Len(t, sqliteMigrations, len(pgMigrations))
+		for idx, migration := range pgMigrations {
+			require.Equal(t, migration.Version, sqliteMigrations[idx].Version)
+			require.Equal(t, migration.Name, sqliteMigrations[idx].Name)
+			require.NotEmpty(t, migration.Up)
+			require.NotEmpty(t, migration.Down)
+			if idx > 0 {
+				require.Greater(t, migration.Version, pgMigrations[idx-1].Version)
+			}
+		}
+	})
+
+	t.Run("Detect migrations which are not in lockstep", func(t *testing.T) {
+		migrations := []*Migration{{Version: 1, Name: "abc"}, {Version: 2, Name: "def"}}
+		require.NoError(t, verifyLockstep(migrations, []*Migration{{Version: 1, Name: "abc"}, {Version: 2, Name: "def"}}))
+		require.Error(t, verifyLockstep(migrations, []*Migration{{Version: 1, Name: "abc"}}))
+		require.Error(t, verifyLockstep(migrations, []*Migration{{Version: 1, Name: "abc"}, {Version: 2, Name: "xyz"}}))
+		require.Error(t, verifyLockstep(migrations, []*Migration{{Version: 1, Name: "abc"}, {Version: 3, Name: "def"}}))
+	})
+
+	t.Run("Unsupported database type", func(t *testing.T) {
+		_, err := Migrations(Mock)
+		require.Error(t, err)
+	})
+}
+
+func TestMigrator(t *testing.T) {
+	key, err := NewEncryptionKey()
+	require.NoError(t, err)
+	conn := newRotationTestConnection(t, filepath.Join(t.TempDir(), "migration.db"), key)
+
+	migrator, err := NewMigrator(conn, logger.NewOptionalLogger(true))
+	require.NoError(t, err)
+	migrations, err := Migrations(SQLite)
+	require.NoError(t, err)
+
+	requireApplied := func(t *testing.T, expected int) {
+		statuses, err := migrator.Status()
+		require.NoError(t, err)
+		require.Len(t, statuses, len(migrations))
+		for idx, status := range statuses {
+			require.Equal(t, idx < expected, status.Applied, status.String())
+			require.Equal(t, status.Applied, !status.Created.IsZero())
+		}
+	}
+	tableExists := func(table string) bool {
+		var count int
+		require.NoError(t, conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=$1", table).Scan(&count))
+		return count == 1
+	}
+
+	t.Run("Status of empty database", func(t *testing.T) {
+		requireApplied(t, 0)
+	})
+
+	t.Run("Apply all migrations", func(t *testing.T) {
+		count, err := migrator.Up()
+		require.NoError(t, err)
+		require.Equal(t, len(migrations), count)
+		requireApplied(t, len(migrations))
+		require.True(t, tableExists("inventory_clusters"))
+		require.True(t, tableExists("config_bucket_rules"))
+	})
+
+	t.Run("Apply migrations again", func(t *testing.T) {
+		count, err := migrator.Up()
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+	})
+
+	t.Run("Revert latest migration", func(t *testing.T) {
+		count, err := migrator.Down(1)
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		requireApplied(t, len(migrations)-1)
+
+		count, err = migrator.Up()
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		requireApplied(t, len(migrations))
+	})
+
+	t.Run("Revert all migrations", func(t *testing.T) {
+		count, err := migrator.Down(len(migrations) + 1)
+		require.NoError(t, err)
+		require.Equal(t, len(migrations), count)
+		requireApplied(t, 0)
+		require.False(t, tableExists("inventory_clusters"))
+		require.True(t, tableExists(MigrationTable))
+	})
+}
+
+func TestMigratorAdoptsLegacyVersion(t *testing.T) {
+	key, err := NewEncryptionKey()
+	require.NoError(t, err)
+	migrations, err := Migrations(SQLite)
+	require.NoError(t, err)
+
+	//database migrated by golang-migrate up to the given version
+	newLegacyDatabase := func(t *testing.T, version int64, dirty bool) Connection {
+		conn := newRotationTestConnection(t, filepath.Join(t.TempDir(), "legacy.db"), key)
+		for _, migration := range migrations[:version] {
+			_, err := conn.Exec(migration.Up)
+			require.NoError(t, err)
+		}
+		_, err := conn.Exec(fmt.Sprintf(`CREATE TABLE %s ("version" bigint NOT NULL PRIMARY KEY, "dirty" boolean NOT NULL)`,
+			LegacyMigrationTable))
+		require.NoError(t, err)
+		_, err = conn.Exec(fmt.Sprintf(`INSERT INTO %s ("version", "dirty") VALUES ($1, $2)`, LegacyMigrationTable), version, dirty)
+		require.NoError(t, err)
+		return conn
+	}
+
+	t.Run("Legacy migrations are not applied again", func(t *testing.T) {
+		migrator, err := NewMigrator(newLegacyDatabase(t, 2, false), logger.NewOptionalLogger(true))
+		require.NoError(t, err)
+
+		statuses, err := migrator.Status()
+		require.NoError(t, err)
+		for idx, status := range statuses {
+			require.Equal(t, idx < 2, status.Applied, status.String())
+		}
+
+		count, err := migrator.Up()
+		require.NoError(t, err)
+		require.Equal(t, len(migrations)-2, count)
+
+		//a reverted legacy migration is not adopted again
+		count, err = migrator.Down(len(migrations))
+		require.NoError(t, err)
+		require.Equal(t, len(migrations), count)
+		count, err = migrator.Up()
+		require.NoError(t, err)
+		require.Equal(t, len(migrations), count)
+	})
+
+	t.Run("Dirty legacy version", func(t *testing.T) {
+		migrator, err := NewMigrator(newLegacyDatabase(t, 1, true), logger.NewOptionalLogger(true))
+		require.NoError(t, err)
+		_, err = migrator.Up()
+		require.Error(t, err)
+	})
+}
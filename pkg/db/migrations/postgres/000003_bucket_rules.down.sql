@@ -0,0 +1 @@
+DROP TABLE IF EXISTS config_bucket_rules;
\ No newline at end of file
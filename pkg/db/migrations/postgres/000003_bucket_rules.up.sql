@@ -0,0 +1,12 @@
+--DDL for configuration bucket-rule entities:
+CREATE TABLE IF NOT EXISTS config_bucket_rules (
+	"version" SERIAL UNIQUE, --can also be used as unique identifier for a bucket rule
+	"rule" text NOT NULL,
+	"priority" integer NOT NULL DEFAULT 0,
+	"selector" text NOT NULL,
+	"buckets" text NOT NULL,
+	"username" varchar(255) NOT NULL,
+	"deleted" boolean DEFAULT FALSE,
+	"created" TIMESTAMP WITHOUT TIME ZONE DEFAULT (NOW() AT TIME ZONE 'utc'),
+	CONSTRAINT config_bucket_rules_pk PRIMARY KEY ("rule", "version")
+)
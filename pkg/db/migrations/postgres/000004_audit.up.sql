@@ -0,0 +1,13 @@
+--DDL for audit entries (append-only: entries are never updated or deleted by the reconciler):
+CREATE TABLE IF NOT EXISTS audit_log (
+	"id" SERIAL PRIMARY KEY,
+	"actor" varchar(255) NOT NULL,
+	"action" varchar(64) NOT NULL,
+	"entity_type" varchar(64) NOT NULL,
+	"target" text NOT NULL,
+	"version_before" bigint NOT NULL DEFAULT 0,
+	"version_after" bigint NOT NULL DEFAULT 0,
+	"details" text NOT NULL DEFAULT '',
+	"created" TIMESTAMP WITHOUT TIME ZONE DEFAULT (NOW() AT TIME ZONE 'utc')
+);
+CREATE INDEX IF NOT EXISTS audit_log_created_idx ON audit_log ("created");
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS scheduler_operations;
\ No newline at end of file
@@ -0,0 +1,12 @@
+--DDL for configuration bucket-rule entities:
+CREATE TABLE IF NOT EXISTS config_bucket_rules (
+	"version" integer PRIMARY KEY AUTOINCREMENT,
+	"rule" text NOT NULL,
+	"priority" integer NOT NULL DEFAULT 0,
+	"selector" text NOT NULL,
+	"buckets" text NOT NULL,
+	"username" varchar(255) NOT NULL,
+	"deleted" boolean DEFAULT FALSE,
+	"created" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT config_bucket_rules_pk UNIQUE ("rule", "version")
+)
@@ -0,0 +1,13 @@
+--DDL for audit entries (append-only: entries are never updated or deleted by the reconciler):
+CREATE TABLE IF NOT EXISTS audit_log (
+	"id" integer PRIMARY KEY AUTOINCREMENT,
+	"actor" text NOT NULL,
+	"action" text NOT NULL,
+	"entity_type" text NOT NULL,
+	"target" text NOT NULL,
+	"version_before" integer NOT NULL DEFAULT 0,
+	"version_after" integer NOT NULL DEFAULT 0,
+	"details" text NOT NULL DEFAULT '',
+	"created" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS audit_log_created_idx ON audit_log ("created");
@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"fmt"
 )
 
 const (
@@ -36,6 +37,14 @@ func (dr *MockDataRows) Next() bool {
 	return false
 }
 
+func (dr *MockDataRows) Err() error {
+	return nil
+}
+
+func (dr *MockDataRows) Close() error {
+	return nil
+}
+
 type MockResult struct {
 }
 
@@ -73,8 +82,8 @@ func (c *MockConnection) Exec(query string, args ...interface{}) (sql.Result, er
 	return &MockResult{}, nil
 }
 
-func (c *MockConnection) Begin() (*sql.Tx, error) {
-	return nil, nil
+func (c *MockConnection) Begin() (*Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported by the mock connection")
 }
 
 func (c *MockConnection) Close() error {
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+//PoolSettings configures the connection pool of a connection factory: zero values keep the defaults of database/sql
+type PoolSettings struct {
+	MaxOpenConns    int           //maximum number of open connections (default: unlimited)
+	MaxIdleConns    int           //maximum number of idle connections (default: 2)
+	ConnMaxLifetime time.Duration //maximum time a connection is reused (default: unlimited)
+	ConnMaxIdleTime time.Duration //maximum time a connection stays idle (default: unlimited)
+}
+
+func (ps PoolSettings) String() string {
+	return fmt.Sprintf("PoolSettings [MaxOpenConns=%d,MaxIdleConns=%d,ConnMaxLifetime=%s,ConnMaxIdleTime=%s]",
+		ps.MaxOpenConns, ps.MaxIdleConns, ps.ConnMaxLifetime, ps.ConnMaxIdleTime)
+}
+
+func (ps PoolSettings) apply(db *sql.DB) {
+	if ps.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(ps.MaxOpenConns)
+	}
+	if ps.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(ps.MaxIdleConns)
+	}
+	if ps.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(ps.ConnMaxLifetime)
+	}
+	if ps.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(ps.ConnMaxIdleTime)
+	}
+}
+
+//readPoolSettings returns the pool settings of Postgres: SQLite allows only one writer and keeps the defaults
+func readPoolSettings() PoolSettings {
+	return PoolSettings{
+		MaxOpenConns:    viper.GetInt("db.postgres.pool.maxOpenConns"),
+		MaxIdleConns:    viper.GetInt("db.postgres.pool.maxIdleConns"),
+		ConnMaxLifetime: viper.GetDuration("db.postgres.pool.connMaxLifetime"),
+		ConnMaxIdleTime: viper.GetDuration("db.postgres.pool.connMaxIdleTime"),
+	}
+}
+
+//connectionPool holds the connection which is shared by all users of a connection factory
+type connectionPool struct {
+	mu   sync.Mutex
+	db   *sql.DB
+	conn Connection
+}
+
+//connection returns the shared connection and opens the pool on first use
+func (p *connectionPool) connection(settings PoolSettings, open func() (*sql.DB, error),
+	newConnection func(db *sql.DB) (Connection, error)) (Connection, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil {
+		return p.conn, nil
+	}
+
+	db, err := open()
+	if err != nil {
+		return nil, err
+	}
+	settings.apply(db)
+	if err := db.Ping(); err != nil { //test connection
+		return nil, closeOnError(db, err)
+	}
+	conn, err := newConnection(db)
+	if err != nil {
+		return nil, closeOnError(db, err)
+	}
+
+	p.db = db
+	p.conn = conn
+	return conn, nil
+}
+
+func closeOnError(db *sql.DB, err error) error {
+	if closeErr := db.Close(); closeErr != nil {
+		return fmt.Errorf("%s (closing database handle failed: %s)", err, closeErr)
+	}
+	return err
+}
+
+func (p *connectionPool) ping() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.db == nil {
+		return fmt.Errorf("connection pool is not opened")
+	}
+	return p.db.Ping()
+}
+
+func (p *connectionPool) stats() sql.DBStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.db == nil {
+		return sql.DBStats{}
+	}
+	return p.db.Stats()
+}
+
+//close closes all connections of the pool: the pool is re-opened when the next connection is requested
+func (p *connectionPool) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.db == nil {
+		return nil
+	}
+	err := p.db.Close()
+	p.db = nil
+	p.conn = nil
+	return err
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionPool(t *testing.T) {
+	key, err := NewEncryptionKey()
+	require.NoError(t, err)
+
+	connFact := &SqliteConnectionFactory{
+		File:          filepath.Join(t.TempDir(), "pool.db"),
+		EncryptionKey: key,
+		Pool: PoolSettings{
+			MaxOpenConns:    3,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: time.Minute,
+			ConnMaxIdleTime: time.Minute,
+		},
+	}
+	require.NoError(t, connFact.Init())
+	defer func() {
+		require.NoError(t, connFact.Close())
+	}()
+
+	t.Run("Pool is opened on first use", func(t *testing.T) {
+		require.Equal(t, 0, connFact.Stats().MaxOpenConnections)
+		require.NoError(t, connFact.Ping())
+		require.Equal(t, 3, connFact.Stats().MaxOpenConnections)
+	})
+
+	t.Run("Connection is shared", func(t *testing.T) {
+		var wg sync.WaitGroup
+		conns := make([]Connection, 10)
+		for i := range conns {
+			wg.Add(1)
+			go func(idx int) {
+				defer wg.Done()
+				conn, err := connFact.NewConnection()
+				require.NoError(t, err)
+				conns[idx] = conn
+			}(i)
+		}
+		wg.Wait()
+		for _, conn := range conns {
+			require.Same(t, conns[0], conn)
+		}
+
+		//closing a shared connection keeps it usable for the other users
+		require.NoError(t, conns[0].Close())
+		_, err := conns[1].Exec("CREATE TABLE IF NOT EXISTS mockTable (col_1 text)")
+		require.NoError(t, err)
+	})
+
+	t.Run("Reset keeps shared connection usable", func(t *testing.T) {
+		conn, err := connFact.NewConnection()
+		require.NoError(t, err)
+		_, err = conn.Exec("CREATE TABLE IF NOT EXISTS mockTable (col_1 text)")
+		require.NoError(t, err)
+
+		connFact.Reset = true
+		defer func() {
+			connFact.Reset = false
+		}()
+		require.NoError(t, connFact.Init())
+
+		//schema was dropped but the connection of existing users is still open
+		_, err = conn.Exec("SELECT col_1 FROM mockTable")
+		require.Error(t, err)
+		_, err = conn.Exec("CREATE TABLE mockTable (col_1 text)")
+		require.NoError(t, err)
+		newConn, err := connFact.NewConnection()
+		require.NoError(t, err)
+		require.Same(t, conn, newConn)
+	})
+
+	t.Run("Close pool", func(t *testing.T) {
+		conn, err := connFact.NewConnection()
+		require.NoError(t, err)
+		require.NoError(t, connFact.Close())
+		require.Equal(t, 0, connFact.Stats().OpenConnections)
+
+		//closed connection is not usable anymore
+		_, err = conn.Exec("SELECT 1")
+		require.Error(t, err)
+
+		//pool is re-opened by the next request
+		newConn, err := connFact.NewConnection()
+		require.NoError(t, err)
+		require.NotSame(t, conn, newConn)
+		_, err = newConn.Exec("SELECT 1")
+		require.NoError(t, err)
+	})
+}
@@ -17,12 +17,12 @@ type PostgresConnection struct {
 	logger    *zap.SugaredLogger
 }
 
-func newPostgresConnection(db *sql.DB, encryptionKey string, debug bool) (*PostgresConnection, error) {
+func newPostgresConnection(db *sql.DB, encryptionKey string, previousEncryptionKeys []string, debug bool) (*PostgresConnection, error) {
 	logger, err := log.NewLogger(debug)
 	if err != nil {
 		return nil, err
 	}
-	encryptor, err := NewEncryptor(encryptionKey)
+	encryptor, err := NewEncryptor(encryptionKey, previousEncryptionKeys...)
 	if err != nil {
 		return nil, err
 	}
@@ -60,14 +60,19 @@ func (pc *PostgresConnection) Exec(query string, args ...interface{}) (sql.Resul
 	return result, err
 }
 
-func (pc *PostgresConnection) Begin() (*sql.Tx, error) {
+func (pc *PostgresConnection) Begin() (*Tx, error) {
 	pc.logger.Debug("Postgres Begin()")
-	return pc.db.Begin()
+	tx, err := pc.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return newTx(tx, pc, pc.logger), nil
 }
 
+//Close is a no-op: the connection is shared by all users of the connection factory which closes it
 func (pc *PostgresConnection) Close() error {
-	pc.logger.Debug("Postgres Close()")
-	return pc.db.Close()
+	pc.logger.Debug("Postgres Close(): shared connection is closed by the connection factory")
+	return nil
 }
 
 func (pc *PostgresConnection) Type() Type {
@@ -75,40 +80,57 @@ func (pc *PostgresConnection) Type() Type {
 }
 
 type PostgresConnectionFactory struct {
-	Host          string
-	Port          int
-	Database      string
-	User          string
-	Password      string
-	SslMode       bool
-	EncryptionKey string
-	Debug         bool
+	Host                   string
+	Port                   int
+	Database               string
+	User                   string
+	Password               string
+	SslMode                bool
+	EncryptionKey          string
+	PreviousEncryptionKeys []string //used to decrypt data which was encrypted before the key was rotated
+	Debug                  bool
+	Pool                   PoolSettings
+	pool                   connectionPool
 }
 
 func (pcf *PostgresConnectionFactory) Init() error {
 	return pcf.checkPostgresIsolationLevel()
 }
 
+//NewConnection returns the connection which is shared by all users of the factory
 func (pcf *PostgresConnectionFactory) NewConnection() (Connection, error) {
 	sslMode := "disable"
 	if pcf.SslMode {
 		sslMode = "require"
 	}
 
-	db, err := sql.Open(
-		"postgres",
-		fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-			pcf.Host, pcf.Port, pcf.User, pcf.Password, pcf.Database, sslMode))
+	return pcf.pool.connection(pcf.Pool,
+		func() (*sql.DB, error) {
+			return sql.Open(
+				"postgres",
+				fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+					pcf.Host, pcf.Port, pcf.User, pcf.Password, pcf.Database, sslMode))
+		},
+		func(db *sql.DB) (Connection, error) {
+			return newPostgresConnection(db, pcf.EncryptionKey, pcf.PreviousEncryptionKeys, pcf.Debug)
+		})
+}
 
-	if err == nil {
-		err = db.Ping()
+//Ping verifies that the database is reachable
+func (pcf *PostgresConnectionFactory) Ping() error {
+	if _, err := pcf.NewConnection(); err != nil {
+		return err
 	}
+	return pcf.pool.ping()
+}
 
-	if err != nil {
-		return nil, err
-	}
+func (pcf *PostgresConnectionFactory) Stats() sql.DBStats {
+	return pcf.pool.stats()
+}
 
-	return newPostgresConnection(db, pcf.EncryptionKey, pcf.Debug)
+//Close closes the shared connection and all pooled database connections
+func (pcf *PostgresConnectionFactory) Close() error {
+	return pcf.pool.close()
 }
 
 func (pcf *PostgresConnectionFactory) checkPostgresIsolationLevel() error {
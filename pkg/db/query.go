@@ -7,12 +7,18 @@ import (
 	"strings"
 )
 
+type Aggregate string
+
+const (
+	AggregateCount Aggregate = "COUNT"
+	AggregateMax   Aggregate = "MAX"
+	AggregateMin   Aggregate = "MIN"
+)
+
 type Query struct {
 	conn          Connection
 	entity        DatabaseEntity
 	columnHandler *ColumnHandler
-	buffer        bytes.Buffer
-	whereClause   bool
 }
 
 func NewQuery(conn Connection, entity DatabaseEntity) (*Query, error) {
@@ -27,142 +33,131 @@ func NewQuery(conn Connection, entity DatabaseEntity) (*Query, error) {
 	}, nil
 }
 
-func (q Query) String() string {
-	return q.buffer.String()
-}
-
 func (q *Query) Select() *Select {
-	q.buffer.WriteString(fmt.Sprintf("SELECT %s FROM %s", q.columnHandler.ColumnNamesCsv(false), q.entity.Table()))
-
-	return &Select{q, []interface{}{}, nil}
+	return &Select{Query: q}
 }
 
 func (q *Query) Insert() *Insert {
-	colValPlcHdr, err := q.columnHandler.ColumnValuesPlaceholderCsv(true)
-
-	q.buffer.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
-		q.entity.Table(), q.columnHandler.ColumnNamesCsv(true), colValPlcHdr, q.columnHandler.ColumnNamesCsv(false)))
-
-	return &Insert{q, err}
+	return &Insert{q}
 }
 
 func (q *Query) Delete() *Delete {
-	q.buffer.WriteString(fmt.Sprintf("DELETE FROM %s", q.entity.Table()))
-
-	return &Delete{q, []interface{}{}, nil}
+	return &Delete{Query: q}
 }
 
 func (q *Query) Update() *Update {
-	colEntriesCsv, plcHdrCnt, err := q.columnHandler.columnEntriesCsvRenderer(true, true)
-
-	q.buffer.WriteString(fmt.Sprintf("UPDATE %s SET %s",
-		q.entity.Table(), colEntriesCsv))
+	return &Update{Query: q}
+}
 
-	return &Update{q, []interface{}{}, plcHdrCnt, err}
+//UpdateMany changes the given fields of all entities which match the WHERE condition.
+//The values are written as they are (encryption or marshalling of the entity is not applied).
+func (q *Query) UpdateMany(fieldValues map[string]interface{}) *UpdateMany {
+	return &UpdateMany{Query: q, fieldValues: fieldValues}
 }
 
 // helper functions:
-func (q *Query) reset() {
-	q.buffer = bytes.Buffer{}
-	q.whereClause = false
+func (q *Query) newRenderer(args ...interface{}) *renderer {
+	return newRenderer(q.conn.Type(), q.columnHandler.ColumnName, args...)
 }
 
-func (q *Query) addWhereCondition(whereCond map[string]interface{}, plcHdrOffset int) ([]interface{}, error) {
-	var args []interface{}
-	var plcHdrIdx int
-
-	if len(whereCond) == 0 {
-		return args, nil
-	}
-
-	//get sort list of fields
-	fields := make([]string, 0, len(whereCond))
-	for field := range whereCond {
-		fields = append(fields, field)
-	}
-	sort.Strings(fields)
-
-	//render WHERE condition
-	q.addWhere()
-	for _, field := range fields {
-		col, err := q.columnHandler.ColumnName(field)
-		if err != nil {
-			return args, err
-		}
-		if plcHdrIdx > 0 {
-			q.buffer.WriteString(" AND")
-		}
-		plcHdrIdx++
-		q.buffer.WriteString(fmt.Sprintf(" %s=$%d", col, plcHdrIdx+plcHdrOffset))
-		args = append(args, whereCond[field])
+func renderWhere(buffer *bytes.Buffer, r *renderer, conds []Condition) error {
+	if len(conds) == 0 {
+		return nil
 	}
-	return args, nil
-}
-
-func (q *Query) addWhereInCondition(field, subQuery string) error {
-	colName, err := q.columnHandler.ColumnName(field)
+	sql, err := r.conditions(conds, "AND")
 	if err != nil {
 		return err
 	}
-	q.addWhere()
-	q.buffer.WriteString(fmt.Sprintf(" %s IN (%s)", colName, subQuery))
+	buffer.WriteString(" WHERE ")
+	buffer.WriteString(sql)
 	return nil
 }
 
-func (q *Query) addWhere() {
-	if q.whereClause {
-		q.buffer.WriteString(" AND")
-	} else {
-		q.buffer.WriteString(" WHERE")
-		q.whereClause = true
-	}
+//subQuery is a raw SQL statement used in a WHERE IN condition
+func subQuery(sql string, args []interface{}) *raw {
+	return &raw{sql, args}
 }
 
 // SELECT:
 type Select struct {
 	*Query
-	args []interface{}
-	err  error
+	columns []*projection
+	joins   []*join
+	conds   []Condition
+	groupBy []string
+	orderBy []string
+	limit   int
+	offset  int
+	err     error
+}
+
+type projection struct {
+	aggregate Aggregate
+	field     string
+}
+
+type join struct {
+	entity        DatabaseEntity
+	columnHandler *ColumnHandler
+	field         string
+	joinField     string
 }
 
 func (s *Select) Where(args map[string]interface{}) *Select {
-	s.args, s.err = s.addWhereCondition(args, 0)
+	s.conds = append(s.conds, equals(args)...)
 	return s
 }
 
-func (s *Select) WhereIn(field, subQuery string, args ...interface{}) *Select {
-	s.err = s.addWhereInCondition(field, subQuery)
-	s.args = args
+//WhereIn matches the field against a raw SQL sub-query: its placeholders have to be numbered $1...$N
+//relative to the passed arguments
+func (s *Select) WhereIn(field, subQuerySQL string, args ...interface{}) *Select {
+	s.conds = append(s.conds, &in{field: field, subQuery: subQuery(subQuerySQL, args)})
 	return s
 }
 
-func (s *Select) GroupBy(args []string) *Select {
-	if len(args) == 0 {
+//WhereCondition adds conditions to the WHERE clause (all conditions have to match)
+func (s *Select) WhereCondition(conds ...Condition) *Select {
+	s.conds = append(s.conds, conds...)
+	return s
+}
+
+//Join adds an inner join with another entity whose joinField has to match the field of the queried entity.
+//Fields of the joined entity can be used in conditions (qualify them with Field() if the name is ambiguous).
+func (s *Select) Join(entity DatabaseEntity, field, joinField string) *Select {
+	columnHandler, err := NewColumnHandler(entity, s.conn)
+	if err != nil {
+		s.err = err
 		return s
 	}
+	s.joins = append(s.joins, &join{
+		entity:        entity,
+		columnHandler: columnHandler,
+		field:         field,
+		joinField:     joinField,
+	})
+	return s
+}
 
-	//get groupings
-	var grouping []string
-	for _, field := range args {
-		col, err := s.columnHandler.ColumnName(field)
-		if err != nil {
-			s.err = err
-			return s
-		}
-		grouping = append(grouping, fmt.Sprintf(" %s", col))
+//Columns restricts the result to the given fields: only usable for sub-queries or Rows()
+func (s *Select) Columns(fields ...string) *Select {
+	for _, field := range fields {
+		s.columns = append(s.columns, &projection{field: field})
 	}
+	return s
+}
 
-	//render group condition
-	s.buffer.WriteString(" GROUP BY")
-	s.buffer.WriteString(strings.Join(grouping, ", "))
+//Aggregate adds an aggregated field to the result (e.g. MAX(id)): only usable for sub-queries or Rows()
+func (s *Select) Aggregate(aggregate Aggregate, field string) *Select {
+	s.columns = append(s.columns, &projection{aggregate: aggregate, field: field})
 	return s
 }
 
-func (s *Select) OrderBy(args map[string]string) *Select {
-	if len(args) == 0 {
-		return s
-	}
+func (s *Select) GroupBy(args []string) *Select {
+	s.groupBy = append(s.groupBy, args...)
+	return s
+}
 
+func (s *Select) OrderBy(args map[string]string) *Select {
 	//get sorted list of fields
 	fields := make([]string, 0, len(args))
 	for field := range args {
@@ -170,49 +165,181 @@ func (s *Select) OrderBy(args map[string]string) *Select {
 	}
 	sort.Strings(fields)
 
-	//get orderings
-	var ordering []string
 	for _, field := range fields {
-		col, err := s.columnHandler.ColumnName(field)
+		s.orderBy = append(s.orderBy, field, args[field])
+	}
+	return s
+}
+
+func (s *Select) Limit(limit int) *Select {
+	s.limit = limit
+	return s
+}
+
+//Offset skips the given number of results (use it in combination with OrderBy to get stable pages)
+func (s *Select) Offset(offset int) *Select {
+	s.offset = offset
+	return s
+}
+
+//column resolves the column name of a field which can be qualified by the table of the queried or a joined entity
+func (s *Select) column(field string) (string, error) {
+	if len(s.joins) == 0 {
+		if strings.HasPrefix(field, s.entity.Table()+".") {
+			field = strings.TrimPrefix(field, s.entity.Table()+".")
+		}
+		return s.columnHandler.ColumnName(field)
+	}
+
+	//qualify column names if entities are joined
+	tables := []string{s.entity.Table()}
+	columnHandlers := []*ColumnHandler{s.columnHandler}
+	for _, join := range s.joins {
+		tables = append(tables, join.entity.Table())
+		columnHandlers = append(columnHandlers, join.columnHandler)
+	}
+	for idx, table := range tables {
+		if !strings.HasPrefix(field, table+".") {
+			continue
+		}
+		col, err := columnHandlers[idx].ColumnName(strings.TrimPrefix(field, table+"."))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s.%s", table, col), nil
+	}
+	for idx, columnHandler := range columnHandlers {
+		if col, err := columnHandler.ColumnName(field); err == nil {
+			return fmt.Sprintf("%s.%s", tables[idx], col), nil
+		}
+	}
+	return "", fmt.Errorf("field '%s' is not defined by any entity of the query (tables: %s)",
+		field, strings.Join(tables, ", "))
+}
+
+func (s *Select) projection() (string, error) {
+	if len(s.columns) == 0 {
+		if len(s.joins) == 0 {
+			return s.columnHandler.ColumnNamesCsv(false), nil
+		}
+		return s.columnHandler.columnNamesCsv(false, s.entity.Table()+"."), nil
+	}
+	var cols []string
+	for _, projection := range s.columns {
+		col, err := s.column(projection.field)
+		if err != nil {
+			return "", err
+		}
+		if projection.aggregate != "" {
+			col = fmt.Sprintf("%s(%s)", projection.aggregate, col)
+		}
+		cols = append(cols, col)
+	}
+	return strings.Join(cols, ", "), nil
+}
+
+func (s *Select) render(r *renderer) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	r = r.withColumns(s.column)
+
+	var buffer bytes.Buffer
+	projection, err := s.projection()
+	if err != nil {
+		return "", err
+	}
+	buffer.WriteString(fmt.Sprintf("SELECT %s FROM %s", projection, s.entity.Table()))
+
+	//render joins
+	for _, join := range s.joins {
+		col, err := s.column(Field(s.entity, join.field))
 		if err != nil {
-			s.err = err
-			return s
+			return "", err
+		}
+		joinCol, err := s.column(Field(join.entity, join.joinField))
+		if err != nil {
+			return "", err
+		}
+		buffer.WriteString(fmt.Sprintf(" INNER JOIN %s ON %s=%s", join.entity.Table(), col, joinCol))
+	}
+
+	if err := renderWhere(&buffer, r, s.conds); err != nil {
+		return "", err
+	}
+
+	//render group condition
+	if len(s.groupBy) > 0 {
+		var grouping []string
+		for _, field := range s.groupBy {
+			col, err := s.column(field)
+			if err != nil {
+				return "", err
+			}
+			grouping = append(grouping, col)
 		}
-		ordering = append(ordering, fmt.Sprintf(" %s %s", col, args[field]))
+		buffer.WriteString(" GROUP BY ")
+		buffer.WriteString(strings.Join(grouping, ", "))
 	}
 
 	//render order condition
-	s.buffer.WriteString(" ORDER BY")
-	s.buffer.WriteString(strings.Join(ordering, ", "))
-	return s
+	if len(s.orderBy) > 0 {
+		var ordering []string
+		for idx := 0; idx < len(s.orderBy); idx += 2 {
+			col, err := s.column(s.orderBy[idx])
+			if err != nil {
+				return "", err
+			}
+			ordering = append(ordering, fmt.Sprintf("%s %s", col, s.orderBy[idx+1]))
+		}
+		buffer.WriteString(" ORDER BY ")
+		buffer.WriteString(strings.Join(ordering, ", "))
+	}
+
+	//render pagination
+	if s.limit > 0 {
+		buffer.WriteString(fmt.Sprintf(" LIMIT %d", s.limit))
+	} else if s.offset > 0 && r.dbType == SQLite {
+		buffer.WriteString(" LIMIT -1") //SQLite supports OFFSET only in combination with LIMIT
+	}
+	if s.offset > 0 {
+		buffer.WriteString(fmt.Sprintf(" OFFSET %d", s.offset))
+	}
+
+	return buffer.String(), nil
 }
 
-func (s *Select) Limit(limit int) *Select {
-	s.buffer.WriteString(fmt.Sprintf(" LIMIT %d", limit))
-	return s
+func (s *Select) sql() (string, []interface{}, error) {
+	if len(s.columns) > 0 {
+		return "", nil, fmt.Errorf("query of entity '%s' selects a subset of columns: "+
+			"this is only supported for sub-queries or raw rows", s.entity)
+	}
+	r := s.newRenderer()
+	sql, err := s.render(r)
+	return sql, *r.args, err
 }
 
 func (s *Select) GetOne() (DatabaseEntity, error) {
-	if s.err != nil {
-		return nil, s.err
+	sql, args, err := s.sql()
+	if err != nil {
+		return nil, err
 	}
-	defer s.reset()
-	row := s.conn.QueryRow(s.buffer.String(), s.args...)
+	row := s.conn.QueryRow(sql, args...)
 	return s.entity, s.columnHandler.Unmarshal(row, s.entity)
 }
 
 func (s *Select) GetMany() ([]DatabaseEntity, error) {
-	if s.err != nil {
-		return nil, s.err
+	sql, args, err := s.sql()
+	if err != nil {
+		return nil, err
 	}
 
-	defer s.reset()
-
 	//get results
-	rows, err := s.conn.Query(s.buffer.String(), s.args...)
+	rows, err := s.conn.Query(sql, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	//create entities
 	var result []DatabaseEntity
@@ -227,84 +354,183 @@ func (s *Select) GetMany() ([]DatabaseEntity, error) {
 		}
 		result = append(result, entity)
 	}
-	return result, nil
+	return result, rows.Err()
+}
+
+//Count returns the number of results of the query
+func (s *Select) Count() (int64, error) {
+	sql, args, err := s.sql()
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = s.conn.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS result", sql), args...).Scan(&count)
+	return count, err
+}
+
+//Rows returns the raw result rows of a query which selects a subset of columns (see Columns() and Aggregate()).
+//The rows have to be closed by the caller.
+func (s *Select) Rows() (DataRows, error) {
+	if len(s.columns) == 0 {
+		return nil, fmt.Errorf("query of entity '%s' defines no columns: raw rows are only supported "+
+			"for a subset of columns", s.entity)
+	}
+	r := s.newRenderer()
+	sql, err := s.render(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.conn.Query(sql, *r.args...)
 }
 
 // INSERT:
 type Insert struct {
 	*Query
-	err error
 }
 
 func (i *Insert) Exec() error {
-	defer i.reset()
 	if err := i.columnHandler.Validate(); err != nil {
 		return err
 	}
+	colValPlcHdr, err := i.columnHandler.ColumnValuesPlaceholderCsv(true)
+	if err != nil {
+		return err
+	}
 	colVals, err := i.columnHandler.ColumnValues(true)
 	if err != nil {
 		return err
 	}
-	row := i.conn.QueryRow(i.buffer.String(), colVals...)
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
+		i.entity.Table(), i.columnHandler.ColumnNamesCsv(true), colValPlcHdr, i.columnHandler.ColumnNamesCsv(false))
+	row := i.conn.QueryRow(sql, colVals...)
 	return i.columnHandler.Unmarshal(row, i.entity)
 }
 
 // DELETE:
 type Delete struct {
 	*Query
-	args []interface{}
-	err  error
+	conds []Condition
 }
 
 func (d *Delete) Where(args map[string]interface{}) *Delete {
-	d.args, d.err = d.addWhereCondition(args, 0)
+	d.conds = append(d.conds, equals(args)...)
+	return d
+}
+
+//WhereIn matches the field against a raw SQL sub-query: its placeholders have to be numbered $1...$N
+//relative to the passed arguments
+func (d *Delete) WhereIn(field, subQuerySQL string, args ...interface{}) *Delete {
+	d.conds = append(d.conds, &in{field: field, subQuery: subQuery(subQuerySQL, args)})
+	return d
+}
+
+//WhereCondition adds conditions to the WHERE clause (all conditions have to match)
+func (d *Delete) WhereCondition(conds ...Condition) *Delete {
+	d.conds = append(d.conds, conds...)
 	return d
 }
 
 func (d *Delete) Exec() (int64, error) {
-	if d.err != nil {
-		return 0, d.err
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("DELETE FROM %s", d.entity.Table()))
+	r := d.newRenderer()
+	if err := renderWhere(&buffer, r, d.conds); err != nil {
+		return 0, err
 	}
-	defer d.reset()
-	res, err := d.conn.Exec(d.buffer.String(), d.args...)
+	res, err := d.conn.Exec(buffer.String(), *r.args...)
 	if err == nil {
 		return res.RowsAffected()
 	}
 	return 0, err
 }
 
-func (d *Delete) WhereIn(field, subQuery string, args ...interface{}) *Delete {
-	d.err = d.addWhereInCondition(field, subQuery)
-	d.args = args
-	return d
-}
-
 // UPDATE:
 type Update struct {
 	*Query
-	args              []interface{}
-	placeholderOffset int
-	err               error
+	conds []Condition
 }
 
 func (u *Update) Where(args map[string]interface{}) *Update {
-	u.args, u.err = u.addWhereCondition(args, u.placeholderOffset)
+	u.conds = append(u.conds, equals(args)...)
+	return u
+}
+
+//WhereCondition adds conditions to the WHERE clause (all conditions have to match)
+func (u *Update) WhereCondition(conds ...Condition) *Update {
+	u.conds = append(u.conds, conds...)
 	return u
 }
 
 func (u *Update) Exec() error {
-	defer u.reset()
 	if err := u.columnHandler.Validate(); err != nil {
 		return err
 	}
-
-	//finalize query by appending RETURNING
-	u.buffer.WriteString(fmt.Sprintf(" RETURNING %s", u.columnHandler.ColumnNamesCsv(false)))
-
+	colEntriesCsv, _, err := u.columnHandler.columnEntriesCsvRenderer(true, true)
+	if err != nil {
+		return err
+	}
 	colVals, err := u.columnHandler.ColumnValues(true)
 	if err != nil {
 		return err
 	}
-	row := u.conn.QueryRow(u.buffer.String(), colVals...)
+
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("UPDATE %s SET %s", u.entity.Table(), colEntriesCsv))
+	r := u.newRenderer(colVals...)
+	if err := renderWhere(&buffer, r, u.conds); err != nil {
+		return err
+	}
+
+	//finalize query by appending RETURNING
+	buffer.WriteString(fmt.Sprintf(" RETURNING %s", u.columnHandler.ColumnNamesCsv(false)))
+
+	row := u.conn.QueryRow(buffer.String(), *r.args...)
 	return u.columnHandler.Unmarshal(row, u.entity)
 }
+
+// UPDATE MANY:
+type UpdateMany struct {
+	*Query
+	fieldValues map[string]interface{}
+	conds       []Condition
+}
+
+func (u *UpdateMany) Where(args map[string]interface{}) *UpdateMany {
+	u.conds = append(u.conds, equals(args)...)
+	return u
+}
+
+//WhereCondition adds conditions to the WHERE clause (all conditions have to match)
+func (u *UpdateMany) WhereCondition(conds ...Condition) *UpdateMany {
+	u.conds = append(u.conds, conds...)
+	return u
+}
+
+//Exec updates the matching entities and returns the number of affected rows
+func (u *UpdateMany) Exec() (int64, error) {
+	if len(u.fieldValues) == 0 {
+		return 0, fmt.Errorf("no fields to update defined for entity '%s'", u.entity)
+	}
+
+	//render SET clause (an assignment is rendered like an equal condition)
+	r := u.newRenderer()
+	var assignments []string
+	for _, assignment := range equals(u.fieldValues) {
+		sql, err := assignment.render(r)
+		if err != nil {
+			return 0, err
+		}
+		assignments = append(assignments, sql)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("UPDATE %s SET %s", u.entity.Table(), strings.Join(assignments, ", ")))
+	if err := renderWhere(&buffer, r, u.conds); err != nil {
+		return 0, err
+	}
+	res, err := u.conn.Exec(buffer.String(), *r.args...)
+	if err == nil {
+		return res.RowsAffected()
+	}
+	return 0, err
+}
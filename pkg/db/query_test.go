@@ -65,4 +65,136 @@ func TestQuery(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, "UPDATE mockTable SET col_1=$1, col_3=$2 WHERE col_1=$3 RETURNING col_1, col_2, col_3", conn.query)
 	})
+
+	t.Run("Select with conditions", func(t *testing.T) {
+		_, err := q.Select().
+			Where(map[string]interface{}{"Col2": true}).
+			WhereCondition(
+				Or(Eq("Col1", "a"), Lt("Col3", 5)),
+				Like("Col1", `b\_%`),
+				In("Col3", 1, 2),
+				Not(Ge("Col3", 10))).
+			GetMany()
+		require.NoError(t, err)
+		require.Equal(t, `SELECT col_1, col_2, col_3 FROM mockTable WHERE col_2=$1 AND (col_1=$2 OR col_3<$3) AND `+
+			`col_1 LIKE $4 ESCAPE '\' AND col_3 IN ($5, $6) AND NOT (col_3>=$7)`, conn.query)
+		require.Equal(t, []interface{}{true, "a", 5, `b\_%`, 1, 2, 10}, conn.args)
+	})
+
+	t.Run("Select with empty conditions", func(t *testing.T) {
+		_, err := q.Select().WhereCondition(In("Col1"), Or(), And()).GetMany()
+		require.NoError(t, err)
+		require.Equal(t, "SELECT col_1, col_2, col_3 FROM mockTable WHERE 1=0 AND 1=0 AND 1=1", conn.query)
+	})
+
+	t.Run("Select with raw conditions", func(t *testing.T) {
+		_, err := q.Select().
+			Where(map[string]interface{}{"Col2": true}).
+			WhereIn("Col1", "SELECT col FROM table WHERE x=$2 AND y=$1", "y", "x").
+			WhereCondition(Raw("col_3>$1", 3)).
+			GetMany()
+		require.NoError(t, err)
+		require.Equal(t, "SELECT col_1, col_2, col_3 FROM mockTable WHERE col_2=$1 AND "+
+			"col_1 IN (SELECT col FROM table WHERE x=$2 AND y=$3) AND col_3>$4", conn.query)
+		require.Equal(t, []interface{}{true, "x", "y", 3}, conn.args)
+
+		_, err = q.Select().WhereCondition(Raw("col_3>$2", 3)).GetMany()
+		require.Error(t, err)
+	})
+
+	t.Run("Select with sub-query and pagination", func(t *testing.T) {
+		subQ, err := NewQuery(conn, &MockDbEntity{})
+		require.NoError(t, err)
+		_, err = q.Select().
+			WhereCondition(
+				Eq("Col2", true),
+				InQuery("Col3", subQ.Select().
+					Aggregate(AggregateMax, "Col3").
+					WhereCondition(Gt("Col3", 1)).
+					GroupBy([]string{"Col1"}))).
+			OrderBy(map[string]string{"Col1": "ASC"}).
+			Limit(10).
+			Offset(20).
+			GetMany()
+		require.NoError(t, err)
+		require.Equal(t, "SELECT col_1, col_2, col_3 FROM mockTable WHERE col_2=$1 AND "+
+			"col_3 IN (SELECT MAX(col_3) FROM mockTable WHERE col_3>$2 GROUP BY col_1) ORDER BY col_1 ASC LIMIT 10 OFFSET 20", conn.query)
+		require.Equal(t, []interface{}{true, 1}, conn.args)
+
+		_, err = subQ.Select().Columns("Col1").GetMany()
+		require.Error(t, err) //selecting a subset of columns is only allowed in sub-queries
+	})
+
+	t.Run("Select with join", func(t *testing.T) {
+		_, err := q.Select().
+			Join(&mockJoinedEntity{}, "Col1", "Col1").
+			WhereCondition(Eq("Col4", 4), Eq(Field(&mockJoinedEntity{}, "Col1"), "a"), Eq("Col2", true)).
+			GetMany()
+		require.NoError(t, err)
+		require.Equal(t, "SELECT mockTable.col_1, mockTable.col_2, mockTable.col_3 FROM mockTable "+
+			"INNER JOIN mockJoinedTable ON mockTable.col_1=mockJoinedTable.col_1 WHERE mockJoinedTable.col_4=$1 AND "+
+			"mockJoinedTable.col_1=$2 AND mockTable.col_2=$3", conn.query)
+
+		_, err = q.Select().Join(&mockJoinedEntity{}, "Col1", "Col1").WhereCondition(Eq("Col5", 5)).GetMany()
+		require.Error(t, err)
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		_, err := q.Select().Where(map[string]interface{}{"Col1": "a"}).Count()
+		require.NoError(t, err)
+		require.Equal(t, "SELECT COUNT(*) FROM (SELECT col_1, col_2, col_3 FROM mockTable WHERE col_1=$1) AS result", conn.query)
+		require.Equal(t, []interface{}{"a"}, conn.args)
+	})
+
+	t.Run("Rows", func(t *testing.T) {
+		rows, err := q.Select().
+			Columns("Col1").
+			Aggregate(AggregateMax, "Col3").
+			WhereCondition(Like("Col1", `a\_%`)).
+			GroupBy([]string{"Col1"}).
+			Rows()
+		require.NoError(t, err)
+		require.NoError(t, rows.Close())
+		require.Equal(t, `SELECT col_1, MAX(col_3) FROM mockTable WHERE col_1 LIKE $1 ESCAPE '\' GROUP BY col_1`, conn.query)
+		require.Equal(t, []interface{}{`a\_%`}, conn.args)
+
+		_, err = q.Select().Rows()
+		require.Error(t, err) //raw rows are only supported for a subset of columns
+	})
+
+	t.Run("Update many", func(t *testing.T) {
+		affected, err := q.UpdateMany(map[string]interface{}{"Col1": "b", "Col2": false}).
+			WhereCondition(Or(Eq("Col1", "a"), Eq("Col1", "b"))).
+			Exec()
+		require.NoError(t, err)
+		require.Equal(t, MockRowsAffected, affected)
+		require.Equal(t, "UPDATE mockTable SET col_1=$1, col_2=$2 WHERE (col_1=$3 OR col_1=$4)", conn.query)
+		require.Equal(t, []interface{}{"b", false, "a", "b"}, conn.args)
+	})
+}
+
+func TestPlaceholder(t *testing.T) {
+	require.Equal(t, "$3", Placeholder(Postgres, 3))
+	require.Equal(t, "?3", Placeholder(SQLite, 3))
+}
+
+type mockJoinedEntity struct {
+	Col1 string
+	Col4 int
+}
+
+func (e *mockJoinedEntity) New() DatabaseEntity {
+	return &mockJoinedEntity{}
+}
+
+func (e *mockJoinedEntity) Table() string {
+	return "mockJoinedTable"
+}
+
+func (e *mockJoinedEntity) Equal(other DatabaseEntity) bool {
+	return false
+}
+
+func (e *mockJoinedEntity) Marshaller() *EntityMarshaller {
+	return NewEntityMarshaller(&e)
 }
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const defaultRotationBatchSize = 100
+
+//EncryptedColumn defines a column which contains data encrypted by the Encryptor
+type EncryptedColumn struct {
+	Entity  DatabaseEntity //entity of the table
+	Field   string         //field of the entity which is stored encrypted
+	IDField string         //field of the entity which identifies a row uniquely
+}
+
+func (ec *EncryptedColumn) String() string {
+	return fmt.Sprintf("EncryptedColumn [Table=%s,Field=%s]", ec.Entity.Table(), ec.Field)
+}
+
+//RotationProgress reports the progress of the key rotation of an encrypted column
+type RotationProgress struct {
+	Table  string
+	Column string
+	Total  int
+	Done   int
+}
+
+func (rp *RotationProgress) String() string {
+	return fmt.Sprintf("RotationProgress [Table=%s,Column=%s,Done=%d,Total=%d]", rp.Table, rp.Column, rp.Done, rp.Total)
+}
+
+//KeyRotation encrypts the data of encrypted columns with the active key
+type KeyRotation struct {
+	conn      Connection
+	logger    *zap.SugaredLogger
+	batchSize int
+	progress  func(progress *RotationProgress)
+}
+
+func NewKeyRotation(conn Connection, logger *zap.SugaredLogger) *KeyRotation {
+	return &KeyRotation{
+		conn:      conn,
+		logger:    logger,
+		batchSize: defaultRotationBatchSize,
+	}
+}
+
+//WithBatchSize defines how many rows are re-encrypted within one transaction
+func (kr *KeyRotation) WithBatchSize(batchSize int) *KeyRotation {
+	if batchSize > 0 {
+		kr.batchSize = batchSize
+	}
+	return kr
+}
+
+//WithProgress registers a callback which is called after each processed batch
+func (kr *KeyRotation) WithProgress(progress func(progress *RotationProgress)) *KeyRotation {
+	kr.progress = progress
+	return kr
+}
+
+//Rotate re-encrypts all values of the columns which were encrypted with a previous key
+func (kr *KeyRotation) Rotate(columns ...*EncryptedColumn) error {
+	encryptor := kr.conn.Encryptor()
+	previousKeyIDs := encryptor.PreviousKeyIDs()
+	if len(previousKeyIDs) == 0 {
+		kr.logger.Infof("No previous encryption keys found: nothing to rotate")
+		return nil
+	}
+	for _, column := range columns {
+		//select only rows which are encrypted with a previous key (identified by the key ID prefix)
+		if err := kr.process(column, Or(keyIDConditions(column, previousKeyIDs)...), encryptor.Reencrypt); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("key rotation of %s failed", column))
+		}
+	}
+	return nil
+}
+
+//Encrypt encrypts all values of the columns which are not encrypted yet (e.g. data which was stored
+//before the column was marked as encrypted). Values which look like data encrypted with an unknown key
+//are refused: encrypting them again would make them unrecoverable.
+func (kr *KeyRotation) Encrypt(columns ...*EncryptedColumn) error {
+	encryptor := kr.conn.Encryptor()
+	keyIDs := append([]string{encryptor.KeyID()}, encryptor.PreviousKeyIDs()...)
+	encrypt := func(value string) (string, error) {
+		if looksEncrypted(value) {
+			return "", fmt.Errorf("value is encrypted with an unknown key (key ID '%s'): "+
+				"configure the key as previous encryption key", value[:keyIDLength])
+		}
+		return encryptor.Encrypt(value)
+	}
+	for _, column := range columns {
+		//select only rows which are not prefixed by a known key ID
+		var conds []Condition
+		for _, cond := range keyIDConditions(column, keyIDs) {
+			conds = append(conds, Not(cond))
+		}
+		if err := kr.process(column, And(conds...), encrypt); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("encryption of %s failed", column))
+		}
+	}
+	return nil
+}
+
+//keyIDConditions returns a condition per key ID which matches values prefixed by the key ID
+func keyIDConditions(column *EncryptedColumn, keyIDs []string) []Condition {
+	conds := make([]Condition, 0, len(keyIDs))
+	for _, keyID := range keyIDs {
+		conds = append(conds, Like(column.Field, keyID+"%")) //key IDs are hex strings: no wildcards to escape
+	}
+	return conds
+}
+
+func (kr *KeyRotation) process(column *EncryptedColumn, cond Condition, convert func(value string) (string, error)) error {
+	query, err := NewQuery(kr.conn, column.Entity)
+	if err != nil {
+		return err
+	}
+	colName, err := query.columnHandler.ColumnName(column.Field)
+	if err != nil {
+		return err
+	}
+
+	progress := &RotationProgress{
+		Table:  column.Entity.Table(),
+		Column: colName,
+	}
+	total, err := query.Select().WhereCondition(cond).Count()
+	if err != nil {
+		return err
+	}
+	progress.Total = int(total)
+	if progress.Total == 0 {
+		kr.logger.Debugf("All values of column '%s' in table '%s' are encrypted with the active key", colName, progress.Table)
+		return nil
+	}
+	kr.logger.Infof("Encrypting %d values of column '%s' in table '%s' with the active key", progress.Total, colName, progress.Table)
+
+	for {
+		//converted rows don't match the WHERE condition anymore: the next batch can always be selected from the beginning
+		batch, err := kr.nextBatch(query.Select().
+			Columns(column.IDField, column.Field).
+			WhereCondition(cond).
+			OrderBy(map[string]string{column.IDField: "ASC"}).
+			Limit(kr.batchSize))
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		dbOps := func(tx *Tx) error {
+			txQuery, err := NewQuery(tx, column.Entity)
+			if err != nil {
+				return err
+			}
+			for id, value := range batch {
+				encValue, err := convert(value)
+				if err != nil {
+					return errors.Wrap(err, fmt.Sprintf("failed to encrypt value with ID '%v'", id))
+				}
+				rowsAffected, err := txQuery.UpdateMany(map[string]interface{}{column.Field: encValue}).
+					Where(map[string]interface{}{column.IDField: id}).
+					Exec()
+				if err != nil {
+					return err
+				}
+				if rowsAffected != 1 {
+					return fmt.Errorf("update of value with ID '%v' affected %d rows but expected was 1", id, rowsAffected)
+				}
+			}
+			return nil
+		}
+		if err := Transaction(kr.conn, dbOps, kr.logger); err != nil {
+			return err
+		}
+
+		progress.Done += len(batch)
+		if kr.progress != nil {
+			kr.progress(progress)
+		}
+	}
+	return nil
+}
+
+func (kr *KeyRotation) nextBatch(query *Select) (map[interface{}]string, error) {
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	batch := make(map[interface{}]string, kr.batchSize)
+	for rows.Next() {
+		var id interface{}
+		var value string
+		if err := rows.Scan(&id, &value); err != nil {
+			return nil, err
+		}
+		batch[id] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return batch, rows.Close() //release the connection before the batch gets updated
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, err := NewEncryptionKey()
+	require.NoError(t, err)
+	newKey, err := NewEncryptionKey()
+	require.NoError(t, err)
+
+	dbFile := filepath.Join(t.TempDir(), "rotation.db")
+	oldConn := newRotationTestConnection(t, dbFile, oldKey)
+	_, err = oldConn.Exec("CREATE TABLE mockTable (col_1 text, col_2 boolean, col_3 integer PRIMARY KEY)")
+	require.NoError(t, err)
+
+	//5 values encrypted with the old key
+	for i := 1; i <= 5; i++ {
+		encData, err := oldConn.Encryptor().Encrypt(data)
+		require.NoError(t, err)
+		_, err = oldConn.Exec("INSERT INTO mockTable (col_1, col_2, col_3) VALUES ($1, $2, $3)", encData, false, i)
+		require.NoError(t, err)
+	}
+
+	newConn := newRotationTestConnection(t, dbFile, newKey, oldKey)
+
+	//1 value encrypted with the new key
+	encData, err := newConn.Encryptor().Encrypt(data)
+	require.NoError(t, err)
+	_, err = newConn.Exec("INSERT INTO mockTable (col_1, col_2, col_3) VALUES ($1, $2, $3)", encData, false, 6)
+	require.NoError(t, err)
+
+	t.Run("Rotate in batches", func(t *testing.T) {
+		var progresses []RotationProgress
+		err := NewKeyRotation(newConn, logger.NewOptionalLogger(true)).
+			WithBatchSize(2).
+			WithProgress(func(progress *RotationProgress) {
+				progresses = append(progresses, *progress)
+			}).
+			Rotate(&EncryptedColumn{Entity: &MockDbEntity{}, Field: "Col1", IDField: "Col3"})
+		require.NoError(t, err)
+
+		require.Equal(t, []RotationProgress{
+			{Table: "mockTable", Column: "col_1", Total: 5, Done: 2},
+			{Table: "mockTable", Column: "col_1", Total: 5, Done: 4},
+			{Table: "mockTable", Column: "col_1", Total: 5, Done: 5},
+		}, progresses)
+
+		//all values are decryptable without previous keys
+		enc, err := NewEncryptor(newKey)
+		require.NoError(t, err)
+		rows, err := newConn.Query("SELECT col_1 FROM mockTable")
+		require.NoError(t, err)
+		var count int
+		for rows.Next() {
+			var encData string
+			require.NoError(t, rows.Scan(&encData))
+			require.True(t, enc.EncryptedWithActiveKey(encData))
+			decData, err := enc.Decrypt(encData)
+			require.NoError(t, err)
+			require.Equal(t, data, decData)
+			count++
+		}
+		require.Equal(t, 6, count)
+	})
+
+	t.Run("Rotate again", func(t *testing.T) {
+		var progresses []RotationProgress
+		err := NewKeyRotation(newConn, logger.NewOptionalLogger(true)).
+			WithProgress(func(progress *RotationProgress) {
+				progresses = append(progresses, *progress)
+			}).
+			Rotate(&EncryptedColumn{Entity: &MockDbEntity{}, Field: "Col1", IDField: "Col3"})
+		require.NoError(t, err)
+		require.Empty(t, progresses)
+	})
+
+	t.Run("Encrypt plaintext values", func(t *testing.T) {
+		_, err = newConn.Exec("INSERT INTO mockTable (col_1, col_2, col_3) VALUES ($1, $2, $3)", data, false, 7)
+		require.NoError(t, err)
+
+		var progresses []RotationProgress
+		err := NewKeyRotation(newConn, logger.NewOptionalLogger(true)).
+			WithProgress(func(progress *RotationProgress) {
+				progresses = append(progresses, *progress)
+			}).
+			Encrypt(&EncryptedColumn{Entity: &MockDbEntity{}, Field: "Col1", IDField: "Col3"})
+		require.NoError(t, err)
+		require.Equal(t, []RotationProgress{
+			{Table: "mockTable", Column: "col_1", Total: 1, Done: 1},
+		}, progresses)
+
+		var encData string
+		require.NoError(t, newConn.QueryRow("SELECT col_1 FROM mockTable WHERE col_3=$1", 7).Scan(&encData))
+		require.True(t, newConn.Encryptor().EncryptedWithActiveKey(encData))
+		decData, err := newConn.Encryptor().Decrypt(encData)
+		require.NoError(t, err)
+		require.Equal(t, data, decData)
+	})
+
+	t.Run("Refuse values encrypted with unknown key", func(t *testing.T) {
+		unknownKey, err := NewEncryptionKey()
+		require.NoError(t, err)
+		unknownEncryptor, err := NewEncryptor(unknownKey)
+		require.NoError(t, err)
+		encData, err := unknownEncryptor.Encrypt(data)
+		require.NoError(t, err)
+		_, err = newConn.Exec("INSERT INTO mockTable (col_1, col_2, col_3) VALUES ($1, $2, $3)", encData, false, 8)
+		require.NoError(t, err)
+
+		err = NewKeyRotation(newConn, logger.NewOptionalLogger(true)).
+			Encrypt(&EncryptedColumn{Entity: &MockDbEntity{}, Field: "Col1", IDField: "Col3"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), unknownEncryptor.KeyID())
+
+		//value is untouched
+		var storedData string
+		require.NoError(t, newConn.QueryRow("SELECT col_1 FROM mockTable WHERE col_3=$1", 8).Scan(&storedData))
+		require.Equal(t, encData, storedData)
+	})
+
+	t.Run("Rotate unknown field", func(t *testing.T) {
+		err := NewKeyRotation(newConn, logger.NewOptionalLogger(true)).
+			Rotate(&EncryptedColumn{Entity: &MockDbEntity{}, Field: "DoesNotExist", IDField: "Col3"})
+		require.Error(t, err)
+	})
+}
+
+func newRotationTestConnection(t *testing.T, dbFile, key string, previousKeys ...string) Connection {
+	connFact := &SqliteConnectionFactory{
+		File:                   dbFile,
+		EncryptionKey:          key,
+		PreviousEncryptionKeys: previousKeys,
+	}
+	require.NoError(t, connFact.Init())
+	conn, err := connFact.NewConnection()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, connFact.Close())
+	})
+	return conn
+}
@@ -2,8 +2,8 @@ package db
 
 import (
 	"database/sql"
-	"io/ioutil"
-	"os"
+	"fmt"
+	"strings"
 
 	log "github.com/kyma-incubator/reconciler/pkg/logger"
 
@@ -19,12 +19,12 @@ type SqliteConnection struct {
 	logger    *zap.SugaredLogger
 }
 
-func newSqliteConnection(db *sql.DB, encKey string, debug bool) (*SqliteConnection, error) {
+func newSqliteConnection(db *sql.DB, encKey string, previousEncKeys []string, debug bool) (*SqliteConnection, error) {
 	logger, err := log.NewLogger(debug)
 	if err != nil {
 		return nil, err
 	}
-	encryptor, err := NewEncryptor(encKey)
+	encryptor, err := NewEncryptor(encKey, previousEncKeys...)
 	if err != nil {
 		return nil, err
 	}
@@ -62,14 +62,19 @@ func (sc *SqliteConnection) Exec(query string, args ...interface{}) (sql.Result,
 	return result, err
 }
 
-func (sc *SqliteConnection) Begin() (*sql.Tx, error) {
+func (sc *SqliteConnection) Begin() (*Tx, error) {
 	sc.logger.Debug("Sqlite3 Begin()")
-	return sc.db.Begin()
+	tx, err := sc.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return newTx(tx, sc, sc.logger), nil
 }
 
+//Close is a no-op: the connection is shared by all users of the connection factory which closes it
 func (sc *SqliteConnection) Close() error {
-	sc.logger.Debug("Sqlite3 Close()")
-	return sc.db.Close()
+	sc.logger.Debug("Sqlite3 Close(): shared connection is closed by the connection factory")
+	return nil
 }
 
 func (sc *SqliteConnection) Type() Type {
@@ -77,64 +82,100 @@ func (sc *SqliteConnection) Type() Type {
 }
 
 type SqliteConnectionFactory struct {
-	File          string
-	Debug         bool
-	Reset         bool
-	SchemaFile    string
-	EncryptionKey string
+	File                   string
+	Debug                  bool
+	Reset                  bool
+	DeploySchema           bool //apply pending schema migrations during initialization
+	EncryptionKey          string
+	PreviousEncryptionKeys []string //used to decrypt data which was encrypted before the key was rotated
+	Pool                   PoolSettings
+	pool                   connectionPool
 }
 
 func (scf *SqliteConnectionFactory) Init() error {
 	if scf.Reset {
-		if err := scf.resetFile(); err != nil {
+		if err := scf.resetSchema(); err != nil {
 			return err
 		}
 	}
-	if scf.SchemaFile != "" {
-		//read DDL (test-table structure)
-		ddl, err := ioutil.ReadFile(scf.SchemaFile)
-		if err != nil {
-			return err
-		}
-
-		//get connection
-		conn, err := scf.NewConnection()
-		if err != nil {
-			return err
-		}
-
-		//populate DB schema
-		_, err = conn.Exec(string(ddl))
-		return err
+	if scf.DeploySchema {
+		return scf.migrate()
 	}
 	return nil
 }
 
-func (scf *SqliteConnectionFactory) NewConnection() (Connection, error) {
-	db, err := sql.Open("sqlite3", scf.File) //establish connection
+func (scf *SqliteConnectionFactory) migrate() error {
+	conn, err := scf.NewConnection()
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	err = db.Ping() //test connection
+	migrator, err := NewMigrator(conn, log.NewOptionalLogger(scf.Debug))
 	if err != nil {
-		return nil, err
+		return err
+	}
+	_, err = migrator.Up()
+	return err
+}
+
+//NewConnection returns the connection which is shared by all users of the factory
+func (scf *SqliteConnectionFactory) NewConnection() (Connection, error) {
+	return scf.pool.connection(scf.Pool,
+		func() (*sql.DB, error) {
+			return sql.Open("sqlite3", scf.File)
+		},
+		func(db *sql.DB) (Connection, error) {
+			return newSqliteConnection(db, scf.EncryptionKey, scf.PreviousEncryptionKeys, scf.Debug)
+		})
+}
+
+//Ping verifies that the database is reachable
+func (scf *SqliteConnectionFactory) Ping() error {
+	if _, err := scf.NewConnection(); err != nil {
+		return err
 	}
+	return scf.pool.ping()
+}
+
+func (scf *SqliteConnectionFactory) Stats() sql.DBStats {
+	return scf.pool.stats()
+}
 
-	return newSqliteConnection(db, scf.EncryptionKey, scf.Debug) //connection ready to use
+//Close closes the shared connection and all pooled database connections
+func (scf *SqliteConnectionFactory) Close() error {
+	return scf.pool.close()
 }
 
-func (scf *SqliteConnectionFactory) resetFile() error {
-	if err := os.Remove(scf.File); err != nil && !os.IsNotExist(err) {
-		//errors are ok if file was missing, but other errors are not expected
+//resetSchema drops all tables and views through the shared connection: replacing the database file would
+//invalidate the connection held by the users of the factory
+func (scf *SqliteConnectionFactory) resetSchema() error {
+	conn, err := scf.NewConnection()
+	if err != nil {
 		return err
 	}
-	file, err := os.Create(scf.File)
+	rows, err := conn.Query("SELECT type, name FROM sqlite_master WHERE type IN ('view', 'table') " +
+		"AND name NOT LIKE 'sqlite_%' ORDER BY type DESC") //drop views before their tables
 	if err != nil {
 		return err
 	}
-	if err := file.Close(); err != nil {
+	defer rows.Close()
+	var drops []string
+	for rows.Next() {
+		var objType, name string
+		if err := rows.Scan(&objType, &name); err != nil {
+			return err
+		}
+		drops = append(drops, fmt.Sprintf("DROP %s IF EXISTS %s", strings.ToUpper(objType), name))
+	}
+	if err := rows.Err(); err != nil {
 		return err
 	}
+	if err := rows.Close(); err != nil { //release the connection before the schema gets changed
+		return err
+	}
+	for _, drop := range drops {
+		if _, err := conn.Exec(drop); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -5,6 +5,8 @@ import (
 	"path"
 
 	file "github.com/kyma-incubator/reconciler/pkg/files"
+	log "github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/spf13/viper"
 )
 
 func NewTestConnectionFactory() (ConnectionFactory, error) {
@@ -19,6 +21,36 @@ func NewTestConnectionFactory() (ConnectionFactory, error) {
 	return connFac, connFac.Init()
 }
 
+//NewTestPostgresConnectionFactory returns a factory for the Postgres database configured in the unittest
+//configuration file (its settings can be overwritten by the DATABASE_* environment variables).
+//Pending schema migrations are applied to the database.
+func NewTestPostgresConnectionFactory() (ConnectionFactory, error) {
+	configDir, err := resolveConfigsDir()
+	if err != nil {
+		return nil, err
+	}
+	viper.SetConfigFile(path.Join(configDir, "reconciler-unittest.yaml"))
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	connFac, err := newConnectionFactory("postgres", true)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := connFac.NewConnection()
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := NewMigrator(conn, log.NewOptionalLogger(true))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := migrator.Up(); err != nil {
+		return nil, err
+	}
+	return connFac, nil
+}
+
 func resolveConfigsDir() (string, error) {
 	configsDir := path.Join("..", "..", "configs")
 	for i := 0; i < 2; i++ {
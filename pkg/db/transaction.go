@@ -7,7 +7,9 @@ import (
 	"go.uber.org/zap"
 )
 
-func TransactionResult(conn Connection, dbOps func() (interface{}, error), logger *zap.SugaredLogger) (interface{}, error) {
+//TransactionResult executes the DB operations within a transaction: all statements of the DB operations have to be
+//executed by the passed transaction. If the connection is already a transaction, a nested transaction is used.
+func TransactionResult(conn Connection, dbOps func(tx *Tx) (interface{}, error), logger *zap.SugaredLogger) (interface{}, error) {
 	log := func(msg string) {
 		if logger != nil {
 			logger.Debug(msg)
@@ -19,11 +21,11 @@ func TransactionResult(conn Connection, dbOps func() (interface{}, error), logge
 		return nil, err
 	}
 
-	result, err := dbOps()
+	result, err := dbOps(tx)
 	if err != nil {
 		log("Rollback transactional DB context")
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			err = errors.Wrap(err, fmt.Sprintf("Rollback of db operations failed: %s", tx.Rollback()))
+			err = errors.Wrap(err, fmt.Sprintf("Rollback of db operations failed: %s", rollbackErr))
 		}
 		return result, err
 	}
@@ -32,9 +34,9 @@ func TransactionResult(conn Connection, dbOps func() (interface{}, error), logge
 	return result, tx.Commit()
 }
 
-func Transaction(conn Connection, dbOps func() error, logger *zap.SugaredLogger) error {
-	dbOpsAdapter := func() (interface{}, error) {
-		return nil, dbOps()
+func Transaction(conn Connection, dbOps func(tx *Tx) error, logger *zap.SugaredLogger) error {
+	dbOpsAdapter := func(tx *Tx) (interface{}, error) {
+		return nil, dbOps(tx)
 	}
 	_, err := TransactionResult(conn, dbOpsAdapter, logger)
 	return err
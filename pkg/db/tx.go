@@ -0,0 +1,146 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+
+	"go.uber.org/zap"
+)
+
+//Tx is a Connection which executes all statements within a database transaction.
+//Beginning a transaction on a Tx starts a nested transaction which is handled by a savepoint.
+type Tx struct {
+	tx        *sql.Tx
+	conn      Connection //connection which started the transaction
+	logger    *zap.SugaredLogger
+	savepoint string //empty for the outermost transaction
+	depth     int
+	finished  bool
+	onCommit  *[]func() //shared by all nested transactions
+	hookCount int       //amount of commit hooks which were registered when the nested transaction began
+}
+
+func newTx(tx *sql.Tx, conn Connection, logger *zap.SugaredLogger) *Tx {
+	return &Tx{
+		tx:       tx,
+		conn:     conn,
+		logger:   logger,
+		onCommit: &[]func(){},
+	}
+}
+
+//OnCommit registers a function which is called after the outermost transaction was committed successfully.
+//Functions registered within a nested transaction are discarded if the nested transaction is rolled back.
+func (t *Tx) OnCommit(fct func()) {
+	*t.onCommit = append(*t.onCommit, fct)
+}
+
+func (t *Tx) Encryptor() *Encryptor {
+	return t.conn.Encryptor()
+}
+
+func (t *Tx) QueryRow(query string, args ...interface{}) DataRow {
+	t.logger.Debugf("Tx QueryRow(): %s | %v", query, args)
+	return t.tx.QueryRow(query, args...)
+}
+
+func (t *Tx) Query(query string, args ...interface{}) (DataRows, error) {
+	t.logger.Debugf("Tx Query(): %s | %v", query, args)
+	rows, err := t.tx.Query(query, args...)
+	if err != nil {
+		t.logger.Errorf("Tx Query() error: %s", err)
+	}
+	return rows, err
+}
+
+func (t *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	t.logger.Debugf("Tx Exec(): %s | %v", query, args)
+	result, err := t.tx.Exec(query, args...)
+	if err != nil {
+		t.logger.Errorf("Tx Exec() error: %s", err)
+	}
+	return result, err
+}
+
+//Begin starts a nested transaction by creating a savepoint
+func (t *Tx) Begin() (*Tx, error) {
+	if t.finished {
+		return nil, fmt.Errorf("cannot begin nested transaction: transaction is already finished")
+	}
+	nestedTx := &Tx{
+		tx:        t.tx,
+		conn:      t.conn,
+		logger:    t.logger,
+		savepoint: fmt.Sprintf("savepoint_%d", t.depth+1),
+		depth:     t.depth + 1,
+		onCommit:  t.onCommit,
+		hookCount: len(*t.onCommit),
+	}
+	if _, err := t.Exec(fmt.Sprintf("SAVEPOINT %s", nestedTx.savepoint)); err != nil {
+		return nil, err
+	}
+	return nestedTx, nil
+}
+
+//Commit commits the transaction or releases the savepoint of a nested transaction
+func (t *Tx) Commit() error {
+	if t.finished {
+		return fmt.Errorf("cannot commit: transaction is already finished")
+	}
+	t.finished = true
+	if t.savepoint == "" {
+		if err := t.tx.Commit(); err != nil {
+			return err
+		}
+		for _, fct := range *t.onCommit {
+			fct()
+		}
+		return nil
+	}
+	_, err := t.Exec(fmt.Sprintf("RELEASE SAVEPOINT %s", t.savepoint))
+	return err
+}
+
+//Rollback rolls the transaction back or reverts the changes made since the savepoint of a nested transaction
+func (t *Tx) Rollback() error {
+	if t.finished {
+		return fmt.Errorf("cannot rollback: transaction is already finished")
+	}
+	t.finished = true
+	*t.onCommit = (*t.onCommit)[:t.hookCount]
+	if t.savepoint == "" {
+		return t.tx.Rollback()
+	}
+	if _, err := t.Exec(fmt.Sprintf("ROLLBACK TO SAVEPOINT %s", t.savepoint)); err != nil {
+		return err
+	}
+	_, err := t.Exec(fmt.Sprintf("RELEASE SAVEPOINT %s", t.savepoint))
+	return err
+}
+
+//Close rolls the transaction back if it was neither committed nor rolled back
+func (t *Tx) Close() error {
+	if t.finished {
+		return nil
+	}
+	return t.Rollback()
+}
+
+//Lock serializes all transactions which acquire a lock with the same name: the lock is held until the
+//transaction is finished. Postgres uses an advisory lock, SQLite serializes writing transactions anyway.
+func (t *Tx) Lock(name string) error {
+	if t.Type() != Postgres {
+		return nil
+	}
+	hash := fnv.New64a()
+	if _, err := hash.Write([]byte(name)); err != nil {
+		return err
+	}
+	_, err := t.Exec("SELECT pg_advisory_xact_lock($1)", int64(hash.Sum64()))
+	return err
+}
+
+func (t *Tx) Type() Type {
+	return t.conn.Type()
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTx(t *testing.T) {
+	key, err := NewEncryptionKey()
+	require.NoError(t, err)
+
+	conn := newRotationTestConnection(t, filepath.Join(t.TempDir(), "tx.db"), key)
+	_, err = conn.Exec("CREATE TABLE mockTable (col_1 text, col_2 boolean, col_3 integer PRIMARY KEY)")
+	require.NoError(t, err)
+
+	insert := func(conn Connection, id int) error {
+		_, err := conn.Exec("INSERT INTO mockTable (col_1, col_2, col_3) VALUES ($1, $2, $3)", data, false, id)
+		return err
+	}
+	count := func() int {
+		var result int
+		require.NoError(t, conn.QueryRow("SELECT COUNT(*) FROM mockTable").Scan(&result))
+		return result
+	}
+	log := logger.NewOptionalLogger(true)
+
+	t.Run("Commit transaction", func(t *testing.T) {
+		err := Transaction(conn, func(tx *Tx) error {
+			if err := insert(tx, 1); err != nil {
+				return err
+			}
+			//statements executed with the transaction see its uncommitted changes
+			var result int
+			if err := tx.QueryRow("SELECT COUNT(*) FROM mockTable").Scan(&result); err != nil {
+				return err
+			}
+			require.Equal(t, 1, result)
+			return nil
+		}, log)
+		require.NoError(t, err)
+		require.Equal(t, 1, count())
+	})
+
+	t.Run("Rollback transaction", func(t *testing.T) {
+		err := Transaction(conn, func(tx *Tx) error {
+			if err := insert(tx, 2); err != nil {
+				return err
+			}
+			return fmt.Errorf("fail")
+		}, log)
+		require.Error(t, err)
+		require.Equal(t, 1, count())
+	})
+
+	t.Run("Rollback nested transaction", func(t *testing.T) {
+		err := Transaction(conn, func(tx *Tx) error {
+			if err := insert(tx, 3); err != nil {
+				return err
+			}
+			nestedErr := Transaction(tx, func(nestedTx *Tx) error {
+				if err := insert(nestedTx, 4); err != nil {
+					return err
+				}
+				return fmt.Errorf("fail")
+			}, log)
+			require.Error(t, nestedErr)
+			return nil
+		}, log)
+		require.NoError(t, err)
+		require.Equal(t, 2, count()) //only the insert of the outer transaction was committed
+	})
+
+	t.Run("Rollback outer transaction", func(t *testing.T) {
+		err := Transaction(conn, func(tx *Tx) error {
+			if err := Transaction(tx, func(nestedTx *Tx) error {
+				return insert(nestedTx, 5)
+			}, log); err != nil {
+				return err
+			}
+			return fmt.Errorf("fail")
+		}, log)
+		require.Error(t, err)
+		require.Equal(t, 2, count()) //committed nested transaction was reverted by the outer transaction
+	})
+
+	t.Run("Commit hooks", func(t *testing.T) {
+		var called []string
+		err := Transaction(conn, func(tx *Tx) error {
+			tx.OnCommit(func() { called = append(called, "outer") })
+			require.NoError(t, Transaction(tx, func(nestedTx *Tx) error {
+				nestedTx.OnCommit(func() { called = append(called, "committed nested") })
+				return nil
+			}, log))
+			require.Error(t, Transaction(tx, func(nestedTx *Tx) error {
+				nestedTx.OnCommit(func() { called = append(called, "reverted nested") })
+				return fmt.Errorf("fail")
+			}, log))
+			require.Empty(t, called) //hooks are not called before the outermost transaction is committed
+			return nil
+		}, log)
+		require.NoError(t, err)
+		require.Equal(t, []string{"outer", "committed nested"}, called)
+
+		called = nil
+		err = Transaction(conn, func(tx *Tx) error {
+			tx.OnCommit(func() { called = append(called, "outer") })
+			return fmt.Errorf("fail")
+		}, log)
+		require.Error(t, err)
+		require.Empty(t, called)
+	})
+
+	t.Run("Finished transaction", func(t *testing.T) {
+		tx, err := conn.Begin()
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		require.Error(t, tx.Commit())
+		require.Error(t, tx.Rollback())
+		_, err = tx.Begin()
+		require.Error(t, err)
+		require.NoError(t, tx.Close())
+	})
+}
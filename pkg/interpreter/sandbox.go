@@ -0,0 +1,249 @@
+package interpreter
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+)
+
+const stepFunction = "__step" //injected into loops and function literals to enforce the step limit
+
+var importRegExp = regexp.MustCompile(`^import\s*"`)
+
+//allowedPackages can be imported by the interpreted code
+var allowedPackages = []string{"fmt", "regexp", "net/url", "strings", "time", "strconv"}
+
+//deniedSymbols of allowed packages which are not accessible (I/O, blocking calls or background goroutines)
+var deniedSymbols = map[string][]string{
+	"fmt": {
+		"Print", "Printf", "Println",
+		"Scan", "Scanf", "Scanln",
+		"Fprint", "Fprintf", "Fprintln",
+		"Fscan", "Fscanf", "Fscanln",
+	},
+	"time": {
+		"Sleep", "After", "AfterFunc", "NewTicker", "NewTimer", "Tick",
+	},
+}
+
+//sandboxSymbols is the curated symbol table used by the interpreter
+var sandboxSymbols = func() interp.Exports {
+	result := make(interp.Exports, len(allowedPackages))
+	for _, pkg := range allowedPackages {
+		symbols := make(map[string]reflect.Value, len(stdlib.Symbols[pkg]))
+		for name, value := range stdlib.Symbols[pkg] {
+			if !isDeniedSymbol(pkg, name) {
+				symbols[name] = value
+			}
+		}
+		result[pkg] = symbols
+	}
+	return result
+}()
+
+func isDeniedSymbol(pkg, name string) bool {
+	for _, denied := range deniedSymbols[pkg] {
+		if name == denied {
+			return true
+		}
+	}
+	return false
+}
+
+func isAllowedPackage(pkg string) bool {
+	for _, allowed := range allowedPackages {
+		if pkg == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+//compile verifies the imports and the syntax of the code and returns the statements which will be evaluated.
+//Loops and function literals get instrumented with a call of the step function.
+func compile(code string) ([]string, error) {
+	if err := verifyImports(code); err != nil {
+		return nil, err
+	}
+
+	compileErr := &CompileError{}
+	var stmts []string
+	for idx, line := range strings.Split(code, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if importRegExp.MatchString(line) { //imports were already verified
+			stmts = append(stmts, line)
+			continue
+		}
+		lineStmts, issues := compileLine(idx+1, line)
+		if len(issues) > 0 {
+			compileErr.Issues = append(compileErr.Issues, issues...)
+			continue
+		}
+		stmts = append(stmts, lineStmts...)
+	}
+
+	if len(compileErr.Issues) > 0 {
+		return nil, compileErr
+	}
+	return stmts, nil
+}
+
+//verifyImports tokenizes the code and accepts only non-aliased single imports of allowed packages
+func verifyImports(code string) error {
+	var scan scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(code))
+	scan.Init(file, []byte(code), nil, scanner.ScanComments)
+
+	for {
+		_, tok, _ := scan.Scan()
+		if tok == token.EOF {
+			return nil
+		}
+		if tok != token.IMPORT {
+			continue
+		}
+		_, tok, lit := scan.Scan()
+		switch tok {
+		case token.STRING:
+			pkg, err := strconv.Unquote(lit)
+			if err != nil || !isAllowedPackage(pkg) {
+				return &BlockedImportError{BlockedImport: fmt.Sprintf("import %s", lit)}
+			}
+		case token.LPAREN:
+			return &BlockedImportError{BlockedImport: "import (...)", Reason: "grouped imports are not supported"}
+		default:
+			return &BlockedImportError{BlockedImport: fmt.Sprintf("import %s", lit), Reason: "aliased imports are not supported"}
+		}
+	}
+}
+
+//compileLine parses the statements of a line and instruments them
+func compileLine(lineNo int, line string) ([]string, []*CompileIssue) {
+	const prefix = "package sandbox\nfunc _() {\n"
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", prefix+line+"\n}", 0)
+	if err != nil {
+		var issues []*CompileIssue
+		if errList, ok := err.(scanner.ErrorList); ok {
+			for _, parseErr := range errList {
+				issues = append(issues, &CompileIssue{
+					Line:    lineNo,
+					Column:  parseErr.Pos.Column,
+					Message: parseErr.Msg,
+				})
+			}
+		} else {
+			issues = append(issues, &CompileIssue{Line: lineNo, Message: err.Error()})
+		}
+		return nil, issues
+	}
+
+	body := file.Decls[0].(*ast.FuncDecl).Body
+	if issues := verifyStatements(fset, lineNo, body); len(issues) > 0 {
+		return nil, issues
+	}
+	instrument(body)
+
+	var result []string
+	for _, stmt := range body.List {
+		var buffer bytes.Buffer
+		if err := printer.Fprint(&buffer, fset, stmt); err != nil {
+			return nil, []*CompileIssue{{Line: lineNo, Message: err.Error()}}
+		}
+		result = append(result, buffer.String())
+	}
+	return result, nil
+}
+
+//verifyStatements rejects statements which could escape the step limit or the timeout
+func verifyStatements(fset *token.FileSet, lineNo int, body *ast.BlockStmt) []*CompileIssue {
+	var issues []*CompileIssue
+	addIssue := func(node ast.Node, msg string) {
+		issues = append(issues, &CompileIssue{
+			Line:    lineNo,
+			Column:  fset.Position(node.Pos()).Column,
+			Message: msg,
+		})
+	}
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.GoStmt:
+			addIssue(n, "go statements are not supported")
+		case *ast.BranchStmt:
+			if n.Tok == token.GOTO {
+				addIssue(n, "goto statements are not supported")
+			}
+		case *ast.Ident:
+			if n.Name == stepFunction {
+				addIssue(n, fmt.Sprintf("identifier '%s' is reserved", stepFunction))
+			}
+		}
+		return true
+	})
+	return issues
+}
+
+//instrument adds a step function call to each loop body and function literal
+func instrument(body *ast.BlockStmt) {
+	ast.Inspect(body, func(node ast.Node) bool {
+		var block *ast.BlockStmt
+		switch n := node.(type) {
+		case *ast.ForStmt:
+			block = n.Body
+		case *ast.RangeStmt:
+			block = n.Body
+		case *ast.FuncLit:
+			block = n.Body
+		default:
+			return true
+		}
+		stepCall := &ast.ExprStmt{X: &ast.CallExpr{Fun: ast.NewIdent(stepFunction)}}
+		block.List = append([]ast.Stmt{stepCall}, block.List...)
+		return true
+	})
+}
+
+//CompileIssue describes a problem found while compiling the code (line and column are 1-based)
+type CompileIssue struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i *CompileIssue) String() string {
+	if i.Column > 0 {
+		return fmt.Sprintf("line %d, column %d: %s", i.Line, i.Column, i.Message)
+	}
+	return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+}
+
+type CompileError struct {
+	Issues []*CompileIssue
+}
+
+func (e *CompileError) Error() string {
+	var msgs []string
+	for _, issue := range e.Issues {
+		msgs = append(msgs, issue.String())
+	}
+	return fmt.Sprintf("Go code cannot be compiled:\n%s", strings.Join(msgs, "\n"))
+}
+
+func IsCompileError(err error) bool {
+	return reflect.TypeOf(err) == reflect.TypeOf(&CompileError{})
+}
@@ -1,68 +1,144 @@
 package interpreter
 
 import (
-	"bufio"
+	"context"
 	"fmt"
+	"io/ioutil"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/traefik/yaegi/interp"
-	"github.com/traefik/yaegi/stdlib"
 )
 
-const allowedPackages = "fmt|regexp|net/url|strings|time|strconv"
+const (
+	symbolsPackage  = "symbols" //internal package used to expose bindings and Go functions to the interpreted code
+	defaultTimeout  = 5 * time.Second
+	defaultMaxSteps = 100000
+)
 
-var regExp = regexp.MustCompile(fmt.Sprintf(`^import\s+"(%s)"$`, allowedPackages))
+var functionNameRegExp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
 
 type GolangInterpreter struct {
-	code     string
-	bindings map[string]interface{}
+	ctx       context.Context
+	code      string
+	bindings  map[string]interface{}
+	functions map[string]interface{}
+	timeout   time.Duration
+	maxSteps  int
 }
 
 func NewGolangInterpreter(code string) *GolangInterpreter {
 	return &GolangInterpreter{
-		code: code,
+		ctx:      context.Background(),
+		code:     code,
+		timeout:  defaultTimeout,
+		maxSteps: defaultMaxSteps,
 	}
 }
 
 func (gi *GolangInterpreter) WithBindings(bindings map[string]interface{}) *GolangInterpreter {
 	if gi.bindings == nil {
-		gi.bindings = bindings
-	} else {
-		for k, v := range gi.bindings {
-			gi.bindings[k] = v
-		}
+		gi.bindings = make(map[string]interface{}, len(bindings))
+	}
+	for k, v := range bindings {
+		gi.bindings[k] = v
 	}
 	return gi
 }
 
-func (gi *GolangInterpreter) Eval() (reflect.Value, error) {
-	interp := interp.New(interp.Options{})
-	interp.Use(stdlib.Symbols)
+//WithFunctions makes Go functions callable by the interpreted code (the map key is the function name)
+func (gi *GolangInterpreter) WithFunctions(functions map[string]interface{}) *GolangInterpreter {
+	if gi.functions == nil {
+		gi.functions = make(map[string]interface{}, len(functions))
+	}
+	for name, fct := range functions {
+		gi.functions[name] = fct
+	}
+	return gi
+}
+
+//WithContext aborts the execution of the code if the context is done (the timeout is applied in addition)
+func (gi *GolangInterpreter) WithContext(ctx context.Context) *GolangInterpreter {
+	gi.ctx = ctx
+	return gi
+}
+
+//WithTimeout limits the execution time of the code
+func (gi *GolangInterpreter) WithTimeout(timeout time.Duration) *GolangInterpreter {
+	gi.timeout = timeout
+	return gi
+}
+
+//WithMaxSteps limits the number of executed loop iterations and function literal calls
+func (gi *GolangInterpreter) WithMaxSteps(maxSteps int) *GolangInterpreter {
+	gi.maxSteps = maxSteps
+	return gi
+}
 
+//Compile verifies the code without executing it
+func (gi *GolangInterpreter) Compile() error {
+	_, err := compile(gi.code)
+	return err
+}
+
+func (gi *GolangInterpreter) Eval() (reflect.Value, error) {
 	var lastResult reflect.Value
-	var err error
 
-	//add bindings to interpreter
-	if err := gi.bind(interp, gi.bindings); err != nil {
+	stmts, err := compile(gi.code)
+	if err != nil {
 		return lastResult, err
 	}
 
-	//execute the code
-	scanner := bufio.NewScanner(strings.NewReader(gi.code))
-	for scanner.Scan() {
-		line := scanner.Text()
+	interp := interp.New(interp.Options{
+		Stdin:  strings.NewReader(""),
+		Stdout: ioutil.Discard,
+		Stderr: ioutil.Discard,
+	})
+	interp.Use(sandboxSymbols)
 
-		//block execution of non-whitelisted imports
-		if strings.HasPrefix(line, "import") && !regExp.MatchString(line) {
-			return lastResult, &BlockedImportError{BlockedImport: line}
+	ctx, cancel := context.WithTimeout(gi.ctx, gi.timeout)
+	defer cancel()
+
+	//the step function is called by the instrumented code and cancels the execution if the limit is exceeded
+	var steps int64
+	step := func() {
+		if atomic.AddInt64(&steps, 1) > int64(gi.maxSteps) {
+			cancel()
 		}
+	}
+
+	//add bindings and functions to interpreter
+	symbols := map[string]reflect.Value{
+		exportedSymbol(stepFunction): reflect.ValueOf(step),
+	}
+	if err := gi.bind(symbols, gi.bindings); err != nil {
+		return lastResult, err
+	}
+	if err := gi.addFunctions(symbols, gi.functions); err != nil {
+		return lastResult, err
+	}
+	if err := gi.registerSymbols(interp, symbols); err != nil {
+		return lastResult, err
+	}
 
-		lastResult, err = interp.Eval(line)
+	//execute the code
+	for _, stmt := range stmts {
+		lastResult, err = interp.EvalWithContext(ctx, stmt)
+		if atomic.LoadInt64(&steps) > int64(gi.maxSteps) {
+			return reflect.Value{}, &StepLimitError{MaxSteps: gi.maxSteps}
+		}
+		if gi.ctx.Err() != nil {
+			return reflect.Value{}, fmt.Errorf("Go interpreter was aborted: %s", gi.ctx.Err())
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return reflect.Value{}, &TimeoutError{Timeout: gi.timeout}
+		}
 		if err != nil {
-			return lastResult, fmt.Errorf("Go interpreter failed to execute line '%s':\n%s", line, err.Error())
+			return lastResult, fmt.Errorf("Go interpreter failed to execute line '%s':\n%s", stmt, err.Error())
 		}
 	}
 
@@ -98,40 +174,65 @@ func (gi *GolangInterpreter) EvalString() (string, error) {
 	}
 }
 
-func (gi *GolangInterpreter) bind(interp *interp.Interpreter, bindings map[string]interface{}) error {
-	if bindings == nil {
-		return nil
-	}
-
-	var err error
+func (gi *GolangInterpreter) bind(symbols map[string]reflect.Value, bindings map[string]interface{}) error {
 	for k, v := range bindings {
+		if !functionNameRegExp.MatchString(k) {
+			return fmt.Errorf("Cannot bind key '%s' because it is not a valid identifier", k)
+		}
 		switch v.(type) {
-		case string:
-			_, err = interp.Eval(fmt.Sprintf(`var %s string = "%s"`, k, v))
-		case bool:
-			_, err = interp.Eval(fmt.Sprintf(`var %s bool = %t`, k, v))
-		case int:
-			_, err = interp.Eval(fmt.Sprintf(`var %s int = %d`, k, v))
-		case int64:
-			_, err = interp.Eval(fmt.Sprintf(`var %s int64 = %d`, k, v))
-		case float32:
-			_, err = interp.Eval(fmt.Sprintf(`var %s float32 = %f`, k, v))
-		case float64:
-			_, err = interp.Eval(fmt.Sprintf(`var %s float64 = %f`, k, v))
+		case string, bool, int, int64, float32, float64, time.Duration, []interface{}, map[string]interface{}:
+			symbols[exportedSymbol(k)] = reflect.ValueOf(v)
 		default:
-			err = fmt.Errorf("Cannot bind key '%s' because value of type '%T' is not supported", k, v)
+			return fmt.Errorf("Cannot bind key '%s' because value of type '%T' is not supported", k, v)
 		}
 	}
+	return nil
+}
 
-	return err
+func (gi *GolangInterpreter) addFunctions(symbols map[string]reflect.Value, functions map[string]interface{}) error {
+	for name, fct := range functions {
+		if !functionNameRegExp.MatchString(name) {
+			return fmt.Errorf("Cannot register function '%s' because its name is not a valid identifier", name)
+		}
+		if reflect.TypeOf(fct).Kind() != reflect.Func {
+			return fmt.Errorf("Cannot register function '%s' because value of type '%T' is not a function", name, fct)
+		}
+		symbols[exportedSymbol(name)] = reflect.ValueOf(fct)
+	}
+	return nil
+}
+
+//registerSymbols exports bindings and functions by an internal package and assigns them to variables using their names
+func (gi *GolangInterpreter) registerSymbols(interp *interp.Interpreter, symbols map[string]reflect.Value) error {
+	interp.Use(map[string]map[string]reflect.Value{symbolsPackage: symbols})
+
+	if _, err := interp.Eval(fmt.Sprintf(`import "%s"`, symbolsPackage)); err != nil {
+		return err
+	}
+	for name := range symbols {
+		name = strings.TrimPrefix(name, exportedSymbol(""))
+		if _, err := interp.Eval(fmt.Sprintf("var %s = %s.%s", name, symbolsPackage, exportedSymbol(name))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportedSymbol(name string) string {
+	return fmt.Sprintf("F%s", name)
 }
 
 type BlockedImportError struct {
 	BlockedImport string
+	Reason        string
 }
 
 func (e *BlockedImportError) Error() string {
-	return fmt.Sprintf("Blocking import statement '%s': only these packages are allowed '%s'", e.BlockedImport, allowedPackages)
+	if e.Reason != "" {
+		return fmt.Sprintf("Blocking import statement '%s': %s", e.BlockedImport, e.Reason)
+	}
+	return fmt.Sprintf("Blocking import statement '%s': only these packages are allowed '%s'",
+		e.BlockedImport, strings.Join(allowedPackages, "|"))
 }
 
 func IsBlockedImportError(err error) bool {
@@ -149,3 +250,27 @@ func (e *NoBooleanResultError) Error() string {
 func IsNoBooleanResultError(err error) bool {
 	return reflect.TypeOf(err) == reflect.TypeOf(&NoBooleanResultError{})
 }
+
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("Go interpreter exceeded the execution timeout of %v", e.Timeout)
+}
+
+func IsTimeoutError(err error) bool {
+	return reflect.TypeOf(err) == reflect.TypeOf(&TimeoutError{})
+}
+
+type StepLimitError struct {
+	MaxSteps int
+}
+
+func (e *StepLimitError) Error() string {
+	return fmt.Sprintf("Go interpreter exceeded the limit of %d execution steps", e.MaxSteps)
+}
+
+func IsStepLimitError(err error) bool {
+	return reflect.TypeOf(err) == reflect.TypeOf(&StepLimitError{})
+}
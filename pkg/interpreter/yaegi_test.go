@@ -1,8 +1,10 @@
 package interpreter
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -100,4 +102,156 @@ os.GetEnv("ABC")
 		require.True(t, IsBlockedImportError(err))
 	})
 
+	t.Run("Call registered functions", func(t *testing.T) {
+		var calls []string
+		goInt := NewGolangInterpreter(`
+restart("app=test")
+restart(prefix + "second")
+count()
+`).WithBindings(map[string]interface{}{"prefix": "app="}).WithFunctions(map[string]interface{}{
+			"restart": func(selector string) {
+				calls = append(calls, selector)
+			},
+			"count": func() int {
+				return len(calls)
+			},
+		})
+		result, err := goInt.EvalString()
+		require.NoError(t, err)
+		require.Equal(t, "2", result)
+		require.Equal(t, []string{"app=test", "app=second"}, calls)
+	})
+
+	t.Run("Reject invalid functions", func(t *testing.T) {
+		_, err := NewGolangInterpreter(`1`).WithFunctions(map[string]interface{}{"no-identifier": func() {}}).Eval()
+		require.Error(t, err)
+		_, err = NewGolangInterpreter(`1`).WithFunctions(map[string]interface{}{"noFunction": "abc"}).Eval()
+		require.Error(t, err)
+	})
+
+	t.Run("Block aliased and indented imports", func(t *testing.T) {
+		for _, code := range []string{
+			`import x "os"`,
+			`import . "fmt"`,
+			`import _ "os"`,
+			"  import \"os\"",
+			`var a = 1; import "os"`,
+		} {
+			_, err := NewGolangInterpreter(code).Eval()
+			require.Error(t, err)
+			require.True(t, IsBlockedImportError(err), code)
+		}
+	})
+
+	t.Run("Block denied symbols of allowed packages", func(t *testing.T) {
+		_, err := NewGolangInterpreter(`
+import "time"
+time.Sleep(10 * time.Second)
+`).Eval()
+		require.Error(t, err)
+		require.False(t, IsTimeoutError(err))
+	})
+
+	t.Run("Abort infinite loop (step limit)", func(t *testing.T) {
+		_, err := NewGolangInterpreter(`
+var x = 0
+for { x++ }
+`).WithMaxSteps(1000).Eval()
+		require.Error(t, err)
+		require.True(t, IsStepLimitError(err))
+	})
+
+	t.Run("Abort infinite recursion (step limit)", func(t *testing.T) {
+		_, err := NewGolangInterpreter(`
+var f func(int) int
+f = func(i int) int { return f(i+1) }
+f(0)
+`).WithMaxSteps(1000).Eval()
+		require.Error(t, err)
+		require.True(t, IsStepLimitError(err))
+	})
+
+	t.Run("Abort infinite loop (timeout)", func(t *testing.T) {
+		_, err := NewGolangInterpreter(`
+var x = 0
+for { x++ }
+`).WithMaxSteps(1 << 30).WithTimeout(100 * time.Millisecond).Eval()
+		require.Error(t, err)
+		require.True(t, IsTimeoutError(err))
+	})
+
+	t.Run("Abort infinite loop (context)", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := NewGolangInterpreter(`
+var x = 0
+for { x++ }
+`).WithMaxSteps(1 << 30).WithContext(ctx).Eval()
+		require.Error(t, err)
+		require.False(t, IsTimeoutError(err))
+		require.Contains(t, err.Error(), "aborted")
+	})
+
+	t.Run("Loops within the step limit", func(t *testing.T) {
+		result, err := NewGolangInterpreter(`
+var sum = 0
+for i := 1; i <= 10; i++ { sum += i }
+sum
+`).WithMaxSteps(10).EvalString()
+		require.NoError(t, err)
+		require.Equal(t, "55", result)
+	})
+
+	t.Run("Structured compile error", func(t *testing.T) {
+		goInt := NewGolangInterpreter(`
+var x = 1
+x >
+go func() {}()
+__step()
+`)
+		err := goInt.Compile()
+		require.Error(t, err)
+		require.True(t, IsCompileError(err))
+		issues := err.(*CompileError).Issues
+		require.Len(t, issues, 3)
+		require.Equal(t, 3, issues[0].Line)
+		require.Equal(t, 4, issues[1].Line)
+		require.Equal(t, 1, issues[1].Column)
+		require.Equal(t, 5, issues[2].Line)
+
+		//code is not executed if it cannot be compiled
+		_, err = goInt.Eval()
+		require.True(t, IsCompileError(err))
+	})
+
+	t.Run("Bindings cannot inject code", func(t *testing.T) {
+		result, err := NewGolangInterpreter(`value`).
+			WithBindings(map[string]interface{}{"value": `"; var injected = "true`}).
+			EvalString()
+		require.NoError(t, err)
+		require.Equal(t, `"; var injected = "true`, result)
+	})
+
+	t.Run("Bind complex values", func(t *testing.T) {
+		result, err := NewGolangInterpreter(`
+import "time"
+d > time.Minute && len(l) == 2 && l[1].(string) == "b" && m["x"].(float64) == 1
+`).WithBindings(map[string]interface{}{
+			"d": 2 * time.Minute,
+			"l": []interface{}{"a", "b"},
+			"m": map[string]interface{}{"x": float64(1)},
+		}).EvalBool()
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("Merge bindings", func(t *testing.T) {
+		result, err := NewGolangInterpreter(`a + b`).
+			WithBindings(map[string]interface{}{"a": "x"}).
+			WithBindings(map[string]interface{}{"b": "y"}).
+			EvalString()
+		require.NoError(t, err)
+		require.Equal(t, "xy", result)
+	})
+
 }
@@ -19,6 +19,12 @@ type HTTPClusterResponse struct {
 	StatusURL            string        `json:"statusUrl"`
 }
 
+//HTTPClustersResponse is the model used to respond a page of a cluster listing
+type HTTPClustersResponse struct {
+	Clusters []*HTTPClusterResponse `json:"clusters"`
+	NextPage string                 `json:"nextPage,omitempty"`
+}
+
 //HTTPErrorResponse is the model used for general error responses
 type HTTPErrorResponse struct {
 	Error string `json:"error"`
@@ -33,3 +39,28 @@ type StatusChange struct {
 	Duration time.Duration `json:"duration"`
 	Status   ClusterStatus `json:"status"`
 }
+
+//HTTPBucketRule is the model used to define a bucket rule and to respond bucket rules
+type HTTPBucketRule struct {
+	Rule     string            `json:"rule"`
+	Priority int64             `json:"priority"`
+	Selector map[string]string `json:"selector,omitempty"`
+	Buckets  []string          `json:"buckets"`
+	Version  int64             `json:"version,omitempty"`
+	Created  time.Time         `json:"created,omitempty"`
+}
+
+type HTTPBucketRulesResponse struct {
+	Rules []*HTTPBucketRule `json:"rules"`
+}
+
+//HTTPClusterBucketsResponse is the model used to respond the buckets assigned to a cluster (in merge order)
+type HTTPClusterBucketsResponse struct {
+	Cluster string               `json:"cluster"`
+	Buckets []*HTTPClusterBucket `json:"buckets"`
+}
+
+type HTTPClusterBucket struct {
+	Bucket string `json:"bucket"`
+	Rule   string `json:"rule,omitempty"`
+}
@@ -43,8 +43,8 @@ func (cb *ClientBuilder) Build(validate bool) (kubernetes.Interface, error) {
 	}
 	config, err := clientcmd.RESTConfigFromKubeConfig(cb.kubeconfig)
 	if err != nil {
-		return nil, errors.Wrap(err,
-			fmt.Sprintf("failed to create Kubernetes client configuration using provided kubeconfig: %s", cb.kubeconfig))
+		//never include the kubeconfig in the error: it contains the credentials of the cluster
+		return nil, errors.Wrap(err, "failed to create Kubernetes client configuration using provided kubeconfig")
 	}
 	clientSet, err := kubernetes.NewForConfig(config)
 	if err != nil {
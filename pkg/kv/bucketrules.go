@@ -0,0 +1,149 @@
+package kv
+
+import (
+	"fmt"
+
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/kyma-incubator/reconciler/pkg/repository"
+)
+
+//BucketRules returns the latest version of all bucket rules (deleted rules are excluded)
+//ordered by their priority.
+func (cer *Repository) BucketRules() ([]*model.BucketRuleEntity, error) {
+	entity := &model.BucketRuleEntity{}
+	q, err := db.NewQuery(cer.Conn, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	//query latest version of all rules
+	entities, err := q.Select().
+		WhereCondition(db.InQuery("Version", q.Select().
+			Aggregate(db.AggregateMax, "Version").
+			GroupBy([]string{"Rule"}))).
+		GetMany()
+	if err != nil {
+		return nil, err
+	}
+
+	//cast to specific entity
+	var result []*model.BucketRuleEntity
+	for _, entity := range entities {
+		rule := entity.(*model.BucketRuleEntity)
+		if rule.Deleted {
+			continue
+		}
+		result = append(result, rule)
+	}
+	model.SortBucketRules(result)
+	return result, nil
+}
+
+func (cer *Repository) BucketRuleHistory(rule string) ([]*model.BucketRuleEntity, error) {
+	q, err := db.NewQuery(cer.Conn, &model.BucketRuleEntity{})
+	if err != nil {
+		return nil, err
+	}
+	entities, err := q.Select().
+		Where(map[string]interface{}{"Rule": rule}).
+		OrderBy(map[string]string{"Version": "ASC"}).
+		GetMany()
+	if err != nil {
+		return nil, err
+	}
+	//cast to specific entity
+	var result []*model.BucketRuleEntity
+	for _, entity := range entities {
+		result = append(result, entity.(*model.BucketRuleEntity))
+	}
+	return result, nil
+}
+
+func (cer *Repository) LatestBucketRule(rule string) (*model.BucketRuleEntity, error) {
+	entity, err := cer.latestBucketRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	if entity.Deleted {
+		return nil, cer.NewNotFoundError(fmt.Errorf("bucket rule '%s' was deleted", rule),
+			&model.BucketRuleEntity{}, map[string]interface{}{"Rule": rule})
+	}
+	return entity, nil
+}
+
+func (cer *Repository) latestBucketRule(rule string) (*model.BucketRuleEntity, error) {
+	q, err := db.NewQuery(cer.Conn, &model.BucketRuleEntity{})
+	if err != nil {
+		return nil, err
+	}
+	whereCond := map[string]interface{}{"Rule": rule}
+	entity, err := q.Select().
+		Where(whereCond).
+		OrderBy(map[string]string{"Version": "DESC"}).
+		Limit(1).
+		GetOne()
+	if err != nil {
+		return nil, cer.NewNotFoundError(err, &model.BucketRuleEntity{}, whereCond)
+	}
+	return entity.(*model.BucketRuleEntity), nil
+}
+
+//CreateBucketRule adds a new version of a bucket rule (if it differs from the latest version)
+func (cer *Repository) CreateBucketRule(rule *model.BucketRuleEntity) (*model.BucketRuleEntity, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	existingRule, err := cer.latestBucketRule(rule.Rule)
+	if err != nil && !repository.IsNotFoundError(err) {
+		return nil, err
+	}
+	if existingRule != nil && existingRule.Equal(rule) {
+		cer.Logger.Debugf("No differences found for bucket rule '%s': not creating new database entity", rule.Rule)
+		return existingRule, nil
+	}
+	dbOps := func(tx *db.Tx) error {
+		txRepo := cer.WithTx(tx)
+		q, err := db.NewQuery(txRepo.Conn, rule)
+		if err != nil {
+			return err
+		}
+		if err := q.Insert().Exec(); err != nil {
+			return err
+		}
+		action, versionBefore := model.AuditActionCreate, int64(0)
+		if existingRule != nil && !existingRule.Deleted { //re-creating a deleted rule is a create
+			action, versionBefore = model.AuditActionUpdate, existingRule.Version
+		}
+		return txRepo.audit(action, model.AuditEntityBucketRule, rule.Rule, versionBefore, rule.Version, "")
+	}
+	return rule, cer.Transactional(dbOps)
+}
+
+//DeleteBucketRule marks a bucket rule as deleted by adding a new version of it (the history is preserved)
+func (cer *Repository) DeleteBucketRule(rule, username string) error {
+	existingRule, err := cer.LatestBucketRule(rule)
+	if err != nil {
+		return err
+	}
+	deletedRule := &model.BucketRuleEntity{
+		Rule:     existingRule.Rule,
+		Priority: existingRule.Priority,
+		Selector: existingRule.Selector,
+		Buckets:  existingRule.Buckets,
+		Username: username,
+		Deleted:  true,
+	}
+	dbOps := func(tx *db.Tx) error {
+		txRepo := cer.WithTx(tx)
+		q, err := db.NewQuery(txRepo.Conn, deletedRule)
+		if err != nil {
+			return err
+		}
+		if err := q.Insert().Exec(); err != nil {
+			return err
+		}
+		return txRepo.audit(model.AuditActionDelete, model.AuditEntityBucketRule, rule, existingRule.Version, deletedRule.Version, "")
+	}
+	return cer.Transactional(dbOps)
+}
@@ -0,0 +1,144 @@
+package kv
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/audit"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/kyma-incubator/reconciler/pkg/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryBucketRules(t *testing.T) {
+	repo := newKeyValueRepo(t)
+
+	ts := time.Now().UnixNano()
+	ruleNames := []string{fmt.Sprintf("testRule-%d", ts), fmt.Sprintf("testRule2-%d", ts)}
+
+	filter := func(rules []*model.BucketRuleEntity) []*model.BucketRuleEntity {
+		var result []*model.BucketRuleEntity
+		for _, rule := range rules {
+			if rule.Rule == ruleNames[0] || rule.Rule == ruleNames[1] {
+				result = append(result, rule)
+			}
+		}
+		return result
+	}
+
+	t.Run("Reject invalid rules", func(t *testing.T) {
+		_, err := model.NewBucketRuleEntity(ruleNames[0], 1, model.BucketSelector{"unknown": "*"}, []string{"default"}, "test")
+		require.Error(t, err)
+		_, err = model.NewBucketRuleEntity(ruleNames[0], 1, model.BucketSelector{model.SelectorShootName: "[abc"}, []string{"default"}, "test")
+		require.Error(t, err)
+		_, err = model.NewBucketRuleEntity(ruleNames[0], 1, nil, nil, "test")
+		require.Error(t, err)
+		_, err = repo.CreateBucketRule(&model.BucketRuleEntity{Rule: ruleNames[0], Buckets: `["default"]`})
+		require.Error(t, err) //username is missing
+	})
+
+	t.Run("Create rules in multiple versions", func(t *testing.T) {
+		for _, priority := range []int64{30, 10} {
+			rule, err := model.NewBucketRuleEntity(ruleNames[0], priority,
+				model.BucketSelector{model.SelectorGlobalAccountID: "ga-*"}, []string{"ga-default", "${globalAccountID}"}, "test")
+			require.NoError(t, err)
+			_, err = repo.CreateBucketRule(rule)
+			require.NoError(t, err)
+		}
+		rule, err := model.NewBucketRuleEntity(ruleNames[1], 20, nil, []string{"default"}, "test")
+		require.NoError(t, err)
+		_, err = repo.CreateBucketRule(rule)
+		require.NoError(t, err)
+	})
+
+	t.Run("Create existing rule", func(t *testing.T) {
+		rule, err := model.NewBucketRuleEntity(ruleNames[1], 20, nil, []string{"default"}, "test")
+		require.NoError(t, err)
+		_, err = repo.CreateBucketRule(rule)
+		require.NoError(t, err)
+
+		history, err := repo.BucketRuleHistory(ruleNames[1])
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+	})
+
+	t.Run("Get rules ordered by priority", func(t *testing.T) {
+		rules, err := repo.BucketRules()
+		require.NoError(t, err)
+		rules = filter(rules)
+		require.Len(t, rules, 2)
+		require.Equal(t, ruleNames[0], rules[0].Rule)
+		require.Equal(t, int64(10), rules[0].Priority)
+		require.Equal(t, ruleNames[1], rules[1].Rule)
+
+		buckets, err := rules[0].GetBuckets()
+		require.NoError(t, err)
+		require.Equal(t, []string{"ga-default", "${globalAccountID}"}, buckets)
+	})
+
+	t.Run("Get rule history", func(t *testing.T) {
+		history, err := repo.BucketRuleHistory(ruleNames[0])
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, int64(30), history[0].Priority)
+		require.Equal(t, int64(10), history[1].Priority)
+	})
+
+	t.Run("Delete rules", func(t *testing.T) {
+		for _, ruleName := range ruleNames {
+			require.NoError(t, repo.DeleteBucketRule(ruleName, "test"))
+			_, err := repo.LatestBucketRule(ruleName)
+			require.True(t, repository.IsNotFoundError(err))
+		}
+
+		rules, err := repo.BucketRules()
+		require.NoError(t, err)
+		require.Empty(t, filter(rules))
+
+		//history is preserved
+		history, err := repo.BucketRuleHistory(ruleNames[0])
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		require.True(t, history[2].Deleted)
+
+		//deleted rules cannot be deleted again
+		require.True(t, repository.IsNotFoundError(repo.DeleteBucketRule(ruleNames[0], "test")))
+	})
+
+	t.Run("Writes are audited in the name of the actor", func(t *testing.T) {
+		ruleName := fmt.Sprintf("testRule3-%d", ts)
+		actorRepo := repo.WithActor("alice")
+		for _, priority := range []int64{1, 2} {
+			rule, err := model.NewBucketRuleEntity(ruleName, priority, nil, []string{"default"}, "alice")
+			require.NoError(t, err)
+			_, err = actorRepo.CreateBucketRule(rule)
+			require.NoError(t, err)
+		}
+		require.NoError(t, actorRepo.DeleteBucketRule(ruleName, "alice"))
+
+		history, err := repo.BucketRuleHistory(ruleName)
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+
+		auditRepo := &audit.Repository{Repository: repo.Repository}
+		entries, err := auditRepo.List(&audit.Filter{EntityType: model.AuditEntityBucketRule, Target: ruleName})
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		expected := []struct {
+			action        model.AuditAction
+			versionBefore int64
+			versionAfter  int64
+		}{
+			{model.AuditActionDelete, history[1].Version, history[2].Version},
+			{model.AuditActionUpdate, history[0].Version, history[1].Version},
+			{model.AuditActionCreate, 0, history[0].Version},
+		}
+		for idx, entry := range entries {
+			require.Equal(t, "alice", entry.Actor)
+			require.Equal(t, expected[idx].action, entry.Action)
+			require.Equal(t, expected[idx].versionBefore, entry.VersionBefore)
+			require.Equal(t, expected[idx].versionAfter, entry.VersionAfter)
+		}
+	})
+}
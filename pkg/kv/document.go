@@ -0,0 +1,405 @@
+package kv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/kyma-incubator/reconciler/pkg/repository"
+	"sigs.k8s.io/yaml"
+)
+
+//Document is the portable representation of configuration keys and bucket values (e.g. to manage them in Git)
+type Document struct {
+	Keys    []*KeyDocument    `json:"keys,omitempty"`
+	Buckets []*BucketDocument `json:"buckets,omitempty"`
+}
+
+type KeyDocument struct {
+	Key       string `json:"key"`
+	DataType  string `json:"dataType,omitempty"`
+	Encrypted bool   `json:"encrypted,omitempty"`
+	Validator string `json:"validator,omitempty"`
+	Trigger   string `json:"trigger,omitempty"`
+}
+
+func (kd *KeyDocument) String() string {
+	return fmt.Sprintf("dataType=%s,encrypted=%t,validator=%s,trigger=%s",
+		kd.DataType, kd.Encrypted, kd.Validator, kd.Trigger)
+}
+
+//BucketDocument contains the values of a bucket: values of data type list, map or yaml can be defined
+//as nested YAML structures, all other values are converted to text
+type BucketDocument struct {
+	Bucket string                 `json:"bucket"`
+	Values map[string]interface{} `json:"values,omitempty"`
+}
+
+func NewDocument(data []byte) (*Document, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	//decode numbers as json.Number: float64 would lose the precision of large integers
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	doc := &Document{}
+	if err := decoder.Decode(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (d *Document) YAML() ([]byte, error) {
+	return yaml.Marshal(d)
+}
+
+func newKeyDocument(key *model.KeyEntity) *KeyDocument {
+	return &KeyDocument{
+		Key:       key.Key,
+		DataType:  string(key.DataType),
+		Encrypted: key.Encrypted,
+		Validator: key.Validator,
+		Trigger:   key.Trigger,
+	}
+}
+
+func (kd *KeyDocument) entity(username string) (*model.KeyEntity, error) {
+	if kd.Key == "" {
+		return nil, fmt.Errorf("key name is undefined")
+	}
+	dataType := model.String
+	if kd.DataType != "" {
+		var err error
+		if dataType, err = model.NewDataType(kd.DataType); err != nil {
+			return nil, err
+		}
+	}
+	key := &model.KeyEntity{
+		Key:       kd.Key,
+		DataType:  dataType,
+		Encrypted: kd.Encrypted || dataType.IsSensitive(), //values of sensitive data types are always encrypted
+		Validator: kd.Validator,
+		Trigger:   kd.Trigger,
+		Username:  username,
+	}
+	return key, key.ValidateCode()
+}
+
+//valueAsString converts a value of the YAML document to its text representation
+func valueAsString(value interface{}) (string, error) {
+	switch typedValue := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return typedValue, nil
+	case bool:
+		return strconv.FormatBool(typedValue), nil
+	case json.Number:
+		return typedValue.String(), nil
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64), nil
+	default:
+		result, err := json.Marshal(typedValue)
+		return string(result), err
+	}
+}
+
+type ChangeAction string
+
+const (
+	ChangeActionCreate    ChangeAction = "create"
+	ChangeActionUpdate    ChangeAction = "update"
+	ChangeActionUnchanged ChangeAction = "unchanged"
+)
+
+//Change describes the difference between an entry of a document and the current state of the database
+type Change struct {
+	Action ChangeAction
+	Bucket string //empty for changes of keys
+	Key    string
+	Old    string //values of encrypted keys are masked
+	New    string //values of encrypted keys are masked
+	key    *model.KeyEntity
+	value  string
+}
+
+func (c *Change) String() string {
+	return fmt.Sprintf("Change [Action=%s,Bucket=%s,Key=%s]", c.Action, c.Bucket, c.Key)
+}
+
+//IsKeyChange returns true if the change affects a key and false if it affects a value
+func (c *Change) IsKeyChange() bool {
+	return c.Bucket == ""
+}
+
+type InvalidDocumentError struct {
+	Errors []error
+}
+
+func (e *InvalidDocumentError) Error() string {
+	var msgs []string
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("Document is invalid:\n%s", strings.Join(msgs, "\n"))
+}
+
+func IsInvalidDocumentError(err error) bool {
+	_, ok := err.(*InvalidDocumentError)
+	return ok
+}
+
+//Export returns a document with the values of the buckets and the latest version of their keys. If no bucket is
+//provided, all buckets and keys are exported. Values of encrypted keys are only exported if includeEncrypted is set.
+func (cer *Repository) Export(buckets []string, includeEncrypted bool) (*Document, error) {
+	doc := &Document{}
+	keys := make(map[string]*model.KeyEntity)
+
+	if len(buckets) == 0 {
+		allKeys, err := cer.Keys()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range allKeys {
+			keys[key.Key] = key
+		}
+		if buckets, err = cer.bucketNames(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, bucket := range buckets {
+		values, err := cer.ValuesByBucket(bucket)
+		if err != nil {
+			return nil, err
+		}
+		bucketDoc := &BucketDocument{
+			Bucket: bucket,
+			Values: make(map[string]interface{}, len(values)),
+		}
+		for _, value := range values {
+			key, err := cer.Key(value.Key, value.KeyVersion)
+			if err != nil {
+				return nil, err
+			}
+			if key.Encrypted && !includeEncrypted {
+				cer.Logger.Warnf("Value of encrypted key '%s' in bucket '%s' is not exported", value.Key, bucket)
+				continue
+			}
+			plainValue, err := cer.PlainValue(key, value)
+			if err != nil {
+				return nil, err
+			}
+			bucketDoc.Values[value.Key] = plainValue
+			if _, ok := keys[key.Key]; !ok {
+				latestKey, err := cer.LatestKey(key.Key)
+				if err != nil {
+					return nil, err
+				}
+				keys[key.Key] = latestKey
+			}
+		}
+		doc.Buckets = append(doc.Buckets, bucketDoc)
+	}
+
+	for _, key := range keys {
+		doc.Keys = append(doc.Keys, newKeyDocument(key))
+	}
+	sort.Slice(doc.Keys, func(i, j int) bool {
+		return doc.Keys[i].Key < doc.Keys[j].Key
+	})
+
+	return doc, nil
+}
+
+//Diff validates the document and returns the changes which an import of it would apply
+func (cer *Repository) Diff(doc *Document) ([]*Change, error) {
+	return cer.diff(doc, "")
+}
+
+//Import validates the document and creates new versions of all changed keys and values within one transaction.
+//Keys and values which are not part of the document remain unchanged.
+func (cer *Repository) Import(doc *Document, username string) ([]*Change, error) {
+	changes, err := cer.diff(doc, username)
+	if err != nil {
+		return nil, err
+	}
+
+	dbOps := func(tx *db.Tx) error {
+		txRepo := cer.WithTx(tx)
+		//keys have to be created first: values are mapped to the latest key version
+		for _, change := range changes {
+			if change.IsKeyChange() && change.Action != ChangeActionUnchanged {
+				if _, err := txRepo.CreateKey(change.key); err != nil {
+					return err
+				}
+			}
+		}
+		for _, change := range changes {
+			if change.IsKeyChange() || change.Action == ChangeActionUnchanged {
+				continue
+			}
+			key, err := txRepo.LatestKey(change.Key)
+			if err != nil {
+				return err
+			}
+			if _, err := txRepo.CreateValue(&model.ValueEntity{
+				Bucket:     change.Bucket,
+				Key:        key.Key,
+				KeyVersion: key.Version,
+				DataType:   key.DataType,
+				Value:      change.value,
+				Username:   username,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return changes, cer.Transactional(dbOps)
+}
+
+func (cer *Repository) diff(doc *Document, username string) ([]*Change, error) {
+	var changes []*Change
+	var errs []error
+
+	//verify keys
+	docKeys := make(map[string]*Change, len(doc.Keys))
+	for idx, keyDoc := range doc.Keys {
+		if _, ok := docKeys[keyDoc.Key]; ok {
+			errs = append(errs, fmt.Errorf("key '%s' is defined multiple times", keyDoc.Key))
+			continue
+		}
+		key, err := keyDoc.entity(username)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("key #%d '%s' is invalid: %s", idx+1, keyDoc.Key, err))
+			continue
+		}
+		change, err := cer.keyChange(key)
+		if err != nil {
+			return nil, err
+		}
+		docKeys[key.Key] = change
+		changes = append(changes, change)
+	}
+
+	//verify values
+	docBuckets := make(map[string]bool, len(doc.Buckets))
+	for _, bucketDoc := range doc.Buckets {
+		if err := model.ValidateBucketName(bucketDoc.Bucket); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if docBuckets[bucketDoc.Bucket] {
+			errs = append(errs, fmt.Errorf("bucket '%s' is defined multiple times", bucketDoc.Bucket))
+			continue
+		}
+		docBuckets[bucketDoc.Bucket] = true
+
+		keyNames := make([]string, 0, len(bucketDoc.Values))
+		for keyName := range bucketDoc.Values {
+			keyNames = append(keyNames, keyName)
+		}
+		sort.Strings(keyNames)
+
+		for _, keyName := range keyNames {
+			value, err := valueAsString(bucketDoc.Values[keyName])
+			if err != nil {
+				errs = append(errs, fmt.Errorf("value of key '%s' in bucket '%s' is invalid: %s", keyName, bucketDoc.Bucket, err))
+				continue
+			}
+			var keyChange *Change
+			if change, ok := docKeys[keyName]; ok {
+				keyChange = change
+			} else {
+				key, err := cer.LatestKey(keyName)
+				if err != nil {
+					if repository.IsNotFoundError(err) {
+						errs = append(errs, fmt.Errorf("key '%s' of value in bucket '%s' does not exist", keyName, bucketDoc.Bucket))
+						continue
+					}
+					return nil, err
+				}
+				keyChange = &Change{Action: ChangeActionUnchanged, Key: keyName, key: key}
+			}
+			if err := keyChange.key.Validate(value); err != nil {
+				errs = append(errs, fmt.Errorf("value of key '%s' in bucket '%s' is invalid: %s", keyName, bucketDoc.Bucket, err))
+				continue
+			}
+			change, err := cer.valueChange(bucketDoc.Bucket, keyChange, value)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, &InvalidDocumentError{Errors: errs}
+	}
+	return changes, nil
+}
+
+func (cer *Repository) keyChange(key *model.KeyEntity) (*Change, error) {
+	change := &Change{
+		Action: ChangeActionCreate,
+		Key:    key.Key,
+		New:    newKeyDocument(key).String(),
+		key:    key,
+	}
+	existingKey, err := cer.LatestKey(key.Key)
+	if err != nil {
+		if repository.IsNotFoundError(err) {
+			return change, nil
+		}
+		return nil, err
+	}
+	change.Old = newKeyDocument(existingKey).String()
+	if existingKey.Equal(key) {
+		change.Action = ChangeActionUnchanged
+		change.key = existingKey
+	} else {
+		change.Action = ChangeActionUpdate
+	}
+	return change, nil
+}
+
+func (cer *Repository) valueChange(bucket string, keyChange *Change, value string) (*Change, error) {
+	change := &Change{
+		Action: ChangeActionCreate,
+		Bucket: bucket,
+		Key:    keyChange.Key,
+		New:    keyChange.key.Mask(value),
+		value:  value,
+	}
+	existingValue, err := cer.LatestValue(bucket, keyChange.Key)
+	if err != nil {
+		if repository.IsNotFoundError(err) {
+			return change, nil
+		}
+		return nil, err
+	}
+	existingKey, err := cer.Key(existingValue.Key, existingValue.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+	plainValue, err := cer.PlainValue(existingKey, existingValue)
+	if err != nil {
+		return nil, err
+	}
+	change.Old = existingKey.Mask(plainValue)
+	//values are mapped to a new key version if the key changes
+	if keyChange.Action == ChangeActionUnchanged && existingValue.KeyVersion == keyChange.key.Version && plainValue == value {
+		change.Action = ChangeActionUnchanged
+	} else {
+		change.Action = ChangeActionUpdate
+	}
+	return change, nil
+}
@@ -0,0 +1,170 @@
+package kv
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument(t *testing.T) {
+	ceRepo := newKeyValueRepo(t)
+
+	ts := time.Now().UnixNano()
+	intKey := fmt.Sprintf("test.import.int%d", ts)
+	listKey := fmt.Sprintf("test.import.list%d", ts)
+	secretKey := fmt.Sprintf("test.import.secret%d", ts)
+	bucket := fmt.Sprintf("import-%d", ts)
+	defer func() {
+		for _, key := range []string{intKey, listKey, secretKey} {
+			require.NoError(t, ceRepo.DeleteKey(key))
+		}
+	}()
+
+	doc, err := NewDocument([]byte(fmt.Sprintf(`
+keys:
+- key: %s
+  dataType: integer
+  validator: it > 0
+- key: %s
+  dataType: list
+- key: %s
+  dataType: secret
+buckets:
+- bucket: %s
+  values:
+    %s: 10
+    %s: [a, b]
+    %s: myPassword
+`, intKey, listKey, secretKey, bucket, intKey, listKey, secretKey)))
+	require.NoError(t, err)
+
+	t.Run("Diff of new document", func(t *testing.T) {
+		changes, err := ceRepo.Diff(doc)
+		require.NoError(t, err)
+		require.Len(t, changes, 6)
+		for _, change := range changes {
+			require.Equal(t, ChangeActionCreate, change.Action)
+			require.NotContains(t, change.New, "myPassword")
+		}
+
+		//nothing was written
+		_, err = ceRepo.LatestKey(intKey)
+		require.Error(t, err)
+	})
+
+	t.Run("Import document", func(t *testing.T) {
+		changes, err := ceRepo.Import(doc, "testUsername")
+		require.NoError(t, err)
+		require.Len(t, changes, 6)
+
+		key, err := ceRepo.LatestKey(secretKey)
+		require.NoError(t, err)
+		require.True(t, key.Encrypted)
+
+		value, err := ceRepo.LatestValue(bucket, listKey)
+		require.NoError(t, err)
+		require.Equal(t, `["a","b"]`, value.Value)
+
+		value, err = ceRepo.LatestValue(bucket, intKey)
+		require.NoError(t, err)
+		require.Equal(t, "10", value.Value)
+		require.Equal(t, "testUsername", value.Username)
+	})
+
+	t.Run("Import document again", func(t *testing.T) {
+		changes, err := ceRepo.Import(doc, "testUsername")
+		require.NoError(t, err)
+		for _, change := range changes {
+			require.Equal(t, ChangeActionUnchanged, change.Action, change.String())
+		}
+	})
+
+	t.Run("Diff of changed document", func(t *testing.T) {
+		doc.Buckets[0].Values[intKey] = 20
+		changes, err := ceRepo.Diff(doc)
+		require.NoError(t, err)
+		var updated []*Change
+		for _, change := range changes {
+			if change.Action != ChangeActionUnchanged {
+				updated = append(updated, change)
+			}
+		}
+		require.Len(t, updated, 1)
+		require.Equal(t, bucket, updated[0].Bucket)
+		require.Equal(t, "10", updated[0].Old)
+		require.Equal(t, "20", updated[0].New)
+	})
+
+	t.Run("Invalid document is not imported", func(t *testing.T) {
+		doc.Buckets[0].Values[intKey] = -1                    //validator fails
+		doc.Buckets[0].Values["test.import.doesNotExist"] = 1 //key is missing
+		doc.Buckets[0].Values[secretKey] = "myNewPassword"
+		_, err := ceRepo.Import(doc, "testUsername")
+		require.Error(t, err)
+		require.True(t, IsInvalidDocumentError(err))
+		require.Len(t, err.(*InvalidDocumentError).Errors, 2)
+
+		//valid values were not imported
+		value, err := ceRepo.LatestValue(bucket, secretKey)
+		require.NoError(t, err)
+		key, err := ceRepo.LatestKey(secretKey)
+		require.NoError(t, err)
+		plainValue, err := ceRepo.PlainValue(key, value)
+		require.NoError(t, err)
+		require.Equal(t, "myPassword", plainValue)
+	})
+
+	t.Run("Export bucket", func(t *testing.T) {
+		exportedDoc, err := ceRepo.Export([]string{bucket}, false)
+		require.NoError(t, err)
+		require.Len(t, exportedDoc.Keys, 2)
+		require.Equal(t, intKey, exportedDoc.Keys[0].Key)
+		require.Equal(t, "it > 0", exportedDoc.Keys[0].Validator)
+		require.Equal(t, map[string]interface{}{
+			intKey:  "10",
+			listKey: `["a","b"]`,
+		}, exportedDoc.Buckets[0].Values) //encrypted values are excluded
+
+		exportedDoc, err = ceRepo.Export([]string{bucket}, true)
+		require.NoError(t, err)
+		require.Len(t, exportedDoc.Keys, 3)
+		require.Equal(t, "myPassword", exportedDoc.Buckets[0].Values[secretKey])
+
+		//exported document can be imported without changes
+		data, err := exportedDoc.YAML()
+		require.NoError(t, err)
+		importDoc, err := NewDocument(data)
+		require.NoError(t, err)
+		changes, err := ceRepo.Diff(importDoc)
+		require.NoError(t, err)
+		for _, change := range changes {
+			require.Equal(t, ChangeActionUnchanged, change.Action, change.String())
+		}
+	})
+}
+
+func TestNewDocumentKeepsNumbers(t *testing.T) {
+	doc, err := NewDocument([]byte(`
+buckets:
+- bucket: numbers
+  values:
+    big: 9007199254740993
+    float: 1.25
+    nested: {size: 9007199254740993}
+`))
+	require.NoError(t, err)
+
+	value, err := valueAsString(doc.Buckets[0].Values["big"])
+	require.NoError(t, err)
+	require.Equal(t, "9007199254740993", value)
+
+	value, err = valueAsString(doc.Buckets[0].Values["float"])
+	require.NoError(t, err)
+	require.Equal(t, "1.25", value)
+
+	value, err = valueAsString(doc.Buckets[0].Values["nested"])
+	require.NoError(t, err)
+	require.Equal(t, `{"size":9007199254740993}`, value)
+}
@@ -3,9 +3,11 @@ package kv
 import (
 	"fmt"
 
+	"github.com/kyma-incubator/reconciler/pkg/audit"
 	"github.com/kyma-incubator/reconciler/pkg/db"
 	"github.com/kyma-incubator/reconciler/pkg/model"
 	"github.com/kyma-incubator/reconciler/pkg/repository"
+	"github.com/pkg/errors"
 )
 
 type Repository struct {
@@ -20,6 +22,29 @@ func NewRepository(dbFac db.ConnectionFactory, debug bool) (*Repository, error)
 	return &Repository{repo}, nil
 }
 
+//WithTx returns a copy of the repository which executes all statements within the transaction
+func (cer *Repository) WithTx(tx *db.Tx) *Repository {
+	return &Repository{cer.Repository.WithTx(tx)}
+}
+
+//WithActor returns a copy of the repository which records its writes in the name of the actor
+func (cer *Repository) WithActor(actor string) *Repository {
+	return &Repository{cer.Repository.WithActor(actor)}
+}
+
+//audit records a write in the audit log (in the name of the actor of the repository)
+func (cer *Repository) audit(action model.AuditAction, entityType, target string, versionBefore, versionAfter int64, details string) error {
+	return audit.Record(cer.Conn, &model.AuditEntity{
+		Actor:         cer.Actor,
+		Action:        action,
+		EntityType:    entityType,
+		Target:        target,
+		VersionBefore: versionBefore,
+		VersionAfter:  versionAfter,
+		Details:       details,
+	})
+}
+
 func (cer *Repository) Keys() ([]*model.KeyEntity, error) {
 	entity := &model.KeyEntity{}
 	q, err := db.NewQuery(cer.Conn, entity)
@@ -27,24 +52,11 @@ func (cer *Repository) Keys() ([]*model.KeyEntity, error) {
 		return nil, err
 	}
 
-	//get fields used in sub-query
-	colHdlr, err := db.NewColumnHandler(entity, cer.Conn)
-	if err != nil {
-		return nil, err
-	}
-	colNameVersion, err := colHdlr.ColumnName("Version")
-	if err != nil {
-		return nil, err
-	}
-	colNameKey, err := colHdlr.ColumnName("Key")
-	if err != nil {
-		return nil, err
-	}
-
 	//query all keys
 	entities, err := q.Select().
-		WhereIn("Version", fmt.Sprintf("SELECT MAX(%s) FROM %s GROUP BY %s",
-			colNameVersion, entity.Table(), colNameKey)).
+		WhereCondition(db.InQuery("Version", q.Select().
+			Aggregate(db.AggregateMax, "Version").
+			GroupBy([]string{"Key"}))).
 		OrderBy(map[string]string{"Key": "ASC"}).
 		GetMany()
 	if err != nil {
@@ -128,10 +140,12 @@ func (cer *Repository) Key(key string, version int64) (*model.KeyEntity, error)
 }
 
 func (cer *Repository) CreateKey(key *model.KeyEntity) (*model.KeyEntity, error) {
-	q, err := db.NewQuery(cer.Conn, key)
-	if err != nil {
+	if err := key.ValidateCode(); err != nil {
 		return nil, err
 	}
+	if key.DataType.IsSensitive() { //values of sensitive data types are always encrypted
+		key.Encrypted = true
+	}
 	existingKey, err := cer.LatestKey(key.Key)
 	if err != nil && !repository.IsNotFoundError(err) {
 		return nil, err
@@ -140,19 +154,41 @@ func (cer *Repository) CreateKey(key *model.KeyEntity) (*model.KeyEntity, error)
 		cer.Logger.Debugf("No differences found for key '%s': not creating new database entity", key.Key)
 		return existingKey, nil
 	}
-	return key, q.Insert().Exec()
+
+	dbOps := func(tx *db.Tx) error {
+		txRepo := cer.WithTx(tx)
+		q, err := db.NewQuery(txRepo.Conn, key)
+		if err != nil {
+			return err
+		}
+		if err := q.Insert().Exec(); err != nil {
+			return err
+		}
+		action, versionBefore := model.AuditActionCreate, int64(0)
+		if existingKey != nil {
+			action, versionBefore = model.AuditActionUpdate, existingKey.Version
+		}
+		return txRepo.audit(action, model.AuditEntityKey, key.Key, versionBefore, key.Version, "")
+	}
+	return key, cer.Transactional(dbOps)
 }
 
 func (cer *Repository) DeleteKey(key string) error {
 	//bundle DB operations
-	dbOps := func() error {
+	dbOps := func(tx *db.Tx) error {
+		txRepo := cer.WithTx(tx)
+		versionBefore, err := txRepo.latestKeyVersion(key)
+		if err != nil {
+			return err
+		}
+
 		//delete all cache entities which were using a value of this key
-		if err := cer.CacheDep.Invalidate().WithKey(key).Exec(false); err != nil {
+		if err := txRepo.CacheDep.Invalidate().WithKey(key).WithClusterReconciliation().Exec(false); err != nil {
 			return err
 		}
 
 		//delete the values mapped to this key
-		q, err := db.NewQuery(cer.Conn, &model.ValueEntity{})
+		q, err := db.NewQuery(txRepo.Conn, &model.ValueEntity{})
 		if err != nil {
 			return err
 		}
@@ -164,48 +200,47 @@ func (cer *Repository) DeleteKey(key string) error {
 		}
 
 		//delete the key
-		qKey, err := db.NewQuery(cer.Conn, &model.KeyEntity{})
+		qKey, err := db.NewQuery(txRepo.Conn, &model.KeyEntity{})
 		if err != nil {
 			return err
 		}
 		_, err = qKey.Delete().
 			Where(map[string]interface{}{"Key": key}).
 			Exec()
-		return err
+		if err != nil {
+			return err
+		}
+		return txRepo.audit(model.AuditActionDelete, model.AuditEntityKey, key, versionBefore, 0, "")
 	}
 
 	return cer.Transactional(dbOps)
 }
 
-func (cer *Repository) ValuesByBucket(bucket string) ([]*model.ValueEntity, error) {
-	entity := &model.ValueEntity{}
-	q, err := db.NewQuery(cer.Conn, entity)
+//latestKeyVersion returns the latest version of the key or 0 if the key doesn't exist
+func (cer *Repository) latestKeyVersion(key string) (int64, error) {
+	latestKey, err := cer.LatestKey(key)
 	if err != nil {
-		return nil, err
+		if repository.IsNotFoundError(err) {
+			return 0, nil
+		}
+		return 0, err
 	}
+	return latestKey.Version, nil
+}
 
-	//get fields used in sub-query
-	colHdlr, err := db.NewColumnHandler(entity, cer.Conn)
-	if err != nil {
-		return nil, err
-	}
-	colVersion, err := colHdlr.ColumnName("Version")
-	if err != nil {
-		return nil, err
-	}
-	colKey, err := colHdlr.ColumnName("Key")
-	if err != nil {
-		return nil, err
-	}
-	colBucket, err := colHdlr.ColumnName("Bucket")
+func (cer *Repository) ValuesByBucket(bucket string) ([]*model.ValueEntity, error) {
+	entity := &model.ValueEntity{}
+	q, err := db.NewQuery(cer.Conn, entity)
 	if err != nil {
 		return nil, err
 	}
 
 	//query all values in bucket (return only the latest value-entry per key)
 	entities, err := q.Select().
-		WhereIn("Version", fmt.Sprintf("SELECT MAX(%s) FROM %s WHERE %s=$1 GROUP BY %s",
-			colVersion, entity.Table(), colBucket, colKey), bucket).
+		WhereCondition(db.InQuery("Version", q.Select().
+			Aggregate(db.AggregateMax, "Version").
+			Where(map[string]interface{}{"Bucket": bucket}).
+			GroupBy([]string{"Key"}))).
 		OrderBy(map[string]string{"Key": "ASC"}).
 		GetMany()
 	if err != nil {
@@ -227,32 +262,12 @@ func (cer *Repository) ValuesByKey(key *model.KeyEntity) ([]*model.ValueEntity,
 		return nil, err
 	}
 
-	//get fields used in sub-query
-	colHdlr, err := db.NewColumnHandler(entity, cer.Conn)
-	if err != nil {
-		return nil, err
-	}
-	colBucket, err := colHdlr.ColumnName("Bucket")
-	if err != nil {
-		return nil, err
-	}
-	colVersion, err := colHdlr.ColumnName("Version")
-	if err != nil {
-		return nil, err
-	}
-	colKey, err := colHdlr.ColumnName("Key")
-	if err != nil {
-		return nil, err
-	}
-	colKeyVersion, err := colHdlr.ColumnName("KeyVersion")
-	if err != nil {
-		return nil, err
-	}
-
-	//query all values in bucket (return only the latest value-entry per key)
+	//query all values of the key (return only the latest value-entry per bucket)
 	entities, err := q.Select().
-		WhereIn("Version", fmt.Sprintf("SELECT MAX(%s) FROM %s WHERE %s=$1 AND %s=$2 GROUP BY %s, %s",
-			colVersion, entity.Table(), colKey, colKeyVersion, colKey, colBucket), key.Key, key.Version).
+		WhereCondition(db.InQuery("Version", q.Select().
+			Aggregate(db.AggregateMax, "Version").
+			Where(map[string]interface{}{"Key": key.Key, "KeyVersion": key.Version}).
+			GroupBy([]string{"Key", "Bucket"}))).
 		OrderBy(map[string]string{"Bucket": "ASC"}).
 		GetMany()
 	if err != nil {
@@ -321,21 +336,27 @@ func (cer *Repository) Value(bucket, key string, version int64) (*model.ValueEnt
 }
 
 func (cer *Repository) CreateValue(value *model.ValueEntity) (*model.ValueEntity, error) {
-	existingValue, err := cer.LatestValue(value.Bucket, value.Key)
-	if err != nil && !repository.IsNotFoundError(err) {
-		return nil, err
-	}
-	if existingValue != nil && existingValue.Equal(value) {
-		cer.Logger.Debugf("No differences found for value of key '%s': not creating new database entity", value.Key)
-		return existingValue, nil
-	}
-
 	//validate value with metadata defined in key before storing it
 	key, err := cer.Key(value.Key, value.KeyVersion)
 	if err != nil {
 		return nil, err //provided key doesn't exist
 	}
 
+	existingValue, err := cer.LatestValue(value.Bucket, value.Key)
+	if err != nil && !repository.IsNotFoundError(err) {
+		return nil, err
+	}
+	if existingValue != nil {
+		equal, err := cer.equalValues(key, existingValue, value)
+		if err != nil {
+			return nil, err
+		}
+		if equal {
+			cer.Logger.Debugf("No differences found for value of key '%s': not creating new database entity", value.Key)
+			return existingValue, nil
+		}
+	}
+
 	if value.DataType == "" { //verify data-type is properly defined
 		value.DataType = key.DataType
 	} else if value.DataType != key.DataType {
@@ -349,10 +370,21 @@ func (cer *Repository) CreateValue(value *model.ValueEntity) (*model.ValueEntity
 		return nil, err //provided value is invalid
 	}
 
+	//values of encrypted keys are stored encrypted (the passed entity remains unchanged)
+	if key.Encrypted {
+		encValue := *value
+		encValue.Value, err = cer.Conn.Encryptor().Encrypt(value.Value)
+		if err != nil {
+			return nil, err
+		}
+		value = &encValue
+	}
+
 	//insert operation
-	dbOps := func() (interface{}, error) {
+	dbOps := func(tx *db.Tx) (interface{}, error) {
+		txRepo := cer.WithTx(tx)
 		//add value entity
-		q, err := db.NewQuery(cer.Conn, value)
+		q, err := db.NewQuery(txRepo.Conn, value)
 		if err != nil {
 			return nil, err
 		}
@@ -362,7 +394,22 @@ func (cer *Repository) CreateValue(value *model.ValueEntity) (*model.ValueEntity
 		}
 
 		//new value provided - invalidate caches which were using the old value
-		if err := cer.CacheDep.Invalidate().WithBucket(value.Bucket).WithKey(value.Key).Exec(false); err != nil {
+		if err := txRepo.CacheDep.Invalidate().WithBucket(value.Bucket).WithKey(value.Key).WithClusterReconciliation().Exec(false); err != nil {
+			return valueEntity, err
+		}
+		//first value of the key in this bucket - invalidate caches which were using the bucket
+		if existingValue == nil {
+			if err := txRepo.CacheDep.Invalidate().WithBucket(value.Bucket).WithKey(model.CacheDependencyBucket).WithClusterReconciliation().Exec(false); err != nil {
+				return valueEntity, err
+			}
+		}
+
+		action, versionBefore := model.AuditActionCreate, int64(0)
+		if existingValue != nil {
+			action, versionBefore = model.AuditActionUpdate, existingValue.Version
+		}
+		if err := txRepo.audit(action, model.AuditEntityValue, valueTarget(value.Bucket, value.Key),
+			versionBefore, valueEntity.Version, ""); err != nil {
 			return valueEntity, err
 		}
 
@@ -379,23 +426,59 @@ func (cer *Repository) CreateValue(value *model.ValueEntity) (*model.ValueEntity
 	return valueEntity, err
 }
 
+//equalValues compares the value with the latest existing value in the bucket: values of encrypted keys are compared in plain text
+func (cer *Repository) equalValues(key *model.KeyEntity, existingValue, value *model.ValueEntity) (bool, error) {
+	if existingValue.KeyVersion != value.KeyVersion {
+		return false, nil
+	}
+	plainValue, err := cer.PlainValue(key, existingValue)
+	if err != nil {
+		return false, err
+	}
+	return plainValue == value.Value, nil
+}
+
+//PlainValue returns the value in plain text: values of encrypted keys get decrypted
+func (cer *Repository) PlainValue(key *model.KeyEntity, value *model.ValueEntity) (string, error) {
+	if !key.Encrypted {
+		return value.Value, nil
+	}
+	plainValue, err := cer.Conn.Encryptor().Decrypt(value.Value)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("failed to decrypt value of key '%s' in bucket '%s'", value.Key, value.Bucket))
+	}
+	return plainValue, nil
+}
+
 func (cer *Repository) DeleteValue(key, bucket string) error {
 	//bundle DB operations
-	dbOps := func() error {
+	dbOps := func(tx *db.Tx) error {
+		txRepo := cer.WithTx(tx)
+		versionBefore := int64(0)
+		latestValue, err := txRepo.LatestValue(bucket, key)
+		if err == nil {
+			versionBefore = latestValue.Version
+		} else if !repository.IsNotFoundError(err) {
+			return err
+		}
+
 		//delete all cache entities which were using a value of this key in this bucket
-		if err := cer.CacheDep.Invalidate().WithKey(key).WithBucket(bucket).Exec(false); err != nil {
+		if err := txRepo.CacheDep.Invalidate().WithKey(key).WithBucket(bucket).WithClusterReconciliation().Exec(false); err != nil {
 			return err
 		}
 
 		//delete the values mapped to this key in this bucket
-		q, err := db.NewQuery(cer.Conn, &model.ValueEntity{})
+		q, err := db.NewQuery(txRepo.Conn, &model.ValueEntity{})
 		if err != nil {
 			return err
 		}
 		_, err = q.Delete().
 			Where(map[string]interface{}{"Key": key, "Bucket": bucket}).
 			Exec()
-		return err
+		if err != nil {
+			return err
+		}
+		return txRepo.audit(model.AuditActionDelete, model.AuditEntityValue, valueTarget(bucket, key), versionBefore, 0, "")
 	}
 
 	return cer.Transactional(dbOps)
@@ -429,22 +512,20 @@ func (cer *Repository) Buckets() ([]*model.BucketEntity, error) {
 }
 
 func (cer *Repository) bucketNames() ([]string, error) {
-	entity := &model.BucketEntity{}
-
-	colHdlr, err := db.NewColumnHandler(entity, cer.Conn)
-	if err != nil {
-		return nil, err
-	}
-
-	colName, err := colHdlr.ColumnName("Bucket")
+	q, err := db.NewQuery(cer.Conn, &model.BucketEntity{})
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := cer.Conn.Query(fmt.Sprintf("SELECT %s FROM %s GROUP BY %s ORDER BY %s ASC", colName, entity.Table(), colName, colName))
+	rows, err := q.Select().
+		Columns("Bucket").
+		GroupBy([]string{"Bucket"}).
+		OrderBy(map[string]string{"Bucket": "ASC"}).
+		Rows()
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var bucketNames []string
 	for rows.Next() {
@@ -455,29 +536,39 @@ func (cer *Repository) bucketNames() ([]string, error) {
 		bucketNames = append(bucketNames, bucket)
 	}
 
-	return bucketNames, nil
+	return bucketNames, rows.Err()
 }
 
 func (cer *Repository) DeleteBucket(bucket string) error {
-	dbOps := func() error {
+	dbOps := func(tx *db.Tx) error {
+		txRepo := cer.WithTx(tx)
 		//invalidate all cache entities which were using values from this bucket
-		if err := cer.CacheDep.Invalidate().WithBucket(bucket).Exec(false); err != nil {
+		if err := txRepo.CacheDep.Invalidate().WithBucket(bucket).WithClusterReconciliation().Exec(false); err != nil {
 			return err
 		}
 
 		//delete the bucket
-		q, err := db.NewQuery(cer.Conn, &model.BucketEntity{})
+		q, err := db.NewQuery(txRepo.Conn, &model.BucketEntity{})
 		if err != nil {
 			return err
 		}
-		_, err = q.Delete().
+		deleted, err := q.Delete().
 			Where(map[string]interface{}{"Bucket": bucket}).
 			Exec()
-		return err
+		if err != nil {
+			return err
+		}
+		return txRepo.audit(model.AuditActionDelete, model.AuditEntityBucket, bucket, 0, 0,
+			fmt.Sprintf("%d values deleted", deleted))
 	}
 	return cer.Transactional(dbOps)
 }
 
+//valueTarget identifies a value in the audit log
+func valueTarget(bucket, key string) string {
+	return fmt.Sprintf("%s/%s", bucket, key)
+}
+
 func (cer *Repository) Close() error {
 	return cer.Conn.Close()
 }
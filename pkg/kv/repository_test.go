@@ -336,6 +336,58 @@ func TestRepositoryValues(t *testing.T) {
 	})
 }
 
+func TestRepositoryEncryptedValues(t *testing.T) {
+	ceRepo := newKeyValueRepo(t)
+
+	//keys of sensitive data types are always encrypted
+	keyEntity, err := ceRepo.CreateKey(&model.KeyEntity{
+		Key:      fmt.Sprintf("testSecretKey%d", time.Now().UnixNano()),
+		DataType: model.Secret,
+		Username: "testUsername",
+	})
+	require.NoError(t, err)
+	require.True(t, keyEntity.Encrypted)
+	defer func() {
+		require.NoError(t, ceRepo.DeleteKey(keyEntity.Key))
+	}()
+
+	valueEntity := &model.ValueEntity{
+		Key:        keyEntity.Key,
+		KeyVersion: keyEntity.Version,
+		Bucket:     "test-secrets",
+		Value:      "myPassword",
+		Username:   "testUsername",
+	}
+
+	t.Run("Store value encrypted", func(t *testing.T) {
+		storedValue, err := ceRepo.CreateValue(valueEntity)
+		require.NoError(t, err)
+		require.NotEqual(t, "myPassword", storedValue.Value)
+		require.Equal(t, "myPassword", valueEntity.Value) //passed entity is unchanged
+
+		latestValue, err := ceRepo.LatestValue(valueEntity.Bucket, valueEntity.Key)
+		require.NoError(t, err)
+		require.Equal(t, storedValue.Value, latestValue.Value)
+
+		plainValue, err := ceRepo.PlainValue(keyEntity, latestValue)
+		require.NoError(t, err)
+		require.Equal(t, "myPassword", plainValue)
+	})
+
+	t.Run("Create existing encrypted value", func(t *testing.T) {
+		latestValue, err := ceRepo.LatestValue(valueEntity.Bucket, valueEntity.Key)
+		require.NoError(t, err)
+		sameValue, err := ceRepo.CreateValue(valueEntity)
+		require.NoError(t, err)
+		require.Equal(t, latestValue.Version, sameValue.Version) //ensure no new entity was created
+
+		valueEntity.Value = "myNewPassword"
+		newValue, err := ceRepo.CreateValue(valueEntity)
+		require.NoError(t, err)
+		require.Greater(t, newValue.Version, latestValue.Version)
+	})
+}
+
 func newKeyValueRepo(t *testing.T) *Repository {
 	connFact, err := db.NewTestConnectionFactory()
 	require.NoError(t, err)
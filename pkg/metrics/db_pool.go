@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type dbStatsProvider interface {
+	Stats() sql.DBStats
+}
+
+// DBPoolCollector provides the statistics of the database connection pool:
+// - reconciler_db_pool_max_open_connections - maximum number of open connections
+// - reconciler_db_pool_open_connections - number of established connections (in use and idle)
+// - reconciler_db_pool_in_use_connections - number of connections currently in use
+// - reconciler_db_pool_idle_connections - number of idle connections
+// - reconciler_db_pool_wait_count_total - total number of connections waited for
+// - reconciler_db_pool_wait_duration_seconds_total - total time blocked waiting for a new connection
+// - reconciler_db_pool_closed_total{"reason"} - total number of connections closed by the pool
+type DBPoolCollector struct {
+	statsProvider dbStatsProvider
+
+	maxOpenDesc      *prometheus.Desc
+	openDesc         *prometheus.Desc
+	inUseDesc        *prometheus.Desc
+	idleDesc         *prometheus.Desc
+	waitCountDesc    *prometheus.Desc
+	waitDurationDesc *prometheus.Desc
+	closedDesc       *prometheus.Desc
+}
+
+func NewDBPoolCollector(statsProvider dbStatsProvider) *DBPoolCollector {
+	newDesc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("", prometheusSubsystem, "db_pool_"+name), help, labels, nil)
+	}
+	return &DBPoolCollector{
+		statsProvider:    statsProvider,
+		maxOpenDesc:      newDesc("max_open_connections", "Maximum number of open connections to the database"),
+		openDesc:         newDesc("open_connections", "Number of established connections both in use and idle"),
+		inUseDesc:        newDesc("in_use_connections", "Number of connections currently in use"),
+		idleDesc:         newDesc("idle_connections", "Number of idle connections"),
+		waitCountDesc:    newDesc("wait_count_total", "Total number of connections waited for"),
+		waitDurationDesc: newDesc("wait_duration_seconds_total", "Total time blocked waiting for a new connection"),
+		closedDesc:       newDesc("closed_total", "Total number of connections closed by the pool", "reason"),
+	}
+}
+
+func (c *DBPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpenDesc
+	ch <- c.openDesc
+	ch <- c.inUseDesc
+	ch <- c.idleDesc
+	ch <- c.waitCountDesc
+	ch <- c.waitDurationDesc
+	ch <- c.closedDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *DBPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.statsProvider.Stats()
+	ch <- prometheus.MustNewConstMetric(c.maxOpenDesc, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.openDesc, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUseDesc, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idleDesc, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCountDesc, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.closedDesc, prometheus.CounterValue, float64(stats.MaxIdleClosed), "max_idle")
+	ch <- prometheus.MustNewConstMetric(c.closedDesc, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed), "max_idle_time")
+	ch <- prometheus.MustNewConstMetric(c.closedDesc, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), "max_lifetime")
+}
@@ -2,12 +2,14 @@ package metrics
 
 import (
 	"github.com/kyma-incubator/reconciler/pkg/cluster"
+	"github.com/kyma-incubator/reconciler/pkg/db"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
-func RegisterAll(inventory cluster.Inventory, logger *zap.SugaredLogger) {
+func RegisterAll(inventory cluster.Inventory, connFact db.ConnectionFactory, logger *zap.SugaredLogger) {
 	reconciliationWaitingCollector := NewReconciliationWaitingCollector(inventory, logger)
 	reconciliationNotReadyCollector := NewReconciliationNotReadyCollector(inventory, logger)
-	prometheus.MustRegister(reconciliationWaitingCollector, reconciliationNotReadyCollector)
+	dbPoolCollector := NewDBPoolCollector(connFact)
+	prometheus.MustRegister(reconciliationWaitingCollector, reconciliationNotReadyCollector, dbPoolCollector)
 }
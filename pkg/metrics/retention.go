@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"github.com/kyma-incubator/reconciler/pkg/cluster"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RetentionCollector provides the following metrics:
+// - reconciler_retention_purged_rows_total{"entity"} - total number of rows removed by the retention job
+// - reconciler_retention_runs_total - total number of executed retention runs
+type RetentionCollector struct {
+	purgedRowsCounter *prometheus.CounterVec
+	runsCounter       prometheus.Counter
+}
+
+func NewRetentionCollector() *RetentionCollector {
+	collector := &RetentionCollector{
+		purgedRowsCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: prometheusSubsystem,
+			Name:      "retention_purged_rows_total",
+			Help:      "Total number of rows removed by the retention job",
+		}, []string{"entity"}),
+		runsCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Subsystem: prometheusSubsystem,
+			Name:      "retention_runs_total",
+			Help:      "Total number of executed retention runs",
+		}),
+	}
+	prometheus.MustRegister(collector)
+	return collector
+}
+
+func (c *RetentionCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.purgedRowsCounter.Describe(ch)
+	c.runsCounter.Describe(ch)
+}
+
+func (c *RetentionCollector) Collect(ch chan<- prometheus.Metric) {
+	c.purgedRowsCounter.Collect(ch)
+	c.runsCounter.Collect(ch)
+}
+
+func (c *RetentionCollector) OnPurge(result *cluster.PurgeResult) {
+	c.runsCounter.Inc()
+	c.purgedRowsCounter.WithLabelValues("cluster").Add(float64(result.Clusters))
+	c.purgedRowsCounter.WithLabelValues("configuration").Add(float64(result.Configurations))
+	c.purgedRowsCounter.WithLabelValues("status").Add(float64(result.Statuses))
+	c.purgedRowsCounter.WithLabelValues("operation").Add(float64(result.Operations))
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/db"
+)
+
+const tblAuditLog string = "audit_log"
+
+//AuditAction is the kind of write operation which was applied to an entity
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+	AuditActionPurge  AuditAction = "purge"
+)
+
+//Types of entities which are tracked in the audit log
+const (
+	AuditEntityKey        = "key"
+	AuditEntityValue      = "value"
+	AuditEntityBucket     = "bucket"
+	AuditEntityBucketRule = "bucketRule"
+	AuditEntityCluster    = "cluster"
+	AuditEntityStatus     = "clusterStatus"
+	AuditEntityInventory  = "inventory"
+	AuditEntityOperation  = "operation"
+)
+
+//AuditEntity records who (actor) applied which write operation (action) on an entity (target). The versions
+//refer to the entity before and after the write (0 if the entity didn't exist before or was removed).
+type AuditEntity struct {
+	ID            int64       `db:"readOnly"`
+	Actor         string      `db:"notNull"`
+	Action        AuditAction `db:"notNull"`
+	EntityType    string      `db:"notNull"`
+	Target        string      `db:"notNull"`
+	VersionBefore int64
+	VersionAfter  int64
+	Details       string
+	Created       time.Time `db:"readOnly"`
+}
+
+func (a *AuditEntity) String() string {
+	return fmt.Sprintf("AuditEntity [ID=%d,Actor=%s,Action=%s,EntityType=%s,Target=%s,VersionBefore=%d,VersionAfter=%d]",
+		a.ID, a.Actor, a.Action, a.EntityType, a.Target, a.VersionBefore, a.VersionAfter)
+}
+
+func (a *AuditEntity) New() db.DatabaseEntity {
+	return &AuditEntity{}
+}
+
+func (a *AuditEntity) Marshaller() *db.EntityMarshaller {
+	marshaller := db.NewEntityMarshaller(&a)
+	marshaller.AddUnmarshaller("Action", func(value interface{}) (interface{}, error) {
+		return AuditAction(fmt.Sprintf("%v", value)), nil
+	})
+	marshaller.AddUnmarshaller("Created", convertTimestampToTime)
+	return marshaller
+}
+
+func (a *AuditEntity) Table() string {
+	return tblAuditLog
+}
+
+func (a *AuditEntity) Equal(other db.DatabaseEntity) bool {
+	if other == nil {
+		return false
+	}
+	otherAudit, ok := other.(*AuditEntity)
+	if ok {
+		return a.Actor == otherAudit.Actor &&
+			a.Action == otherAudit.Action &&
+			a.EntityType == otherAudit.EntityType &&
+			a.Target == otherAudit.Target &&
+			a.VersionBefore == otherAudit.VersionBefore &&
+			a.VersionAfter == otherAudit.VersionAfter &&
+			a.Details == otherAudit.Details
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/keb"
+)
+
+const tblBucketRules string = "config_bucket_rules"
+
+//Fields of a cluster which can be used in a bucket selector
+const (
+	SelectorGlobalAccountID    = "globalAccountID"
+	SelectorSubAccountID       = "subAccountID"
+	SelectorServicePlanID      = "servicePlanID"
+	SelectorShootName          = "shootName"
+	SelectorRuntimeName        = "runtimeName"
+	SelectorRuntimeDescription = "runtimeDescription"
+)
+
+var selectorFields = []string{
+	SelectorGlobalAccountID,
+	SelectorSubAccountID,
+	SelectorServicePlanID,
+	SelectorShootName,
+	SelectorRuntimeName,
+	SelectorRuntimeDescription,
+}
+
+//BucketSelector maps a cluster field to a glob pattern (see path.Match).
+//A cluster is selected if all patterns match. An empty selector matches any cluster.
+type BucketSelector map[string]string
+
+func (s BucketSelector) Validate() error {
+	for field, pattern := range s {
+		if !isSelectorField(field) {
+			return fmt.Errorf("selector field '%s' is not supported (supported fields are: %s)",
+				field, strings.Join(selectorFields, ", "))
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("selector pattern '%s' of field '%s' is invalid: %s", pattern, field, err)
+		}
+	}
+	return nil
+}
+
+func (s BucketSelector) Matches(metadata *keb.Metadata, runtime *keb.RuntimeInput) bool {
+	if metadata == nil {
+		metadata = &keb.Metadata{}
+	}
+	if runtime == nil {
+		runtime = &keb.RuntimeInput{}
+	}
+	clusterFields := map[string]string{
+		SelectorGlobalAccountID:    metadata.GlobalAccountID,
+		SelectorSubAccountID:       metadata.SubAccountID,
+		SelectorServicePlanID:      metadata.ServicePlanID,
+		SelectorShootName:          metadata.ShootName,
+		SelectorRuntimeName:        runtime.Name,
+		SelectorRuntimeDescription: runtime.Description,
+	}
+	for field, pattern := range s {
+		if matched, err := path.Match(pattern, clusterFields[field]); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func isSelectorField(field string) bool {
+	for _, selectorField := range selectorFields {
+		if field == selectorField {
+			return true
+		}
+	}
+	return false
+}
+
+//BucketRuleEntity assigns an ordered list of buckets to the clusters matching its selector.
+//The buckets of all matching rules are merged in the order of the rule priorities (ascending):
+//values of a bucket assigned by a higher prioritised rule override the values of lower prioritised rules.
+type BucketRuleEntity struct {
+	Rule     string `db:"notNull"`
+	Version  int64  `db:"readOnly"`
+	Priority int64
+	Selector string    `db:"notNull"`
+	Buckets  string    `db:"notNull"`
+	Username string    `db:"notNull"`
+	Deleted  bool      `db:"notNull"`
+	Created  time.Time `db:"readOnly"`
+}
+
+func NewBucketRuleEntity(rule string, priority int64, selector BucketSelector, buckets []string, username string) (*BucketRuleEntity, error) {
+	if selector == nil {
+		selector = BucketSelector{}
+	}
+	selectorJSON, err := json.Marshal(selector)
+	if err != nil {
+		return nil, err
+	}
+	bucketsJSON, err := json.Marshal(buckets)
+	if err != nil {
+		return nil, err
+	}
+	entity := &BucketRuleEntity{
+		Rule:     rule,
+		Priority: priority,
+		Selector: string(selectorJSON),
+		Buckets:  string(bucketsJSON),
+		Username: username,
+	}
+	return entity, entity.Validate()
+}
+
+func (br *BucketRuleEntity) Validate() error {
+	if br.Rule == "" {
+		return fmt.Errorf("bucket rule has no name")
+	}
+	selector, err := br.GetSelector()
+	if err != nil {
+		return err
+	}
+	if err := selector.Validate(); err != nil {
+		return fmt.Errorf("selector of bucket rule '%s' is invalid: %s", br.Rule, err)
+	}
+	buckets, err := br.GetBuckets()
+	if err != nil {
+		return err
+	}
+	if len(buckets) == 0 {
+		return fmt.Errorf("bucket rule '%s' has no buckets", br.Rule)
+	}
+	for _, bucket := range buckets {
+		if bucket == "" {
+			return fmt.Errorf("bucket rule '%s' contains an empty bucket name", br.Rule)
+		}
+	}
+	return nil
+}
+
+func (br *BucketRuleEntity) GetSelector() (BucketSelector, error) {
+	selector := BucketSelector{}
+	if br.Selector == "" {
+		return selector, nil
+	}
+	if err := json.Unmarshal([]byte(br.Selector), &selector); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal selector of bucket rule '%s': %s", br.Rule, err)
+	}
+	return selector, nil
+}
+
+func (br *BucketRuleEntity) GetBuckets() ([]string, error) {
+	var buckets []string
+	if br.Buckets == "" {
+		return buckets, nil
+	}
+	if err := json.Unmarshal([]byte(br.Buckets), &buckets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal buckets of bucket rule '%s': %s", br.Rule, err)
+	}
+	return buckets, nil
+}
+
+func (br *BucketRuleEntity) String() string {
+	return fmt.Sprintf("BucketRuleEntity [Rule=%s,Version=%d,Priority=%d,Selector=%s,Buckets=%s,Deleted=%t]",
+		br.Rule, br.Version, br.Priority, br.Selector, br.Buckets, br.Deleted)
+}
+
+func (br *BucketRuleEntity) New() db.DatabaseEntity {
+	return &BucketRuleEntity{}
+}
+
+func (br *BucketRuleEntity) Marshaller() *db.EntityMarshaller {
+	marshaller := db.NewEntityMarshaller(&br)
+	marshaller.AddUnmarshaller("Created", convertTimestampToTime)
+	return marshaller
+}
+
+func (br *BucketRuleEntity) Table() string {
+	return tblBucketRules
+}
+
+func (br *BucketRuleEntity) Equal(other db.DatabaseEntity) bool {
+	if other == nil {
+		return false
+	}
+	otherRule, ok := other.(*BucketRuleEntity)
+	if ok {
+		return br.Rule == otherRule.Rule &&
+			br.Priority == otherRule.Priority &&
+			br.Selector == otherRule.Selector &&
+			br.Buckets == otherRule.Buckets &&
+			br.Deleted == otherRule.Deleted
+	}
+	return false
+}
+
+//SortBucketRules orders the rules by priority (ascending) and name
+func SortBucketRules(rules []*BucketRuleEntity) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Priority == rules[j].Priority {
+			return rules[i].Rule < rules[j].Rule
+		}
+		return rules[i].Priority < rules[j].Priority
+	})
+}
@@ -9,6 +9,10 @@ import (
 
 const tblCacheDeps string = "config_cachedeps"
 
+//CacheDependencyBucket is the key of a cache dependency on a whole bucket: it invalidates the cache entry
+//if a value of a key which was not used so far is added to the bucket
+const CacheDependencyBucket = "*"
+
 type CacheDependencyEntity struct {
 	Bucket  string    `db:"notNull"`
 	Key     string    `db:"notNull"`
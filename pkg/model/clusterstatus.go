@@ -46,3 +46,45 @@ func NewClusterStatus(status Status) (*ClusterStatus, error) {
 	}
 	return clusterStatus, nil
 }
+
+//statusTransitions is the state machine of the cluster status: it defines which status can follow a status
+//(a new cluster configuration always starts with the status ReconcilePending, and the inventory watcher switches ready
+//clusters back to ReconcilePending when their reconcile interval passed)
+var statusTransitions = map[Status][]Status{
+	ClusterStatusReconcilePending: {ClusterStatusReconciling, ClusterStatusError},
+	ClusterStatusReconcileFailed:  {ClusterStatusReconciling, ClusterStatusError},
+	ClusterStatusReconciling:      {ClusterStatusReady, ClusterStatusError, ClusterStatusReconcileFailed},
+	ClusterStatusReady:            {ClusterStatusReconcilePending},
+	ClusterStatusError:            {ClusterStatusReconcilePending},
+}
+
+type InvalidStatusTransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *InvalidStatusTransitionError) Error() string {
+	return fmt.Sprintf("Cluster status cannot switch from '%s' to '%s'", e.From, e.To)
+}
+
+func IsInvalidStatusTransitionError(err error) bool {
+	_, ok := err.(*InvalidStatusTransitionError)
+	return ok
+}
+
+//ValidateStatusTransition verifies that the state machine allows switching from one status to another.
+//Keeping the current status is always allowed.
+func ValidateStatusTransition(from, to Status) error {
+	if _, err := NewClusterStatus(to); err != nil {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &InvalidStatusTransitionError{From: from, To: to}
+}
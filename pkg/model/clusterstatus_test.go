@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusTransition(t *testing.T) {
+	t.Run("Allowed transitions", func(t *testing.T) {
+		testCases := []struct {
+			from Status
+			to   Status
+		}{
+			{ClusterStatusReconcilePending, ClusterStatusReconciling},
+			{ClusterStatusReconcilePending, ClusterStatusReconcilePending},
+			{ClusterStatusReconcileFailed, ClusterStatusReconciling},
+			{ClusterStatusReconciling, ClusterStatusReady},
+			{ClusterStatusReconciling, ClusterStatusError},
+			{ClusterStatusReconciling, ClusterStatusReconcileFailed},
+			{ClusterStatusReady, ClusterStatusReconcilePending},
+			{ClusterStatusError, ClusterStatusReconcilePending},
+		}
+		for _, testCase := range testCases {
+			require.NoError(t, ValidateStatusTransition(testCase.from, testCase.to))
+		}
+	})
+
+	t.Run("Forbidden transitions", func(t *testing.T) {
+		testCases := []struct {
+			from Status
+			to   Status
+		}{
+			{ClusterStatusReconcilePending, ClusterStatusReady},
+			{ClusterStatusReady, ClusterStatusReconciling},
+			{ClusterStatusReady, ClusterStatusError},
+			{ClusterStatusError, ClusterStatusReady},
+			{ClusterStatusError, ClusterStatusReconciling},
+		}
+		for _, testCase := range testCases {
+			err := ValidateStatusTransition(testCase.from, testCase.to)
+			require.Error(t, err)
+			require.True(t, IsInvalidStatusTransitionError(err))
+		}
+	})
+
+	t.Run("Unknown status", func(t *testing.T) {
+		err := ValidateStatusTransition(ClusterStatusReconciling, "foo")
+		require.Error(t, err)
+		require.False(t, IsInvalidStatusTransitionError(err))
+	})
+}
@@ -1,32 +1,62 @@
 package model
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
 )
 
 const (
-	String  DataType = "string"
-	Integer DataType = "integer"
-	Boolean DataType = "boolean"
+	String   DataType = "string"
+	Integer  DataType = "integer"
+	Boolean  DataType = "boolean"
+	Float    DataType = "float"
+	Duration DataType = "duration"
+	List     DataType = "list"
+	Map      DataType = "map"
+	YAML     DataType = "yaml"
+	Secret   DataType = "secret"
 )
 
+//dataTypeAliases are alternative names of data types
+var dataTypeAliases = map[string]DataType{
+	"json": Map,
+}
+
 type DataType string
 
+//DataTypes returns all supported data types
+func DataTypes() []DataType {
+	return []DataType{String, Integer, Boolean, Float, Duration, List, Map, YAML, Secret}
+}
+
 func NewDataType(dataType string) (DataType, error) {
-	switch strings.ToLower(dataType) {
-	case string(String):
-		return String, nil
-	case string(Integer):
-		return Integer, nil
-	case string(Boolean):
-		return Boolean, nil
-	default:
-		return "", fmt.Errorf("DataType '%s' is not supported", dataType)
+	dataType = strings.ToLower(dataType)
+	for _, dt := range DataTypes() {
+		if string(dt) == dataType {
+			return dt, nil
+		}
+	}
+	if dt, ok := dataTypeAliases[dataType]; ok {
+		return dt, nil
 	}
+	return "", fmt.Errorf("DataType '%s' is not supported", dataType)
 }
 
+//Get converts the value into the Go type of the data type:
+//
+//  string, secret  string
+//  integer         int64
+//  boolean         bool
+//  float           float64
+//  duration        time.Duration (e.g. '1h30m')
+//  list            []interface{} (JSON/YAML array or comma-separated values)
+//  map             map[string]interface{} (JSON object)
+//  yaml            map[string]interface{}, []interface{} or scalar (any YAML document)
 func (dt DataType) Get(value string) (interface{}, error) {
 	var err error
 	var typedValue interface{}
@@ -41,12 +71,60 @@ func (dt DataType) Get(value string) (interface{}, error) {
 		if err != nil {
 			return typedValue, dt.fireParseError(value)
 		}
+	case Float:
+		typedValue, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return typedValue, dt.fireParseError(value)
+		}
+	case Duration:
+		typedValue, err = time.ParseDuration(value)
+		if err != nil {
+			return typedValue, dt.fireParseError(value)
+		}
+	case List:
+		typedValue, err = parseList(value)
+		if err != nil {
+			return typedValue, dt.fireParseError(value)
+		}
+	case Map:
+		var mapValue map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &mapValue); err != nil || mapValue == nil {
+			return typedValue, dt.fireParseError(value)
+		}
+		typedValue = mapValue
+	case YAML:
+		if err := yaml.Unmarshal([]byte(value), &typedValue); err != nil {
+			return typedValue, dt.fireParseError(value)
+		}
 	default:
 		typedValue = value
 	}
 	return typedValue, nil
 }
 
+//IsSensitive returns true if values of this data type must not be exposed
+func (dt DataType) IsSensitive() bool {
+	return dt == Secret
+}
+
 func (dt DataType) fireParseError(value string) error {
 	return fmt.Errorf("Value '%s' is not compatible with DataType '%s'", value, dt)
 }
+
+func parseList(value string) ([]interface{}, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		var list []interface{}
+		if err := yaml.Unmarshal([]byte(value), &list); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+	list := []interface{}{}
+	if strings.TrimSpace(value) == "" {
+		return list, nil
+	}
+	for _, item := range strings.Split(value, ",") {
+		list = append(list, strings.TrimSpace(item))
+	}
+	return list, nil
+}
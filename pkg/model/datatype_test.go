@@ -2,6 +2,7 @@ package model
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -22,6 +23,57 @@ func TestDataType(t *testing.T) {
 		dt, err = NewDataType("string")
 		require.NoError(t, err)
 		require.Equal(t, dt, String)
+
+		dt, err = NewDataType("JSON")
+		require.NoError(t, err)
+		require.Equal(t, dt, Map)
+
+		for _, dataType := range DataTypes() {
+			dt, err := NewDataType(string(dataType))
+			require.NoError(t, err)
+			require.Equal(t, dataType, dt)
+		}
+	})
+
+	t.Run("Get typed values", func(t *testing.T) {
+		testCases := []struct {
+			dataType DataType
+			value    string
+			expected interface{}
+		}{
+			{Float, "1.5", 1.5},
+			{Duration, "1h30m", 90 * time.Minute},
+			{List, "a, b,c", []interface{}{"a", "b", "c"}},
+			{List, "", []interface{}{}},
+			{List, `["a", 1]`, []interface{}{"a", float64(1)}},
+			{Map, `{"a": {"b": true}}`, map[string]interface{}{"a": map[string]interface{}{"b": true}}},
+			{YAML, "a:\n  b: [1, 2]", map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{float64(1), float64(2)}}}},
+			{YAML, "- foo\n- bar", []interface{}{"foo", "bar"}},
+			{Secret, "pwd", "pwd"},
+		}
+		for _, testCase := range testCases {
+			got, err := testCase.dataType.Get(testCase.value)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, got)
+		}
+	})
+
+	t.Run("Get invalid typed values", func(t *testing.T) {
+		testCases := []struct {
+			dataType DataType
+			value    string
+		}{
+			{Float, "abc"},
+			{Duration, "10"},
+			{List, "[a, b"},
+			{Map, "[1, 2]"},
+			{Map, "null"},
+			{YAML, "a: [b"},
+		}
+		for _, testCase := range testCases {
+			_, err := testCase.dataType.Get(testCase.value)
+			require.Error(t, err, "value '%s' should be invalid for data type '%s'", testCase.value, testCase.dataType)
+		}
 	})
 
 }
@@ -8,7 +8,10 @@ import (
 	"github.com/kyma-incubator/reconciler/pkg/interpreter"
 )
 
-const tblKeys string = "config_keys"
+const (
+	tblKeys     string = "config_keys"
+	maskedValue string = "***"
+)
 
 type KeyEntity struct {
 	Key       string   `db:"notNull"`
@@ -25,6 +28,9 @@ func (ke *KeyEntity) Validate(value string) error {
 	//ensure data type
 	typedValue, err := ke.DataType.Get(value)
 	if err != nil {
+		if ke.Encrypted {
+			return fmt.Errorf("Value of key '%s' is not compatible with DataType '%s'", ke.Key, ke.DataType)
+		}
 		return err
 	}
 
@@ -39,7 +45,7 @@ func (ke *KeyEntity) Validate(value string) error {
 		if !result {
 			return &InvalidValueError{
 				Key:       ke.Key,
-				Value:     value,
+				Value:     ke.Mask(value),
 				Validator: ke.Validator,
 				Result:    result,
 			}
@@ -49,6 +55,35 @@ func (ke *KeyEntity) Validate(value string) error {
 	return nil
 }
 
+//Mask hides the value if the key is encrypted
+func (ke *KeyEntity) Mask(value string) string {
+	if ke.Encrypted {
+		return maskedValue
+	}
+	return value
+}
+
+//ValidateCode verifies that the validator and trigger code can be compiled by the Go interpreter
+func (ke *KeyEntity) ValidateCode() error {
+	codes := map[string]string{
+		"validator": ke.Validator,
+		"trigger":   ke.Trigger,
+	}
+	for _, attribute := range []string{"validator", "trigger"} {
+		if codes[attribute] == "" {
+			continue
+		}
+		if err := interpreter.NewGolangInterpreter(codes[attribute]).Compile(); err != nil {
+			return &InvalidCodeError{
+				Key:       ke.Key,
+				Attribute: attribute,
+				Err:       err,
+			}
+		}
+	}
+	return nil
+}
+
 func (ke *KeyEntity) String() string {
 	return fmt.Sprintf("KeyEntity [Key=%s,Version=%d,DataType=%s,Encrypted=%t,User=%s]",
 		ke.Key, ke.Version, ke.DataType, ke.Encrypted, ke.Username)
@@ -99,3 +134,22 @@ func IsInvalidValueError(err error) bool {
 	_, ok := err.(*InvalidValueError)
 	return ok
 }
+
+type InvalidCodeError struct {
+	Key       string
+	Attribute string
+	Err       error
+}
+
+func (err *InvalidCodeError) Error() string {
+	return fmt.Sprintf("Code of %s defined in key '%s' is invalid:\n%s", err.Attribute, err.Key, err.Err)
+}
+
+func (err *InvalidCodeError) Unwrap() error {
+	return err.Err
+}
+
+func IsInvalidCodeError(err error) bool {
+	_, ok := err.(*InvalidCodeError)
+	return ok
+}
@@ -1,8 +1,11 @@
 package model
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/kyma-incubator/reconciler/pkg/interpreter"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -69,4 +72,58 @@ func TestKeyEntity(t *testing.T) {
 		require.Error(t, err)
 		require.False(t, IsInvalidValueError(err)) //is code error
 	})
+
+	t.Run("Validate complex types", func(t *testing.T) {
+		key := &KeyEntity{
+			Key:       "Mock",
+			DataType:  Duration,
+			Validator: "import \"time\"\nit >= time.Minute",
+		}
+		require.NoError(t, key.Validate("5m"))
+		require.True(t, IsInvalidValueError(key.Validate("30s")))
+
+		key = &KeyEntity{
+			Key:       "Mock",
+			DataType:  List,
+			Validator: `len(it) == 2 && it[0].(string) == "a"`,
+		}
+		require.NoError(t, key.Validate("a,b"))
+		require.True(t, IsInvalidValueError(key.Validate("a,b,c")))
+
+		key = &KeyEntity{
+			Key:       "Mock",
+			DataType:  Map,
+			Validator: `it["enabled"].(bool)`,
+		}
+		require.NoError(t, key.Validate(`{"enabled": true}`))
+		require.True(t, IsInvalidValueError(key.Validate(`{"enabled": false}`)))
+
+		key = &KeyEntity{
+			Key:       "Mock",
+			DataType:  Float,
+			Validator: `it > 0.5`,
+		}
+		require.NoError(t, key.Validate("0.75"))
+		require.True(t, IsInvalidValueError(key.Validate("0.25")))
+	})
+
+	t.Run("Validate code", func(t *testing.T) {
+		key := &KeyEntity{
+			Key:       "Mock",
+			DataType:  String,
+			Validator: `len(it) > 5`,
+			Trigger:   `restartPods("kyma-system", "app=test")`,
+		}
+		require.NoError(t, key.ValidateCode())
+
+		key.Trigger = `restartPods("kyma-system",`
+		err := key.ValidateCode()
+		require.Error(t, err)
+		require.True(t, IsInvalidCodeError(err))
+		require.Equal(t, "trigger", err.(*InvalidCodeError).Attribute)
+
+		var compileErr *interpreter.CompileError
+		require.True(t, errors.As(err, &compileErr))
+		require.Equal(t, 1, compileErr.Issues[0].Line)
+	})
 }
@@ -9,4 +9,5 @@ const (
 	OperationStateClientError = "client_error"
 	OperationStateError       = "error"
 	OperationStateFailed      = "failed"
+	OperationStateSuperseded  = "superseded"
 )
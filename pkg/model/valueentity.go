@@ -21,8 +21,12 @@ type ValueEntity struct {
 }
 
 func (ve *ValueEntity) String() string {
+	value := ve.Value
+	if ve.DataType.IsSensitive() {
+		value = maskedValue
+	}
 	return fmt.Sprintf("ValueEntity [Key=%s,KeyVersion=%d,Value=%s,Version=%d,Bucket=%s,DataType=%s,User=%s]",
-		ve.Key, ve.KeyVersion, ve.Value, ve.Version, ve.Bucket, ve.DataType, ve.Username)
+		ve.Key, ve.KeyVersion, value, ve.Version, ve.Bucket, ve.DataType, ve.Username)
 }
 
 func (ve *ValueEntity) New() db.DatabaseEntity {
@@ -0,0 +1,256 @@
+package chart
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	file "github.com/kyma-incubator/reconciler/pkg/files"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const (
+	prometheusSubsystem        = "reconciler"
+	defaultManifestCacheSize   = 500
+	manifestCacheFileExtension = ".yaml"
+	cacheTierMemory            = "memory"
+	cacheTierDisk              = "disk"
+)
+
+// ManifestCache is a content-addressed cache for rendered HELM manifests.
+// The cache key is a hash of the chart files, the component settings (name, namespace, profile, capabilities)
+// and the merged chart values. Entries are kept in a size-limited memory tier (LRU) and,
+// if a cache directory is defined, in a disk tier which survives restarts. Both tiers keep at most
+// maxEntries manifests: the disk tier drops the files which were least recently written or read.
+//
+// ManifestCache provides the following metrics:
+// - reconciler_manifest_cache_hits_total{"tier"}
+// - reconciler_manifest_cache_misses_total
+type ManifestCache struct {
+	cacheDir   string
+	maxEntries int
+	logger     *zap.SugaredLogger
+
+	entries map[string]*list.Element
+	lru     *list.List
+	mu      sync.Mutex
+
+	hitsCounter   *prometheus.CounterVec
+	missesCounter prometheus.Counter
+}
+
+type manifestCacheEntry struct {
+	key      string
+	manifest string
+}
+
+func NewManifestCache(cacheDir string, maxEntries int, logger *zap.SugaredLogger) (*ManifestCache, error) {
+	if maxEntries < 0 {
+		return nil, fmt.Errorf("max entries of manifest cache cannot be < 0 (got %d)", maxEntries)
+	}
+	if maxEntries == 0 {
+		maxEntries = defaultManifestCacheSize
+	}
+	if cacheDir != "" && !file.DirExists(cacheDir) {
+		if err := os.MkdirAll(cacheDir, 0700); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to create manifest cache directory '%s'", cacheDir))
+		}
+	}
+	return &ManifestCache{
+		cacheDir:   cacheDir,
+		maxEntries: maxEntries,
+		logger:     logger,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		hitsCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: prometheusSubsystem,
+			Name:      "manifest_cache_hits_total",
+			Help:      "Number of rendered manifests which were served from the manifest cache",
+		}, []string{"tier"}),
+		missesCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Subsystem: prometheusSubsystem,
+			Name:      "manifest_cache_misses_total",
+			Help:      "Number of manifests which were not found in the manifest cache and had to be rendered",
+		}),
+	}, nil
+}
+
+func (c *ManifestCache) Describe(ch chan<- *prometheus.Desc) {
+	c.hitsCounter.Describe(ch)
+	c.missesCounter.Describe(ch)
+}
+
+func (c *ManifestCache) Collect(ch chan<- prometheus.Metric) {
+	c.hitsCounter.Collect(ch)
+	c.missesCounter.Collect(ch)
+}
+
+// Get returns the cached manifest for the given key. The memory tier is checked first,
+// afterwards the disk tier. A manifest found on disk is promoted to the memory tier.
+func (c *ManifestCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		c.hitsCounter.WithLabelValues(cacheTierMemory).Inc()
+		return elem.Value.(*manifestCacheEntry).manifest, true
+	}
+
+	if c.cacheDir != "" {
+		data, err := ioutil.ReadFile(c.cacheFile(key))
+		if err == nil {
+			//the modification time marks the file as recently used for the eviction of the disk tier
+			now := time.Now()
+			if err := os.Chtimes(c.cacheFile(key), now, now); err != nil {
+				c.logger.Warnf("Failed to update modification time of manifest cache file '%s': %s", c.cacheFile(key), err)
+			}
+			c.addToMemory(key, string(data))
+			c.hitsCounter.WithLabelValues(cacheTierDisk).Inc()
+			return string(data), true
+		}
+		if !os.IsNotExist(err) {
+			c.logger.Warnf("Failed to read manifest cache file '%s': %s", c.cacheFile(key), err)
+		}
+	}
+
+	c.missesCounter.Inc()
+	return "", false
+}
+
+// Set stores the manifest in the memory tier and, if a cache directory is defined, in the disk tier.
+func (c *ManifestCache) Set(key, manifest string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.addToMemory(key, manifest)
+
+	if c.cacheDir == "" {
+		return nil
+	}
+
+	//write to a temporary file first to avoid that concurrent readers get an incomplete manifest
+	tmpFile, err := ioutil.TempFile(c.cacheDir, fmt.Sprintf("%s-*.tmp", key))
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary manifest cache file")
+	}
+	if _, err := tmpFile.WriteString(manifest); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		return errors.Wrap(err, fmt.Sprintf("failed to write manifest cache file '%s'", tmpFile.Name()))
+	}
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return err
+	}
+	if err := os.Rename(tmpFile.Name(), c.cacheFile(key)); err != nil {
+		return err
+	}
+	return c.evictFromDisk()
+}
+
+//evictFromDisk deletes the least recently used manifest files if the disk tier exceeds the max entries
+func (c *ManifestCache) evictFromDisk() error {
+	files, err := ioutil.ReadDir(c.cacheDir)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to list manifest cache directory '%s'", c.cacheDir))
+	}
+	var manifestFiles []os.FileInfo
+	for _, f := range files {
+		if !f.IsDir() && filepath.Ext(f.Name()) == manifestCacheFileExtension {
+			manifestFiles = append(manifestFiles, f)
+		}
+	}
+	if len(manifestFiles) <= c.maxEntries {
+		return nil
+	}
+	sort.Slice(manifestFiles, func(i, j int) bool {
+		return manifestFiles[i].ModTime().Before(manifestFiles[j].ModTime())
+	})
+	for _, f := range manifestFiles[:len(manifestFiles)-c.maxEntries] {
+		if err := os.Remove(filepath.Join(c.cacheDir, f.Name())); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, fmt.Sprintf("failed to delete manifest cache file '%s'", f.Name()))
+		}
+	}
+	return nil
+}
+
+func (c *ManifestCache) addToMemory(key, manifest string) {
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*manifestCacheEntry).manifest = manifest
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&manifestCacheEntry{key: key, manifest: manifest})
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*manifestCacheEntry).key)
+	}
+}
+
+func (c *ManifestCache) cacheFile(key string) string {
+	return filepath.Join(c.cacheDir, key+manifestCacheFileExtension)
+}
+
+// newManifestCacheKey calculates the content-addressed key of a rendered manifest.
+func newManifestCacheKey(helmChart *chart.Chart, component *Component, values map[string]interface{}) (string, error) {
+	hash := sha256.New()
+
+	if _, err := fmt.Fprintf(hash, "name=%s\nnamespace=%s\nprofile=%s\n",
+		component.name, component.namespace, component.profile); err != nil {
+		return "", err
+	}
+
+	if component.capabilities != nil {
+		apiVersions := make([]string, len(component.capabilities.APIVersions))
+		copy(apiVersions, component.capabilities.APIVersions)
+		sort.Strings(apiVersions)
+		if _, err := fmt.Fprintf(hash, "kubeVersion=%s\napiVersions=%s\n",
+			component.capabilities.KubeVersion.Version, strings.Join(apiVersions, ",")); err != nil {
+			return "", err
+		}
+	}
+
+	hashChartFiles(hash, helmChart)
+
+	//JSON encoding of maps is sorted by key which makes it deterministic
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("failed to marshal chart values of component '%s'", component.name))
+	}
+	if _, err := hash.Write(valuesJSON); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func hashChartFiles(hash io.Writer, helmChart *chart.Chart) {
+	files := make([]*chart.File, len(helmChart.Raw))
+	copy(files, helmChart.Raw)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	for _, f := range files {
+		_, _ = fmt.Fprintf(hash, "file=%s:%d\n", f.Name, len(f.Data))
+		_, _ = hash.Write(f.Data)
+	}
+	for _, dep := range helmChart.Dependencies() {
+		_, _ = fmt.Fprintf(hash, "dependency=%s\n", dep.Name())
+		hashChartFiles(hash, dep)
+	}
+}
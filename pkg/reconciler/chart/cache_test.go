@@ -0,0 +1,141 @@
+package chart
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	log "github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestCache(t *testing.T) {
+	logger, err := log.NewLogger(true)
+	require.NoError(t, err)
+
+	t.Run("Get and set manifest in memory tier", func(t *testing.T) {
+		cache, err := NewManifestCache("", 0, logger)
+		require.NoError(t, err)
+
+		_, ok := cache.Get("abc")
+		require.False(t, ok)
+		require.Equal(t, float64(1), testutil.ToFloat64(cache.missesCounter))
+
+		require.NoError(t, cache.Set("abc", "manifest"))
+		got, ok := cache.Get("abc")
+		require.True(t, ok)
+		require.Equal(t, "manifest", got)
+		require.Equal(t, float64(1), testutil.ToFloat64(cache.hitsCounter.WithLabelValues(cacheTierMemory)))
+	})
+
+	t.Run("Evict least recently used entries", func(t *testing.T) {
+		cache, err := NewManifestCache("", 2, logger)
+		require.NoError(t, err)
+
+		require.NoError(t, cache.Set("1", "manifest1"))
+		require.NoError(t, cache.Set("2", "manifest2"))
+		_, ok := cache.Get("1") //mark "1" as recently used
+		require.True(t, ok)
+		require.NoError(t, cache.Set("3", "manifest3"))
+
+		_, ok = cache.Get("2")
+		require.False(t, ok)
+		_, ok = cache.Get("1")
+		require.True(t, ok)
+		_, ok = cache.Get("3")
+		require.True(t, ok)
+	})
+
+	t.Run("Get manifest from disk tier", func(t *testing.T) {
+		cacheDir := t.TempDir()
+
+		cache1, err := NewManifestCache(cacheDir, 0, logger)
+		require.NoError(t, err)
+		require.NoError(t, cache1.Set("abc", "manifest"))
+
+		//new cache instance has an empty memory tier
+		cache2, err := NewManifestCache(cacheDir, 0, logger)
+		require.NoError(t, err)
+		got, ok := cache2.Get("abc")
+		require.True(t, ok)
+		require.Equal(t, "manifest", got)
+		require.Equal(t, float64(1), testutil.ToFloat64(cache2.hitsCounter.WithLabelValues(cacheTierDisk)))
+
+		//entry was promoted to memory tier
+		_, ok = cache2.Get("abc")
+		require.True(t, ok)
+		require.Equal(t, float64(1), testutil.ToFloat64(cache2.hitsCounter.WithLabelValues(cacheTierMemory)))
+	})
+
+	t.Run("Evict least recently used entries from disk tier", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		cache, err := NewManifestCache(cacheDir, 2, logger)
+		require.NoError(t, err)
+
+		require.NoError(t, cache.Set("1", "manifest1"))
+		require.NoError(t, cache.Set("2", "manifest2"))
+		require.NoError(t, cache.Set("3", "manifest3"))
+
+		files, err := ioutil.ReadDir(cacheDir)
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		require.NoFileExists(t, filepath.Join(cacheDir, "1"+manifestCacheFileExtension))
+	})
+
+	t.Run("Cache key depends on profile and configuration", func(t *testing.T) {
+		component1 := NewComponentBuilder("main", componentName).
+			WithNamespace("testNamespace").
+			Build()
+		component2 := NewComponentBuilder("main", componentName).
+			WithNamespace("testNamespace").
+			WithProfile(profileName).
+			Build()
+
+		helmChart := loadHelmChart(t, component1)
+
+		key1, err := newManifestCacheKey(helmChart, component1, map[string]interface{}{"a": "1", "b": "2"})
+		require.NoError(t, err)
+		key1Again, err := newManifestCacheKey(helmChart, component1, map[string]interface{}{"b": "2", "a": "1"})
+		require.NoError(t, err)
+		require.Equal(t, key1, key1Again)
+
+		key2, err := newManifestCacheKey(helmChart, component2, map[string]interface{}{"a": "1", "b": "2"})
+		require.NoError(t, err)
+		require.NotEqual(t, key1, key2)
+
+		key3, err := newManifestCacheKey(helmChart, component1, map[string]interface{}{"a": "1", "b": "3"})
+		require.NoError(t, err)
+		require.NotEqual(t, key1, key3)
+	})
+
+	t.Run("Render template using cache", func(t *testing.T) {
+		component := NewComponentBuilder("main", componentName).
+			WithNamespace("testNamespace").
+			WithProfile(profileName).
+			WithConfiguration([]reconciler.Configuration{
+				{
+					Key:   "config.key2",
+					Value: "value2 from component",
+				},
+			}).
+			Build()
+
+		cache, err := NewManifestCache(t.TempDir(), 0, logger)
+		require.NoError(t, err)
+
+		helm, err := NewHelmClient(chartDir, logger)
+		require.NoError(t, err)
+		helm.WithManifestCache(cache)
+
+		rendered, err := helm.Render(component)
+		require.NoError(t, err)
+		require.Equal(t, float64(1), testutil.ToFloat64(cache.missesCounter))
+
+		cached, err := helm.Render(component)
+		require.NoError(t, err)
+		require.Equal(t, rendered, cached)
+		require.Equal(t, float64(1), testutil.ToFloat64(cache.hitsCounter.WithLabelValues(cacheTierMemory)))
+	})
+}
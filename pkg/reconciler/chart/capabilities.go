@@ -0,0 +1,117 @@
+package chart
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyma-incubator/reconciler/pkg/reconciler/kubernetes/kubeclient"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+)
+
+type IncompatibleResourcesError struct {
+	Incompatibilities []string
+}
+
+func (err *IncompatibleResourcesError) Error() string {
+	return fmt.Sprintf("%d rendered resources are not supported by the cluster: %s",
+		len(err.Incompatibilities), strings.Join(err.Incompatibilities, ", "))
+}
+
+func IsIncompatibleResourcesError(err error) bool {
+	_, ok := errors.Cause(err).(*IncompatibleResourcesError)
+	return ok
+}
+
+//NewCapabilities retrieves the Kubernetes version and the available API resources of a cluster
+//by using its discovery API. The result can be used as HELM '.Capabilities' when rendering a chart.
+func NewCapabilities(discoveryClient discovery.DiscoveryInterface) (*chartutil.Capabilities, error) {
+	kubeVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get server version from Kubernetes")
+	}
+
+	apiVersions, err := action.GetVersionSet(discoveryClient)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		//a failed group discovery (e.g. caused by an orphaned API service) still returns all valid APIs
+		return nil, errors.Wrap(err, "could not get API versions from Kubernetes")
+	}
+
+	return &chartutil.Capabilities{
+		APIVersions: apiVersions,
+		KubeVersion: chartutil.KubeVersion{
+			Version: kubeVersion.GitVersion,
+			Major:   kubeVersion.Major,
+			Minor:   kubeVersion.Minor,
+		},
+	}, nil
+}
+
+//ValidateManifest verifies that the GroupVersionKind of each resource in the manifest is supported by the cluster.
+//Kinds defined by CRDs which are part of the manifest are considered as supported.
+//All incompatibilities are reported in one IncompatibleResourcesError.
+func ValidateManifest(manifest string, capabilities *chartutil.Capabilities) error {
+	unstructs, err := kubeclient.ToUnstructured([]byte(manifest), true)
+	if err != nil {
+		return err
+	}
+
+	crdKinds, err := customResourceKinds(unstructs)
+	if err != nil {
+		return err
+	}
+
+	var incompatibilities []string
+	for _, unstruct := range unstructs {
+		gvk := fmt.Sprintf("%s/%s", unstruct.GetAPIVersion(), unstruct.GetKind())
+		if capabilities.APIVersions.Has(gvk) || crdKinds[gvk] {
+			continue
+		}
+		incompatibilities = append(incompatibilities,
+			fmt.Sprintf("%s '%s' (apiVersion: %s)", unstruct.GetKind(), unstruct.GetName(), unstruct.GetAPIVersion()))
+	}
+
+	if len(incompatibilities) > 0 {
+		sort.Strings(incompatibilities)
+		return &IncompatibleResourcesError{Incompatibilities: incompatibilities}
+	}
+	return nil
+}
+
+//customResourceKinds returns the GroupVersionKinds (e.g. 'group/version/kind') defined by CRDs in the given resources
+func customResourceKinds(unstructs []*unstructured.Unstructured) (map[string]bool, error) {
+	result := make(map[string]bool)
+	for _, unstruct := range unstructs {
+		if unstruct.GetKind() != kindCRD {
+			continue
+		}
+		group, _, err := unstructured.NestedString(unstruct.Object, "spec", "group")
+		if err != nil {
+			return nil, err
+		}
+		kind, _, err := unstructured.NestedString(unstruct.Object, "spec", "names", "kind")
+		if err != nil {
+			return nil, err
+		}
+		versions, _, err := unstructured.NestedSlice(unstruct.Object, "spec", "versions")
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			versionMap, ok := version.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			result[fmt.Sprintf("%s/%v/%s", group, versionMap["name"], kind)] = true
+		}
+		//CRDs of API version 'apiextensions.k8s.io/v1beta1' can define a single version
+		if version, ok, _ := unstructured.NestedString(unstruct.Object, "spec", "version"); ok {
+			result[fmt.Sprintf("%s/%s/%s", group, version, kind)] = true
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package chart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCapabilities(t *testing.T) {
+	discoveryClient := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	discoveryClient.FakedServerVersion = &version.Info{
+		Major:      "1",
+		Minor:      "20",
+		GitVersion: "v1.20.4",
+	}
+	discoveryClient.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap"},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment"},
+			},
+		},
+		{
+			GroupVersion: "apiextensions.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "customresourcedefinitions", Kind: "CustomResourceDefinition"},
+			},
+		},
+	}
+
+	capabilities, err := NewCapabilities(discoveryClient)
+	require.NoError(t, err)
+
+	t.Run("Get capabilities of cluster", func(t *testing.T) {
+		require.Equal(t, "v1.20.4", capabilities.KubeVersion.Version)
+		require.Equal(t, "20", capabilities.KubeVersion.Minor)
+		require.True(t, capabilities.APIVersions.Has("apps/v1"))
+		require.True(t, capabilities.APIVersions.Has("apps/v1/Deployment"))
+		require.False(t, capabilities.APIVersions.Has("policy/v1beta1"))
+	})
+
+	t.Run("Validate supported manifest", func(t *testing.T) {
+		err := ValidateManifest(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: tests.example.com
+spec:
+  group: example.com
+  names:
+    kind: Test
+  versions:
+  - name: v1alpha1
+---
+apiVersion: example.com/v1alpha1
+kind: Test
+metadata:
+  name: test`, capabilities)
+		require.NoError(t, err)
+	})
+
+	t.Run("Validate unsupported manifest", func(t *testing.T) {
+		err := ValidateManifest(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy
+---
+apiVersion: policy/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: psp
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: ingress`, capabilities)
+		require.Error(t, err)
+		require.True(t, IsIncompatibleResourcesError(err))
+		require.Equal(t, []string{
+			"Ingress 'ingress' (apiVersion: extensions/v1beta1)",
+			"PodSecurityPolicy 'psp' (apiVersion: policy/v1beta1)",
+		}, err.(*IncompatibleResourcesError).Incompatibilities)
+	})
+}
@@ -1,44 +1,149 @@
 package chart
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/imdario/mergo"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler"
-	"strings"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chartutil"
 )
 
+//pathTokenRegex matches a single token of a dot-notated key, optionally followed by list indices (e.g. b[0][1])
+var pathTokenRegex = regexp.MustCompile(`^([^\[\]]+)((?:\[\d+\])*)$`)
+
 type Component struct {
 	version       string
 	name          string
 	profile       string
 	namespace     string
-	configuration map[string]interface{}
+	values        []string
+	configuration []reconciler.Configuration
+	capabilities  *chartutil.Capabilities
 }
 
-func (c *Component) Configuration() (map[string]interface{}, error) {
+//Values returns the YAML values documents of the component merged in the defined order
+func (c *Component) Values() (map[string]interface{}, error) {
 	result := make(map[string]interface{})
-	for key, value := range c.configuration {
-		if err := mergo.Merge(&result, c.convertToNestedMap(key, value), mergo.WithOverride); err != nil {
+	for idx, valuesDoc := range c.values {
+		values, err := chartutil.ReadValues([]byte(valuesDoc))
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to parse values document #%d of component '%s'",
+				idx+1, c.name))
+		}
+		if err := mergo.Merge(&result, values.AsMap(), mergo.WithOverride); err != nil {
 			return nil, err
 		}
 	}
 	return result, nil
 }
 
-//convertToNestedMap converts a key with dot-notation into a nested map (e.g. a.b.c=value become [a:[b:[c:value]]])
-func (c *Component) convertToNestedMap(key string, value interface{}) map[string]interface{} {
+func (c *Component) Configuration() (map[string]interface{}, error) {
 	result := make(map[string]interface{})
-	tokens := strings.Split(key, ".")
-	lastNestedMap := result
-	for depth, token := range tokens {
-		switch depth {
-		case len(tokens) - 1: //last token reached, stop nesting
-			lastNestedMap[token] = value
-		default:
-			lastNestedMap[token] = make(map[string]interface{})
-			lastNestedMap = lastNestedMap[token].(map[string]interface{})
+	if err := c.applyConfiguration(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//applyConfiguration sets the configuration entries of the component in the given values map.
+//Entries are applied in the defined order: later entries override earlier ones.
+func (c *Component) applyConfiguration(values map[string]interface{}) error {
+	for _, kvEntry := range c.configuration {
+		value, err := c.typedValue(kvEntry)
+		if err != nil {
+			return err
 		}
+		if err := setNestedValue(values, kvEntry.Key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Component) typedValue(kvEntry reconciler.Configuration) (interface{}, error) {
+	if kvEntry.DataType == "" {
+		return kvEntry.Value, nil
+	}
+	value, err := kvEntry.DataType.Get(kvEntry.Value)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("invalid value for configuration key '%s' of component '%s'",
+			kvEntry.Key, c.name))
 	}
-	return result
+	if duration, ok := value.(time.Duration); ok {
+		return duration.String(), nil //charts expect durations in text format (e.g. '1m30s')
+	}
+	return value, nil
+}
+
+type pathElement struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+func parsePath(key string) ([]pathElement, error) {
+	var path []pathElement
+	for _, token := range strings.Split(key, ".") {
+		matches := pathTokenRegex.FindStringSubmatch(token)
+		if matches == nil {
+			return nil, fmt.Errorf("configuration key '%s' is invalid: token '%s' is not supported", key, token)
+		}
+		path = append(path, pathElement{key: matches[1]})
+		for _, idxToken := range strings.Split(strings.Trim(matches[2], "[]"), "][") {
+			if idxToken == "" {
+				continue
+			}
+			idx, err := strconv.Atoi(idxToken)
+			if err != nil {
+				return nil, fmt.Errorf("configuration key '%s' is invalid: index '%s' is not a number", key, idxToken)
+			}
+			path = append(path, pathElement{index: idx, isIdx: true})
+		}
+	}
+	return path, nil
+}
+
+//setNestedValue sets the value of a key with dot-notation in the nested map (e.g. a.b.c=value become [a:[b:[c:value]]]).
+//List indices are supported (e.g. a.b[1].c=value become [a:[b:[nil, [c:value]]]]).
+func setNestedValue(values map[string]interface{}, key string, value interface{}) error {
+	path, err := parsePath(key)
+	if err != nil {
+		return err
+	}
+	//first path element is always a map key
+	values[path[0].key] = setPathValue(values[path[0].key], path[1:], value)
+	return nil
+}
+
+//setPathValue sets the value at the path of the given node and returns the updated node.
+//Missing maps and lists are created, lists are extended if the index exceeds their length.
+func setPathValue(node interface{}, path []pathElement, value interface{}) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	elem := path[0]
+	if elem.isIdx {
+		list, ok := node.([]interface{})
+		if !ok {
+			list = []interface{}{}
+		}
+		for len(list) <= elem.index {
+			list = append(list, nil)
+		}
+		list[elem.index] = setPathValue(list[elem.index], path[1:], value)
+		return list
+	}
+	nestedMap, ok := node.(map[string]interface{})
+	if !ok {
+		nestedMap = make(map[string]interface{})
+	}
+	nestedMap[elem.key] = setPathValue(nestedMap[elem.key], path[1:], value)
+	return nestedMap
 }
 
 type ComponentBuilder struct {
@@ -48,9 +153,8 @@ type ComponentBuilder struct {
 func NewComponentBuilder(version, name string) *ComponentBuilder {
 	return &ComponentBuilder{
 		&Component{
-			version:       version,
-			name:          name,
-			configuration: make(map[string]interface{}),
+			version: version,
+			name:    name,
 		},
 	}
 }
@@ -65,10 +169,21 @@ func (cb *ComponentBuilder) WithNamespace(namespace string) *ComponentBuilder {
 	return cb
 }
 
+//WithValues adds YAML values documents which are merged between the chart profile and the configuration
+func (cb *ComponentBuilder) WithValues(values ...string) *ComponentBuilder {
+	cb.component.values = append(cb.component.values, values...)
+	return cb
+}
+
 func (cb *ComponentBuilder) WithConfiguration(config []reconciler.Configuration) *ComponentBuilder {
-	for _, kvEntry := range config {
-		cb.component.configuration[kvEntry.Key] = kvEntry.Value
-	}
+	cb.component.configuration = append(cb.component.configuration, config...)
+	return cb
+}
+
+//WithCapabilities defines the cluster capabilities (Kubernetes version, API versions) used for rendering the chart.
+//If not set, HELM default capabilities are used.
+func (cb *ComponentBuilder) WithCapabilities(capabilities *chartutil.Capabilities) *ComponentBuilder {
+	cb.component.capabilities = capabilities
 	return cb
 }
 
@@ -2,6 +2,7 @@ package chart
 
 import (
 	"encoding/json"
+	"github.com/kyma-incubator/reconciler/pkg/model"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler"
 	"testing"
 
@@ -12,11 +13,11 @@ func TestComponent(t *testing.T) {
 	t.Parallel()
 
 	t.Run("Convert dot-notated configuration keys to a nested map", func(t *testing.T) {
-		component := NewComponentBuilder("main", "unittest-kyma").Build()
-
-		got := component.convertToNestedMap("this.is.a.test", "the test value")
+		got := make(map[string]interface{})
+		err := setNestedValue(got, "this.is.a.test", "the test value")
+		require.NoError(t, err)
 		expected := make(map[string]interface{})
-		err := json.Unmarshal([]byte(`{
+		err = json.Unmarshal([]byte(`{
 			"this":{
 				"is":{
 					"a":{
@@ -73,4 +74,162 @@ func TestComponent(t *testing.T) {
 		require.Equal(t, expected, got)
 	})
 
+	t.Run("Convert configuration keys with list indices to a nested map", func(t *testing.T) {
+		got := make(map[string]interface{})
+		err := setNestedValue(got, "this.is[1].a[0].test", "the test value")
+		require.NoError(t, err)
+		expected := make(map[string]interface{})
+		err = json.Unmarshal([]byte(`{
+			"this":{
+				"is":[
+					null,
+					{
+						"a":[
+							{
+								"test":"the test value"
+							}
+						]
+					}
+				]
+			}
+		}`), &expected)
+		require.NoError(t, err)
+
+		require.Equal(t, expected, got)
+	})
+
+	t.Run("Convert invalid configuration keys", func(t *testing.T) {
+		for _, key := range []string{"this..test", "this.is[a].test", "this[0.test", "[0].test"} {
+			err := setNestedValue(make(map[string]interface{}), key, "the test value")
+			require.Error(t, err, "key '%s' should be invalid", key)
+		}
+	})
+
+	t.Run("Test typed chart configuration processing", func(t *testing.T) {
+		component := NewComponentBuilder("main", "unittest-kyma").
+			WithConfiguration([]reconciler.Configuration{
+				{
+					Key:      "test.list[0].enabled",
+					Value:    "true",
+					DataType: model.Boolean,
+				},
+				{
+					Key:      "test.list[1].replicas",
+					Value:    "3",
+					DataType: model.Integer,
+				},
+				{
+					Key:   "test.string",
+					Value: "123",
+				},
+			}).
+			Build()
+
+		got, err := component.Configuration()
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]interface{}{
+			"test": map[string]interface{}{
+				"list": []interface{}{
+					map[string]interface{}{"enabled": true},
+					map[string]interface{}{"replicas": int64(3)},
+				},
+				"string": "123",
+			},
+		}, got)
+	})
+
+	t.Run("Test complex typed chart configuration processing", func(t *testing.T) {
+		component := NewComponentBuilder("main", "unittest-kyma").
+			WithConfiguration([]reconciler.Configuration{
+				{
+					Key:      "test.ratio",
+					Value:    "0.75",
+					DataType: model.Float,
+				},
+				{
+					Key:      "test.timeout",
+					Value:    "90s",
+					DataType: model.Duration,
+				},
+				{
+					Key:      "test.hosts",
+					Value:    "a.com, b.com",
+					DataType: model.List,
+				},
+				{
+					Key:      "test.resources",
+					Value:    `{"limits":{"cpu":"100m"}}`,
+					DataType: model.Map,
+				},
+				{
+					Key:      "test.resources.requests",
+					Value:    "cpu: 50m\nmemory: 64Mi",
+					DataType: model.YAML,
+				},
+				{
+					Key:      "test.password",
+					Value:    "secret",
+					DataType: model.Secret,
+				},
+			}).
+			Build()
+
+		got, err := component.Configuration()
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]interface{}{
+			"test": map[string]interface{}{
+				"ratio":   0.75,
+				"timeout": "1m30s",
+				"hosts":   []interface{}{"a.com", "b.com"},
+				"resources": map[string]interface{}{
+					"limits":   map[string]interface{}{"cpu": "100m"},
+					"requests": map[string]interface{}{"cpu": "50m", "memory": "64Mi"},
+				},
+				"password": "secret",
+			},
+		}, got)
+	})
+
+	t.Run("Test typed chart configuration with invalid value", func(t *testing.T) {
+		component := NewComponentBuilder("main", "unittest-kyma").
+			WithConfiguration([]reconciler.Configuration{
+				{
+					Key:      "test.enabled",
+					Value:    "not a boolean",
+					DataType: model.Boolean,
+				},
+			}).
+			Build()
+
+		_, err := component.Configuration()
+		require.Error(t, err)
+	})
+
+	t.Run("Test values documents processing", func(t *testing.T) {
+		component := NewComponentBuilder("main", "unittest-kyma").
+			WithValues(`
+test:
+  key1: value from doc 1
+  key2: value from doc 1
+  list:
+  - a
+  - b`, `
+test:
+  key2: value from doc 2`).
+			Build()
+
+		got, err := component.Values()
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]interface{}{
+			"test": map[string]interface{}{
+				"key1": "value from doc 1",
+				"key2": "value from doc 2",
+				"list": []interface{}{"a", "b"},
+			},
+		}, got)
+	})
+
 }
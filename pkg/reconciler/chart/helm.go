@@ -17,6 +17,7 @@ import (
 type HelmClient struct {
 	chartDir string
 	logger   *zap.SugaredLogger
+	cache    *ManifestCache
 }
 
 func NewHelmClient(chartDir string, logger *zap.SugaredLogger) (*HelmClient, error) {
@@ -29,6 +30,11 @@ func NewHelmClient(chartDir string, logger *zap.SugaredLogger) (*HelmClient, err
 	}, nil
 }
 
+func (c *HelmClient) WithManifestCache(cache *ManifestCache) *HelmClient {
+	c.cache = cache
+	return c
+}
+
 func (c *HelmClient) Render(component *Component) (string, error) {
 	helmChart, err := loader.Load(filepath.Join(c.chartDir, component.name))
 	if err != nil {
@@ -40,6 +46,18 @@ func (c *HelmClient) Render(component *Component) (string, error) {
 		return "", err
 	}
 
+	var cacheKey string
+	if c.cache != nil {
+		cacheKey, err = newManifestCacheKey(helmChart, component, config)
+		if err != nil {
+			return "", err
+		}
+		if manifest, ok := c.cache.Get(cacheKey); ok {
+			c.logger.Debugf("Using cached manifest of component '%s' (cache key: %s)", component.name, cacheKey)
+			return manifest, nil
+		}
+	}
+
 	tplAction, err := c.newTemplatingAction(component)
 	if err != nil {
 		return "", err
@@ -50,6 +68,13 @@ func (c *HelmClient) Render(component *Component) (string, error) {
 		return "", errors.Wrap(err, fmt.Sprintf("Failed to render HELM template for component '%s'", component.name))
 	}
 
+	if c.cache != nil {
+		if err := c.cache.Set(cacheKey, helmRelease.Manifest); err != nil {
+			//a failing cache is not critical: the rendered manifest is still valid
+			c.logger.Warnf("Failed to cache manifest of component '%s': %s", component.name, err)
+		}
+	}
+
 	return helmRelease.Manifest, nil
 }
 
@@ -69,6 +94,11 @@ func (c *HelmClient) newTemplatingAction(component *Component) (*action.Install,
 	tplAction.Replace = true     // Skip the name check
 	tplAction.IncludeCRDs = true //include CRDs in the templated output
 	tplAction.ClientOnly = true  //if false, it will validate the manifests against the Kubernetes cluster the kubeclient is currently pointing at
+	if component.capabilities != nil {
+		//render the chart against the capabilities of the target cluster instead of the HELM defaults
+		tplAction.KubeVersion = &component.capabilities.KubeVersion
+		tplAction.APIVersions = component.capabilities.APIVersions
+	}
 
 	return tplAction, nil
 }
@@ -83,20 +113,27 @@ func (c *HelmClient) newActionConfig(namespace string) (*action.Configuration, e
 	return cfg, nil
 }
 
+//mergeChartConfiguration merges the chart profile, the values documents of the component
+//and the component configuration (in this order: later ones override previous ones)
 func (c *HelmClient) mergeChartConfiguration(chart *chart.Chart, component *Component) (map[string]interface{}, error) {
 	result, err := c.chartConfiguration(chart, component.profile)
 	if err != nil {
 		return nil, err
 	}
 
-	componentConfig, err := component.Configuration()
+	componentValues, err := component.Values()
 	if err != nil {
 		return nil, err
 	}
 
-	if err := mergo.Merge(&result, componentConfig, mergo.WithOverride); err != nil {
+	if err := mergo.Merge(&result, componentValues, mergo.WithOverride); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to merge profile configuration with values "+
+			"of component '%s'", component.name))
+	}
+
+	if err := component.applyConfiguration(result); err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("failed to merge profile configuration with component "+
-			"configuration for cpmponent '%s'", component.name))
+			"configuration for component '%s'", component.name))
 	}
 
 	return result, nil
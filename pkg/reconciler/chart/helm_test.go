@@ -3,6 +3,7 @@ package chart
 import (
 	"encoding/json"
 	log "github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/kyma-incubator/reconciler/pkg/model"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
@@ -138,6 +139,57 @@ func TestHelm(t *testing.T) {
 		require.Equal(t, expected, got)
 	})
 
+	t.Run("Merge chart configuration with values documents and component configuration", func(t *testing.T) {
+		component := NewComponentBuilder("main", componentName).
+			WithNamespace("testNamespace").
+			WithProfile(profileName).
+			WithValues(`
+config:
+  key1: value1 from values document
+  key2: value2 from values document
+list:
+- name: entry1
+- name: entry2`).
+			WithConfiguration([]reconciler.Configuration{
+				{
+					Key:   "config.key2",
+					Value: "value2 from component",
+				},
+				{
+					Key:      "list[1].enabled",
+					Value:    "true",
+					DataType: model.Boolean,
+				},
+			}).
+			Build()
+
+		helm, err := NewHelmClient(chartDir, logger)
+		require.NoError(t, err)
+
+		got, err := helm.mergeChartConfiguration(loadHelmChart(t, component), component)
+		require.NoError(t, err)
+
+		var expected map[string]interface{}
+		err = json.Unmarshal([]byte(`{
+			"config": {
+				"key1": "value1 from values document",
+				"key2": "value2 from component"
+			},
+			"profile": true,
+			"list": [
+				{
+					"name": "entry1"
+				},
+				{
+					"name": "entry2",
+					"enabled": true
+				}
+			]
+		}`), &expected)
+		require.NoError(t, err)
+		require.Equal(t, expected, got)
+	})
+
 	t.Run("Render template", func(t *testing.T) {
 		component := NewComponentBuilder("main", componentName).
 			WithNamespace("testNamespace").
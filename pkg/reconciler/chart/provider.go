@@ -5,6 +5,7 @@ import (
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/kubernetes/kubeclient"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/workspace"
 	"io/ioutil"
+	"k8s.io/client-go/kubernetes"
 	"os"
 	"path/filepath"
 
@@ -16,6 +17,7 @@ const kindCRD = "CustomResourceDefinition"
 type Provider struct {
 	wsFactory *workspace.Factory
 	logger    *zap.SugaredLogger
+	cache     *ManifestCache
 }
 
 func NewProvider(wsFactory *workspace.Factory, logger *zap.SugaredLogger) (*Provider, error) {
@@ -28,6 +30,11 @@ func NewProvider(wsFactory *workspace.Factory, logger *zap.SugaredLogger) (*Prov
 	}, nil
 }
 
+func (p *Provider) WithManifestCache(cache *ManifestCache) *Provider {
+	p.cache = cache
+	return p
+}
+
 func (p *Provider) RenderCRD(version string) ([]*Manifest, error) {
 	ws, err := p.newWorkspace(version)
 	if err != nil {
@@ -96,7 +103,7 @@ func (p *Provider) RenderManifest(component *Component) (*Manifest, error) {
 		return nil, err
 	}
 
-	manifest, err := helmClient.Render(component)
+	manifest, err := helmClient.WithManifestCache(p.cache).Render(component)
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +115,22 @@ func (p *Provider) RenderManifest(component *Component) (*Manifest, error) {
 	}, nil
 }
 
+//RecordRelease stores the HELM release metadata of a deployed component in the target cluster
+func (p *Provider) RecordRelease(clientSet kubernetes.Interface, component *Component, manifest string) error {
+	ws, err := p.newWorkspace(component.version)
+	if err != nil {
+		return err
+	}
+
+	helmClient, err := NewHelmClient(ws.ResourceDir, p.logger)
+	if err != nil {
+		return err
+	}
+
+	_, err = helmClient.RecordRelease(clientSet, component, manifest)
+	return err
+}
+
 func (p *Provider) newWorkspace(version string) (*workspace.Workspace, error) {
 	p.logger.Debugf("Getting workspace for Kyma '%s'", version)
 	ws, err := p.wsFactory.Get(version)
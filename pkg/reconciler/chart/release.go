@@ -0,0 +1,107 @@
+package chart
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	helmtime "helm.sh/helm/v3/pkg/time"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultMaxReleaseHistory = 10
+	releaseDescription       = "Reconciled by Kyma reconciler"
+)
+
+//RecordRelease stores the HELM release metadata of a deployed component as HELM release secret in the target cluster.
+//An existing release (e.g. created by a previous 'helm install') is adopted: its last revision gets superseded
+//and the new revision continues its history. If the last revision is already deployed with the same manifest
+//and chart version, no new revision is recorded.
+func (c *HelmClient) RecordRelease(clientSet kubernetes.Interface, component *Component, manifest string) (*release.Release, error) {
+	helmChart, err := loader.Load(filepath.Join(c.chartDir, component.name))
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := c.mergeChartConfiguration(helmChart, component)
+	if err != nil {
+		return nil, err
+	}
+
+	store := c.newReleaseStorage(clientSet, component.namespace)
+
+	now := helmtime.Now()
+	newRelease := &release.Release{
+		Name:      component.name,
+		Namespace: component.namespace,
+		Chart:     helmChart,
+		Config:    config,
+		Manifest:  manifest,
+		Info: &release.Info{
+			FirstDeployed: now,
+			LastDeployed:  now,
+			Status:        release.StatusDeployed,
+			Description:   releaseDescription,
+		},
+		Version: 1,
+	}
+
+	lastRelease, err := store.Last(component.name)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to retrieve HELM release of component '%s'", component.name))
+	}
+
+	if lastRelease != nil {
+		if c.releaseUnchanged(lastRelease, newRelease) {
+			c.logger.Debugf("HELM release '%s' (revision %d) of component '%s' is up to date",
+				lastRelease.Name, lastRelease.Version, component.name)
+			return lastRelease, nil
+		}
+
+		c.logger.Debugf("Adopting HELM release '%s' of component '%s': superseding revision %d",
+			lastRelease.Name, component.name, lastRelease.Version)
+		newRelease.Version = lastRelease.Version + 1
+		if lastRelease.Info != nil {
+			newRelease.Info.FirstDeployed = lastRelease.Info.FirstDeployed
+			lastRelease.Info.Status = release.StatusSuperseded
+		}
+		if err := store.Update(lastRelease); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to supersede revision %d of HELM release '%s'",
+				lastRelease.Version, lastRelease.Name))
+		}
+	}
+
+	if err := store.Create(newRelease); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to record revision %d of HELM release '%s'",
+			newRelease.Version, newRelease.Name))
+	}
+
+	c.logger.Debugf("HELM release '%s' (revision %d) of component '%s' recorded",
+		newRelease.Name, newRelease.Version, component.name)
+	return newRelease, nil
+}
+
+func (c *HelmClient) newReleaseStorage(clientSet kubernetes.Interface, namespace string) *storage.Storage {
+	secretsDriver := driver.NewSecrets(clientSet.CoreV1().Secrets(namespace))
+	secretsDriver.Log = c.logger.Debugf
+	store := storage.Init(secretsDriver)
+	store.Log = c.logger.Debugf
+	store.MaxHistory = defaultMaxReleaseHistory
+	return store
+}
+
+func (c *HelmClient) releaseUnchanged(lastRelease, newRelease *release.Release) bool {
+	if lastRelease.Info == nil || lastRelease.Info.Status != release.StatusDeployed {
+		return false
+	}
+	if lastRelease.Chart == nil || lastRelease.Chart.Metadata == nil {
+		return false
+	}
+	return lastRelease.Manifest == newRelease.Manifest &&
+		lastRelease.Chart.Metadata.Version == newRelease.Chart.Metadata.Version
+}
@@ -0,0 +1,79 @@
+package chart
+
+import (
+	"testing"
+
+	log "github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRecordRelease(t *testing.T) {
+	logger, err := log.NewLogger(true)
+	require.NoError(t, err)
+
+	component := NewComponentBuilder("main", componentName).
+		WithNamespace("testNamespace").
+		WithProfile(profileName).
+		Build()
+
+	helm, err := NewHelmClient(chartDir, logger)
+	require.NoError(t, err)
+
+	t.Run("Record new release and new revisions", func(t *testing.T) {
+		clientSet := fake.NewSimpleClientset()
+
+		rel1, err := helm.RecordRelease(clientSet, component, "manifest v1")
+		require.NoError(t, err)
+		require.Equal(t, 1, rel1.Version)
+		require.Equal(t, release.StatusDeployed, rel1.Info.Status)
+
+		//unchanged manifest does not create a new revision
+		relUnchanged, err := helm.RecordRelease(clientSet, component, "manifest v1")
+		require.NoError(t, err)
+		require.Equal(t, 1, relUnchanged.Version)
+
+		rel2, err := helm.RecordRelease(clientSet, component, "manifest v2")
+		require.NoError(t, err)
+		require.Equal(t, 2, rel2.Version)
+
+		history, err := helm.newReleaseStorage(clientSet, component.namespace).History(component.name)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		for _, rel := range history {
+			if rel.Version == 1 {
+				require.Equal(t, release.StatusSuperseded, rel.Info.Status)
+			} else {
+				require.Equal(t, release.StatusDeployed, rel.Info.Status)
+				require.Equal(t, "manifest v2", rel.Manifest)
+			}
+		}
+	})
+
+	t.Run("Adopt existing release", func(t *testing.T) {
+		clientSet := fake.NewSimpleClientset()
+
+		//simulate a release installed by HELM
+		existingRelease := &release.Release{
+			Name:      component.name,
+			Namespace: component.namespace,
+			Chart:     loadHelmChart(t, component),
+			Manifest:  "manifest installed by helm",
+			Info: &release.Info{
+				Status: release.StatusDeployed,
+			},
+			Version: 5,
+		}
+		store := helm.newReleaseStorage(clientSet, component.namespace)
+		require.NoError(t, store.Create(existingRelease))
+
+		rel, err := helm.RecordRelease(clientSet, component, "manifest by reconciler")
+		require.NoError(t, err)
+		require.Equal(t, 6, rel.Version)
+
+		adoptedRelease, err := store.Get(component.name, 5)
+		require.NoError(t, err)
+		require.Equal(t, release.StatusSuperseded, adoptedRelease.Info.Status)
+	})
+}
@@ -1,12 +1,17 @@
 package istio
 
 import (
-	"encoding/json"
+	"context"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/chart"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/file"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler/kubernetes"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/service"
-	"k8s.io/apimachinery/pkg/types"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8s "k8s.io/client-go/kubernetes"
 	"os"
 	"os/exec"
 	"strings"
@@ -18,20 +23,9 @@ const (
 	istioOperatorKind        = "kind: IstioOperator"
 	istioNamespace           = "istio-system"
 	istioChart               = "istio-configuration"
+	sidecarInjectorWebhook   = "istio-sidecar-injector"
 )
 
-type webhookPatchJSON struct {
-	Op    string                `json:"op"`
-	Path  string                `json:"path"`
-	Value webhookPatchJSONValue `json:"value"`
-}
-
-type webhookPatchJSONValue struct {
-	Key      string   `json:"key"`
-	Operator string   `json:"operator"`
-	Values   []string `json:"values"`
-}
-
 type ReconcileAction struct {
 }
 
@@ -77,34 +71,45 @@ func (a *ReconcileAction) Run(version, profile string, config []reconciler.Confi
 		return err
 	}
 
-	_, err = context.KubeClient.Deploy(context.Context, manifest.Manifest, istioNamespace)
+	_, err = context.KubeClient.Deploy(context.Context, manifest.Manifest, istioNamespace, context.PostRender)
+	if err != nil {
+		return err
+	}
+
+	clientSet, err := context.KubeClient.Clientset()
 	if err != nil {
 		return err
 	}
 
-	patchContent := []webhookPatchJSON{{
-		Op:   "add",
-		Path: "/webhooks/4/namespaceSelector/matchExpressions/-",
-		Value: webhookPatchJSONValue{
-			Key:      "gardener.cloud/purpose",
-			Operator: "NotIn",
-			Values: []string{
-				"kube-system",
-			},
-		},
-	}}
-
-	patchContentJSON, err := json.Marshal(patchContent)
+	return postRenderSidecarInjector(context.Context, clientSet, context.PostRender)
+}
+
+//postRenderSidecarInjector applies the post-render declarations on the sidecar-injector webhook: the webhook
+//is created by istioctl and is therefore not part of the deployed manifest
+func postRenderSidecarInjector(ctx context.Context, clientSet k8s.Interface, interceptor kubernetes.ResourceInterceptor) error {
+	webhooks := clientSet.AdmissionregistrationV1().MutatingWebhookConfigurations()
+	webhook, err := webhooks.Get(ctx, sidecarInjectorWebhook, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
 
-	err = context.KubeClient.PatchUsingStrategy("MutatingWebhookConfiguration", "istio-sidecar-injector", istioNamespace, patchContentJSON, types.JSONPatchType)
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(webhook)
 	if err != nil {
 		return err
 	}
+	resource := &unstructured.Unstructured{Object: object}
+	resource.SetAPIVersion(admissionregistrationv1.SchemeGroupVersion.String())
+	resource.SetKind("MutatingWebhookConfiguration")
+	if err := interceptor.Intercept(resource); err != nil {
+		return err
+	}
 
-	return nil
+	patched := &admissionregistrationv1.MutatingWebhookConfiguration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(resource.Object, patched); err != nil {
+		return err
+	}
+	_, err = webhooks.Update(ctx, patched, metav1.UpdateOptions{})
+	return err
 }
 
 func getIstioctlBinaryPath() string {
@@ -0,0 +1,43 @@
+package istio
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-incubator/reconciler/pkg/reconciler/kubernetes"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPostRenderSidecarInjector(t *testing.T) {
+	istioInjection := metav1.LabelSelectorRequirement{Key: "istio-injection", Operator: metav1.LabelSelectorOpDoesNotExist}
+	webhooks := make([]admissionregistrationv1.MutatingWebhook, 5)
+	for idx := range webhooks {
+		webhooks[idx].NamespaceSelector = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{istioInjection},
+		}
+	}
+	clientSet := fake.NewSimpleClientset(&admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: sidecarInjectorWebhook},
+		Webhooks:   webhooks,
+	})
+
+	interceptor, err := kubernetes.NewPostRenderInterceptor(sidecarInjectorPatch)
+	require.NoError(t, err)
+	require.NoError(t, postRenderSidecarInjector(context.Background(), clientSet, interceptor))
+
+	webhook, err := clientSet.AdmissionregistrationV1().MutatingWebhookConfigurations().
+		Get(context.Background(), sidecarInjectorWebhook, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []metav1.LabelSelectorRequirement{istioInjection}, webhook.Webhooks[3].NamespaceSelector.MatchExpressions)
+	require.Equal(t, []metav1.LabelSelectorRequirement{
+		istioInjection,
+		{
+			Key:      "gardener.cloud/purpose",
+			Operator: metav1.LabelSelectorOpNotIn,
+			Values:   []string{"kube-system"},
+		},
+	}, webhook.Webhooks[4].NamespaceSelector.MatchExpressions)
+}
@@ -2,11 +2,31 @@ package istio
 
 import (
 	"github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/service"
 )
 
 const ReconcilerName = "istio"
 
+//sidecarInjectorPatch excludes the kube-system namespace of Gardener clusters from the sidecar injection
+var sidecarInjectorPatch = reconciler.PostRender{
+	Patches: []reconciler.Patch{
+		{
+			Target: reconciler.PatchTarget{
+				Kind: "MutatingWebhookConfiguration",
+				Name: sidecarInjectorWebhook,
+			},
+			Type: reconciler.JSON6902Patch,
+			Patch: `- op: add
+  path: /webhooks/4/namespaceSelector/matchExpressions/-
+  value:
+    key: gardener.cloud/purpose
+    operator: NotIn
+    values: [kube-system]`,
+		},
+	},
+}
+
 //nolint:gochecknoinits //usage of init() is intended to register reconciler-instances in centralized registry
 func init() {
 	log, err := logger.NewLogger(false)
@@ -20,5 +40,6 @@ func init() {
 		log.Fatalf("Could not create '%s' component reconciler: %s", ReconcilerName, err)
 	}
 
-	reconciler.WithReconcileAction(&ReconcileAction{})
+	reconciler.WithReconcileAction(&ReconcileAction{}).
+		WithPostRender(sidecarInjectorPatch)
 }
@@ -0,0 +1,125 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+//PostRenderInterceptor applies declarative post-render transformations (labels, annotations and patches)
+//on rendered resources. Declarations are applied in the given order.
+type PostRenderInterceptor struct {
+	postRenders []reconciler.PostRender
+}
+
+func NewPostRenderInterceptor(postRenders ...reconciler.PostRender) (*PostRenderInterceptor, error) {
+	for _, postRender := range postRenders {
+		for _, patch := range postRender.Patches {
+			if err := patch.Validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &PostRenderInterceptor{
+		postRenders: postRenders,
+	}, nil
+}
+
+func (i *PostRenderInterceptor) Intercept(resource *unstructured.Unstructured) error {
+	for _, postRender := range i.postRenders {
+		if len(postRender.Labels) > 0 {
+			resource.SetLabels(mergeStringMaps(resource.GetLabels(), postRender.Labels))
+		}
+		if len(postRender.Annotations) > 0 {
+			resource.SetAnnotations(mergeStringMaps(resource.GetAnnotations(), postRender.Annotations))
+		}
+		for _, patch := range postRender.Patches {
+			if !i.matches(patch.Target, resource) {
+				continue
+			}
+			if err := i.applyPatch(patch, resource); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to apply %s patch on %s '%s'",
+					patch.Type, resource.GetKind(), resource.GetName()))
+			}
+		}
+	}
+	return nil
+}
+
+func (i *PostRenderInterceptor) matches(target reconciler.PatchTarget, resource *unstructured.Unstructured) bool {
+	return (target.APIVersion == "" || target.APIVersion == resource.GetAPIVersion()) &&
+		(target.Kind == "" || target.Kind == resource.GetKind()) &&
+		(target.Name == "" || target.Name == resource.GetName()) &&
+		(target.Namespace == "" || target.Namespace == resource.GetNamespace())
+}
+
+func (i *PostRenderInterceptor) applyPatch(patch reconciler.Patch, resource *unstructured.Unstructured) error {
+	patchJSON, err := yaml.YAMLToJSON([]byte(patch.Patch))
+	if err != nil {
+		return err
+	}
+
+	resourceJSON, err := resource.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	var patchedJSON []byte
+	switch patch.Type {
+	case reconciler.JSON6902Patch:
+		jsonPatch, err := jsonpatch.DecodePatch(patchJSON)
+		if err != nil {
+			return err
+		}
+		patchedJSON, err = jsonPatch.Apply(resourceJSON)
+		if err != nil {
+			return err
+		}
+	case reconciler.StrategicMergePatch:
+		//strategic merge patches require the schema of the resource: custom resources fall back to a JSON merge patch
+		dataStruct, err := scheme.Scheme.New(resource.GroupVersionKind())
+		if err != nil {
+			patchedJSON, err = jsonpatch.MergePatch(resourceJSON, patchJSON)
+			if err != nil {
+				return err
+			}
+			break
+		}
+		patchedJSON, err = strategicpatch.StrategicMergePatch(resourceJSON, patchJSON, dataStruct)
+		if err != nil {
+			return err
+		}
+	case reconciler.MergePatch:
+		patchedJSON, err = jsonpatch.MergePatch(resourceJSON, patchJSON)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("patch type '%s' is not supported", patch.Type)
+	}
+
+	patchedObject := make(map[string]interface{})
+	if err := json.Unmarshal(patchedJSON, &patchedObject); err != nil {
+		return err
+	}
+	resource.SetUnstructuredContent(patchedObject)
+	return nil
+}
+
+func mergeStringMaps(current, additional map[string]string) map[string]string {
+	result := make(map[string]string, len(current)+len(additional))
+	for key, value := range current {
+		result[key] = value
+	}
+	for key, value := range additional {
+		result[key] = value
+	}
+	return result
+}
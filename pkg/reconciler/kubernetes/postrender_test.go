@@ -0,0 +1,145 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/kyma-incubator/reconciler/pkg/reconciler"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPostRenderInterceptor(t *testing.T) {
+	newDeployment := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name":      "deploy",
+					"namespace": "test",
+					"labels": map[string]interface{}{
+						"app": "test",
+					},
+				},
+				"spec": map[string]interface{}{
+					"replicas": int64(1),
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{
+								map[string]interface{}{
+									"name":  "main",
+									"image": "main:1.0",
+								},
+								map[string]interface{}{
+									"name":  "sidecar",
+									"image": "sidecar:1.0",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("Add labels and annotations", func(t *testing.T) {
+		interceptor, err := NewPostRenderInterceptor(reconciler.PostRender{
+			Labels:      map[string]string{"landscape": "dev"},
+			Annotations: map[string]string{"owner": "team"},
+		})
+		require.NoError(t, err)
+
+		resource := newDeployment()
+		require.NoError(t, interceptor.Intercept(resource))
+		require.Equal(t, map[string]string{"app": "test", "landscape": "dev"}, resource.GetLabels())
+		require.Equal(t, map[string]string{"owner": "team"}, resource.GetAnnotations())
+	})
+
+	t.Run("Apply strategic merge patch", func(t *testing.T) {
+		interceptor, err := NewPostRenderInterceptor(reconciler.PostRender{
+			Patches: []reconciler.Patch{
+				{
+					Target: reconciler.PatchTarget{Kind: "Deployment", Name: "deploy"},
+					Type:   reconciler.StrategicMergePatch,
+					Patch: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: sidecar
+        image: sidecar:2.0`,
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		resource := newDeployment()
+		require.NoError(t, interceptor.Intercept(resource))
+		containers, _, err := unstructured.NestedSlice(resource.Object, "spec", "template", "spec", "containers")
+		require.NoError(t, err)
+		require.Len(t, containers, 2) //containers are merged by name
+		require.Equal(t, "main:1.0", containers[0].(map[string]interface{})["image"])
+		require.Equal(t, "sidecar:2.0", containers[1].(map[string]interface{})["image"])
+	})
+
+	t.Run("Apply JSON6902 patch", func(t *testing.T) {
+		interceptor, err := NewPostRenderInterceptor(reconciler.PostRender{
+			Patches: []reconciler.Patch{
+				{
+					Target: reconciler.PatchTarget{Kind: "Deployment"},
+					Type:   reconciler.JSON6902Patch,
+					Patch:  `[{"op": "replace", "path": "/spec/replicas", "value": 3}]`,
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		resource := newDeployment()
+		require.NoError(t, interceptor.Intercept(resource))
+		replicas, _, err := unstructured.NestedFloat64(resource.Object, "spec", "replicas")
+		require.NoError(t, err)
+		require.Equal(t, float64(3), replicas)
+	})
+
+	t.Run("Skip patch for non-matching target", func(t *testing.T) {
+		interceptor, err := NewPostRenderInterceptor(reconciler.PostRender{
+			Patches: []reconciler.Patch{
+				{
+					Target: reconciler.PatchTarget{Kind: "Deployment", Namespace: "other"},
+					Type:   reconciler.MergePatch,
+					Patch:  `{"spec": {"replicas": 3}}`,
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		resource := newDeployment()
+		require.NoError(t, interceptor.Intercept(resource))
+		require.Equal(t, newDeployment(), resource)
+	})
+
+	t.Run("Reject invalid patch declaration", func(t *testing.T) {
+		_, err := NewPostRenderInterceptor(reconciler.PostRender{
+			Patches: []reconciler.Patch{
+				{
+					Type:  "unknown",
+					Patch: `{}`,
+				},
+			},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("Fail on invalid patch", func(t *testing.T) {
+		interceptor, err := NewPostRenderInterceptor(reconciler.PostRender{
+			Patches: []reconciler.Patch{
+				{
+					Type:  reconciler.JSON6902Patch,
+					Patch: `[{"op": "remove", "path": "/spec/notExisting"}]`,
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Error(t, interceptor.Intercept(newDeployment()))
+	})
+}
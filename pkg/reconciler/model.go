@@ -3,11 +3,65 @@ package reconciler
 import (
 	"fmt"
 	"strings"
+
+	"github.com/kyma-incubator/reconciler/pkg/model"
 )
 
 type Configuration struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
+	Key      string         `json:"key"`
+	Value    string         `json:"value"`
+	DataType model.DataType `json:"dataType,omitempty"` //optional: values without data type are passed as string
+	Trigger  string         `json:"trigger,omitempty"`  //optional: executed by the reconciler if the value changed
+	Secret   bool           `json:"secret,omitempty"`   //optional: value is confidential and has to be masked in any output
+}
+
+func (c Configuration) String() string {
+	value := c.Value
+	if c.Secret {
+		value = "***"
+	}
+	return fmt.Sprintf("Configuration [Key=%s,Value=%s,DataType=%s]", c.Key, value, c.DataType)
+}
+
+type PatchType string
+
+const (
+	StrategicMergePatch PatchType = "strategic"
+	MergePatch          PatchType = "merge"
+	JSON6902Patch       PatchType = "json6902"
+)
+
+//PostRender declares transformations which are applied on the rendered resources before they get deployed
+type PostRender struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Patches     []Patch           `json:"patches,omitempty"`
+}
+
+//Patch is applied on all rendered resources which match the target (empty target fields match any value)
+type Patch struct {
+	Target PatchTarget `json:"target"`
+	Type   PatchType   `json:"type"`
+	Patch  string      `json:"patch"` //patch document in YAML or JSON format
+}
+
+type PatchTarget struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+func (p *Patch) Validate() error {
+	switch p.Type {
+	case StrategicMergePatch, MergePatch, JSON6902Patch:
+	default:
+		return fmt.Errorf("patch type '%s' is not supported", p.Type)
+	}
+	if strings.TrimSpace(p.Patch) == "" {
+		return fmt.Errorf("patch of type '%s' is empty", p.Type)
+	}
+	return nil
 }
 
 type Status string
@@ -27,6 +81,8 @@ type Reconciliation struct {
 	Version         string          `json:"version"`
 	Profile         string          `json:"profile"`
 	Configuration   []Configuration `json:"configuration"`
+	Values          []string        `json:"values,omitempty"`     //YAML values documents of the component
+	PostRender      []PostRender    `json:"postRender,omitempty"` //post-render declarations (e.g. of landscape buckets)
 	Kubeconfig      string          `json:"kubeconfig"`
 	CallbackURL     string          `json:"callbackURL"` //CallbackURL is mandatory when component-reconciler runs in separate process
 	InstallCRD      bool            `json:"installCRD"`
@@ -36,7 +92,9 @@ type Reconciliation struct {
 	CallbackFunc func(status Status) error `json:"-"` //CallbackFunc is mandatory when component-reconciler runs embedded in another process
 }
 
-func (r *Reconciliation) String() string {
+//String returns a description of the reconciliation which excludes the kubeconfig and configuration values.
+//It is defined on the value type to apply also if a reconciliation is printed by value.
+func (r Reconciliation) String() string {
 	return fmt.Sprintf("Reconciliation [Component:%s,Version:%s,Namespace:%s,Profile:%s]",
 		r.Component, r.Version, r.Namespace, r.Profile)
 }
@@ -69,11 +127,18 @@ func (r *Reconciliation) Validate() error {
 		errFields = append(errFields, "CorrelationID")
 	}
 	//return aggregated error msg
-	var err error
 	if len(errFields) > 0 {
-		err = fmt.Errorf("mandatory fields are undefined: %s", strings.Join(errFields, ","))
+		return fmt.Errorf("mandatory fields are undefined: %s", strings.Join(errFields, ","))
+	}
+	//verify post-render declarations
+	for _, postRender := range r.PostRender {
+		for _, patch := range postRender.Patches {
+			if err := patch.Validate(); err != nil {
+				return err
+			}
+		}
 	}
-	return err
+	return nil
 }
 
 type CallbackMessage struct {
@@ -0,0 +1,31 @@
+package reconciler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconciliation(t *testing.T) {
+	t.Run("String excludes kubeconfig and secrets", func(t *testing.T) {
+		reconciliation := Reconciliation{
+			Component:  "component",
+			Version:    "1.0.0",
+			Kubeconfig: "my kubeconfig",
+			Configuration: []Configuration{
+				{Key: "my.secret", Value: "my secret", Secret: true},
+			},
+		}
+		for _, output := range []string{
+			reconciliation.String(),
+			fmt.Sprintf("%s", reconciliation),
+			fmt.Sprintf("%v", reconciliation),
+			fmt.Sprintf("%v", &reconciliation),
+		} {
+			require.Contains(t, output, "component")
+			require.NotContains(t, output, "my kubeconfig")
+			require.NotContains(t, output, "my secret")
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/kubernetes"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/workspace"
 	"github.com/panjf2000/ants/v2"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -34,11 +36,13 @@ const (
 	defaultTimeout       = 10 * time.Minute
 	defaultWorkers       = 100
 	defaultWorkspace     = "."
+	manifestCacheDir     = ".manifest-cache"
 )
 
 var (
-	wsFactory *workspace.Factory //singleton
-	m         sync.Mutex
+	wsFactory     *workspace.Factory   //singleton
+	manifestCache *chart.ManifestCache //singleton
+	m             sync.Mutex
 )
 
 type ActionContext struct {
@@ -47,6 +51,7 @@ type ActionContext struct {
 	Context          context.Context
 	Logger           *zap.SugaredLogger
 	ChartProvider    *chart.Provider
+	PostRender       *kubernetes.PostRenderInterceptor //post-render declarations of the component and the reconciliation
 }
 
 type Action interface {
@@ -55,6 +60,10 @@ type Action interface {
 
 type ComponentReconciler struct {
 	workspace             string
+	manifestCacheConfig   manifestCacheConfig
+	clusterCapabilities   bool
+	helmReleases          bool
+	postRenders           []reconciler.PostRender
 	dependencies          []string
 	serverConfig          serverConfig
 	heartbeatSenderConfig heartbeatSenderConfig
@@ -84,6 +93,11 @@ type progressTrackerConfig struct {
 	timeout  time.Duration
 }
 
+type manifestCacheConfig struct {
+	dir        string
+	maxEntries int
+}
+
 type serverConfig struct {
 	port       int
 	sslCrtFile string
@@ -116,7 +130,42 @@ func (r *ComponentReconciler) newChartProvider() (*chart.Provider, error) {
 	if err != nil {
 		return nil, err
 	}
-	return chart.NewProvider(wsFact, r.logger)
+	chartProv, err := chart.NewProvider(wsFact, r.logger)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := r.manifestCache()
+	if err != nil {
+		return nil, err
+	}
+	return chartProv.WithManifestCache(cache), nil
+}
+
+func (r *ComponentReconciler) manifestCache() (*chart.ManifestCache, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if manifestCache != nil {
+		return manifestCache, nil
+	}
+
+	cacheDir := r.manifestCacheConfig.dir
+	if cacheDir == "" {
+		cacheDir = filepath.Join(r.workspace, manifestCacheDir)
+	}
+	r.logger.Debugf("Creating new manifest cache using cache directory '%s'", cacheDir)
+	cache, err := chart.NewManifestCache(cacheDir, r.manifestCacheConfig.maxEntries, r.logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := prometheus.Register(cache); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			return nil, err
+		}
+	}
+	manifestCache = cache
+
+	return manifestCache, nil
 }
 
 func (r *ComponentReconciler) workspaceFactory() (*workspace.Factory, error) {
@@ -209,6 +258,33 @@ func (r *ComponentReconciler) WithWorkspace(workspace string) *ComponentReconcil
 	return r
 }
 
+func (r *ComponentReconciler) WithManifestCache(dir string, maxEntries int) *ComponentReconciler {
+	r.manifestCacheConfig.dir = dir
+	r.manifestCacheConfig.maxEntries = maxEntries
+	return r
+}
+
+//WithClusterCapabilities renders charts against the capabilities (Kubernetes version, API versions) of the target
+//cluster and validates the rendered resources against the cluster API before they get deployed
+func (r *ComponentReconciler) WithClusterCapabilities() *ComponentReconciler {
+	r.clusterCapabilities = true
+	return r
+}
+
+//WithHelmReleases records (and adopts existing) HELM release secrets of the component after a successful deployment.
+//This keeps HELM based tooling working on clusters which were previously managed by HELM.
+func (r *ComponentReconciler) WithHelmReleases() *ComponentReconciler {
+	r.helmReleases = true
+	return r
+}
+
+//WithPostRender adds post-render declarations which are applied on all rendered resources of the component.
+//They are applied before the post-render declarations of the reconciliation model.
+func (r *ComponentReconciler) WithPostRender(postRenders ...reconciler.PostRender) *ComponentReconciler {
+	r.postRenders = append(r.postRenders, postRenders...)
+	return r
+}
+
 func (r *ComponentReconciler) WithDependencies(components ...string) *ComponentReconciler {
 	r.dependencies = components
 	return r
@@ -11,7 +11,9 @@ import (
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/heartbeat"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/kubernetes"
 	"github.com/kyma-incubator/reconciler/pkg/reconciler/kubernetes/adapter"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler/trigger"
 	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chartutil"
 )
 
 type runner struct {
@@ -85,12 +87,21 @@ func (r *runner) reconcile(ctx context.Context, model *reconciler.Reconciliation
 		return err
 	}
 
+	//post-render declarations of the component are applied before the declarations of the model
+	postRenders := append([]reconciler.PostRender{}, r.postRenders...)
+	postRenders = append(postRenders, model.PostRender...)
+	postRenderInterceptor, err := kubernetes.NewPostRenderInterceptor(postRenders...)
+	if err != nil {
+		return err
+	}
+
 	actionHelper := &ActionContext{
 		KubeClient:       kubeClient,
 		WorkspaceFactory: wsFactory,
 		Context:          ctx,
 		Logger:           r.logger,
 		ChartProvider:    chartProvider,
+		PostRender:       postRenderInterceptor,
 	}
 
 	if r.preReconcileAction != nil {
@@ -102,7 +113,7 @@ func (r *runner) reconcile(ctx context.Context, model *reconciler.Reconciliation
 	}
 
 	if r.reconcileAction == nil {
-		if err := r.install(ctx, chartProvider, model, kubeClient); err != nil {
+		if err := r.install(ctx, chartProvider, model, kubeClient, postRenderInterceptor); err != nil {
 			r.logger.Warnf("Default-reconciliation of '%s' with version '%s' failed: %s",
 				model.Component, model.Version, err)
 			return err
@@ -123,33 +134,102 @@ func (r *runner) reconcile(ctx context.Context, model *reconciler.Reconciliation
 		}
 	}
 
+	if err := r.executeTriggers(ctx, model, kubeClient); err != nil {
+		r.logger.Warnf("Execution of triggers of '%s' with version '%s' failed: %s",
+			model.Component, model.Version, err)
+		return err
+	}
+
 	return nil
 }
 
-func (r *runner) install(ctx context.Context, chartProvider *chart.Provider, model *reconciler.Reconciliation, kubeClient kubernetes.Client) error {
-	manifest, err := r.renderManifest(chartProvider, model)
+//executeTriggers runs the triggers of the component keys whose value changed since the last successful reconciliation
+func (r *runner) executeTriggers(ctx context.Context, model *reconciler.Reconciliation, kubeClient kubernetes.Client) error {
+	hasTriggers := false
+	for _, cfg := range model.Configuration {
+		if cfg.Trigger != "" {
+			hasTriggers = true
+			break
+		}
+	}
+	if !hasTriggers {
+		return nil
+	}
+
+	clientSet, err := kubeClient.Clientset()
 	if err != nil {
 		return err
 	}
+	return trigger.NewExecutor(nil, clientSet, r.logger).
+		ExecuteChanged(ctx, model.Component, model.Namespace, model.Configuration)
+}
 
-	resources, err := kubeClient.Deploy(ctx, manifest, model.Namespace, &LabelInterceptor{})
+func (r *runner) install(ctx context.Context, chartProvider *chart.Provider, model *reconciler.Reconciliation, kubeClient kubernetes.Client, postRenderInterceptor *kubernetes.PostRenderInterceptor) error {
+	var capabilities *chartutil.Capabilities
+	if r.clusterCapabilities {
+		clientSet, err := kubeClient.Clientset()
+		if err != nil {
+			return err
+		}
+		capabilities, err = chart.NewCapabilities(clientSet.Discovery())
+		if err != nil {
+			return errors.Wrap(err, "Failed to retrieve capabilities of target cluster")
+		}
+	}
 
-	if err == nil {
-		r.logger.Debugf("Deployment of manifest finished successfully: %d resources deployed", len(resources))
-	} else {
+	component := r.newComponent(model, capabilities)
+	chartManifest, manifest, err := r.renderManifest(chartProvider, model, component)
+	if err != nil {
+		return err
+	}
+
+	if capabilities != nil {
+		if err := chart.ValidateManifest(manifest, capabilities); err != nil {
+			r.logger.Warnf("Validation of manifest against cluster capabilities failed: %s", err)
+			return err
+		}
+	}
+
+	resources, err := kubeClient.Deploy(ctx, manifest, model.Namespace, postRenderInterceptor, &LabelInterceptor{})
+
+	if err != nil {
 		r.logger.Warnf("Failed to deploy manifests on target cluster: %s", err)
+		return err
 	}
+	r.logger.Debugf("Deployment of manifest finished successfully: %d resources deployed", len(resources))
 
-	return err
+	if r.helmReleases {
+		r.recordHelmRelease(chartProvider, component, chartManifest, kubeClient)
+	}
+
+	return nil
+}
+
+//recordHelmRelease stores the HELM release of the deployed component. The release is optional bookkeeping:
+//a failure is only logged as the component was already deployed successfully.
+func (r *runner) recordHelmRelease(chartProvider *chart.Provider, component *chart.Component, chartManifest *chart.Manifest, kubeClient kubernetes.Client) {
+	clientSet, err := kubeClient.Clientset()
+	if err == nil {
+		//the release contains only the manifest of the component (without CRDs)
+		err = chartProvider.RecordRelease(clientSet, component, chartManifest.Manifest)
+	}
+	if err != nil {
+		r.logger.Warnf("Failed to record HELM release of component '%s': %s", chartManifest.Name, err)
+	}
 }
 
-func (r *runner) renderManifest(chartProvider *chart.Provider, model *reconciler.Reconciliation) (string, error) {
-	component := chart.NewComponentBuilder(model.Version, model.Component).
+func (r *runner) newComponent(model *reconciler.Reconciliation, capabilities *chartutil.Capabilities) *chart.Component {
+	return chart.NewComponentBuilder(model.Version, model.Component).
 		WithProfile(model.Profile).
 		WithNamespace(model.Namespace).
+		WithValues(model.Values...).
 		WithConfiguration(model.Configuration).
+		WithCapabilities(capabilities).
 		Build()
+}
 
+//renderManifest returns the manifest of the component and the manifest to deploy (including the Kyma CRDs if requested)
+func (r *runner) renderManifest(chartProvider *chart.Provider, model *reconciler.Reconciliation, component *chart.Component) (*chart.Manifest, string, error) {
 	var manifests []*chart.Manifest
 
 	//get manifest of component
@@ -158,7 +238,7 @@ func (r *runner) renderManifest(chartProvider *chart.Provider, model *reconciler
 		msg := fmt.Sprintf("Failed to get manifest for component '%s' in Kyma version '%s'",
 			model.Component, model.Version)
 		r.logger.Errorf("%s: %s", msg, err)
-		return "", errors.Wrap(err, msg)
+		return nil, "", errors.Wrap(err, msg)
 	}
 	manifests = append(manifests, chartManifest)
 
@@ -168,10 +248,10 @@ func (r *runner) renderManifest(chartProvider *chart.Provider, model *reconciler
 		if err != nil {
 			msg := fmt.Sprintf("Failed to get CRD manifests for Kyma version '%s'", model.Version)
 			r.logger.Errorf("%s: %s", msg, err)
-			return "", errors.Wrap(err, msg)
+			return nil, "", errors.Wrap(err, msg)
 		}
 		manifests = append(manifests, crdManifests...)
 	}
 
-	return chart.MergeManifests(manifests...), nil
+	return chartManifest, chart.MergeManifests(manifests...), nil
 }
@@ -0,0 +1,159 @@
+package trigger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/interpreter"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	stateConfigMapPrefix = "reconciler-triggers-"
+	stateConfigMapKey    = "checksums"
+	defaultTimeout       = 1 * time.Minute //used if the context has no deadline
+)
+
+//Executor runs the trigger code of configuration keys
+type Executor struct {
+	registry  *Registry
+	clientSet kubernetes.Interface
+	logger    *zap.SugaredLogger
+}
+
+func NewExecutor(registry *Registry, clientSet kubernetes.Interface, logger *zap.SugaredLogger) *Executor {
+	if registry == nil {
+		registry = NewRegistry()
+	}
+	return &Executor{
+		registry:  registry,
+		clientSet: clientSet,
+		logger:    logger,
+	}
+}
+
+//Execute evaluates the trigger code: the configuration key and value are bound to the variables 'key' and 'value'.
+//The execution is aborted if the context is done and may take until the deadline of the context.
+func (e *Executor) Execute(ctx context.Context, cfg reconciler.Configuration) error {
+	triggerCtx := &Context{
+		Context:   ctx,
+		ClientSet: e.clientSet,
+		Logger:    e.logger,
+	}
+	timeout := defaultTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	_, err := interpreter.NewGolangInterpreter(cfg.Trigger).
+		WithContext(ctx).
+		WithTimeout(timeout).
+		WithBindings(map[string]interface{}{
+			"key":   cfg.Key,
+			"value": cfg.Value,
+		}).
+		WithFunctions(e.registry.bind(triggerCtx)).
+		Eval()
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to evaluate trigger of key '%s'", cfg.Key))
+	}
+	if len(triggerCtx.Errors()) > 0 {
+		var msgs []string
+		for _, err := range triggerCtx.Errors() {
+			msgs = append(msgs, err.Error())
+		}
+		return fmt.Errorf("trigger of key '%s' failed: %s", cfg.Key, strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+//ExecuteChanged runs the triggers of all configuration values which changed since the last successful execution.
+//Only keys of the component are considered (prefixed by '<component>.'): the configuration contains the keys of all
+//components of the cluster and the trigger of a shared key would otherwise run once per component.
+//The checksums of the values are stored in a ConfigMap in the namespace of the component. If no ConfigMap exists,
+//only the checksums are stored and no trigger is executed: this is the case during the first reconciliation of a
+//component (its resources are deployed with the current values) but also during the first reconciliation of a
+//component which was deployed by a reconciler without trigger support.
+func (e *Executor) ExecuteChanged(ctx context.Context, component, namespace string, configuration []reconciler.Configuration) error {
+	var triggerCfgs []reconciler.Configuration
+	for _, cfg := range configuration {
+		if cfg.Trigger != "" && strings.HasPrefix(cfg.Key, component+".") {
+			triggerCfgs = append(triggerCfgs, cfg)
+		}
+	}
+	if len(triggerCfgs) == 0 {
+		return nil
+	}
+
+	configMapName := stateConfigMapPrefix + component
+	configMap, err := e.clientSet.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+	if err != nil && !k8serr.IsNotFound(err) {
+		return errors.Wrap(err, fmt.Sprintf("failed to retrieve trigger state of component '%s'", component))
+	}
+	firstRun := k8serr.IsNotFound(err)
+	if firstRun {
+		e.logger.Infof("No trigger state found for component '%s': storing checksums of the configuration values "+
+			"without executing triggers", component)
+		configMap = nil
+	}
+
+	oldChecksums := make(map[string]string)
+	if !firstRun && configMap.Data[stateConfigMapKey] != "" {
+		if err := json.Unmarshal([]byte(configMap.Data[stateConfigMapKey]), &oldChecksums); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to unmarshal trigger state of component '%s'", component))
+		}
+	}
+
+	newChecksums := make(map[string]string, len(triggerCfgs))
+	for _, cfg := range triggerCfgs {
+		newChecksums[cfg.Key] = checksum(cfg.Value)
+		if firstRun {
+			continue
+		}
+		if oldChecksums[cfg.Key] == newChecksums[cfg.Key] {
+			continue
+		}
+		e.logger.Infof("Value of key '%s' changed: executing trigger '%s'", cfg.Key, cfg.Trigger)
+		if err := e.Execute(ctx, cfg); err != nil {
+			return err
+		}
+	}
+
+	return e.storeChecksums(ctx, component, namespace, configMap, newChecksums)
+}
+
+func (e *Executor) storeChecksums(ctx context.Context, component, namespace string, configMap *v1.ConfigMap, checksums map[string]string) error {
+	data, err := json.Marshal(checksums)
+	if err != nil {
+		return err
+	}
+	if configMap == nil {
+		_, err = e.clientSet.CoreV1().ConfigMaps(namespace).Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      stateConfigMapPrefix + component,
+				Namespace: namespace,
+			},
+			Data: map[string]string{stateConfigMapKey: string(data)},
+		}, metav1.CreateOptions{})
+	} else {
+		configMap.Data = map[string]string{stateConfigMapKey: string(data)}
+		_, err = e.clientSet.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to store trigger state of component '%s'", component))
+	}
+	return nil
+}
+
+func checksum(value string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(value)))
+}
@@ -0,0 +1,167 @@
+package trigger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestExecutor(t *testing.T) {
+	log, err := logger.NewLogger(true)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	newClientSet := func() *fake.Clientset {
+		return fake.NewSimpleClientset(
+			&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "test", Labels: map[string]string{"app": "test"}}},
+			&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "test", Labels: map[string]string{"app": "other"}}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "deploy", Namespace: "test", Labels: map[string]string{"app": "test"}}},
+			&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "test"}},
+		)
+	}
+
+	t.Run("Execute built-in functions", func(t *testing.T) {
+		clientSet := newClientSet()
+		executor := NewExecutor(nil, clientSet, log)
+
+		err := executor.Execute(ctx, reconciler.Configuration{
+			Key:   "my.key",
+			Value: "abc",
+			Trigger: `
+restartPods("test", "app=test")
+rolloutRestart("Deployment", "test", "deploy")
+deleteJob("test", "job")
+annotate("deployment", "test", "deploy", key, value)
+`,
+		})
+		require.NoError(t, err)
+
+		pods, err := clientSet.CoreV1().Pods("test").List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, pods.Items, 1)
+		require.Equal(t, "pod2", pods.Items[0].Name)
+
+		_, err = clientSet.BatchV1().Jobs("test").Get(ctx, "job", metav1.GetOptions{})
+		require.True(t, k8serr.IsNotFound(err))
+
+		deployment, err := clientSet.AppsV1().Deployments("test").Get(ctx, "deploy", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "abc", deployment.Annotations["my.key"])
+		require.NotEmpty(t, deployment.Spec.Template.Annotations[restartedAtAnnotation])
+	})
+
+	t.Run("Fail if a function fails", func(t *testing.T) {
+		executor := NewExecutor(nil, newClientSet(), log)
+		err := executor.Execute(ctx, reconciler.Configuration{
+			Key:     "my.key",
+			Trigger: `rolloutRestart("pod", "test", "pod1")`,
+		})
+		require.Error(t, err)
+
+		err = executor.Execute(ctx, reconciler.Configuration{
+			Key:     "my.key",
+			Trigger: `deleteJob("test", "notExisting")`,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("Fail for unknown function", func(t *testing.T) {
+		executor := NewExecutor(nil, newClientSet(), log)
+		err := executor.Execute(ctx, reconciler.Configuration{
+			Key:     "my.key",
+			Trigger: `notExisting("test")`,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("Execute custom function", func(t *testing.T) {
+		var called string
+		registry := NewRegistry()
+		require.NoError(t, registry.Register("custom", func(ctx *Context) interface{} {
+			return func(value string) {
+				called = value
+			}
+		}))
+		require.Error(t, registry.Register("not-valid", func(ctx *Context) interface{} { return nil }))
+		require.Contains(t, registry.Names(), "restartPods")
+
+		executor := NewExecutor(registry, newClientSet(), log)
+		require.NoError(t, executor.Execute(ctx, reconciler.Configuration{
+			Key:     "my.key",
+			Value:   "xyz",
+			Trigger: `custom(value)`,
+		}))
+		require.Equal(t, "xyz", called)
+	})
+
+	t.Run("Abort if the context is done", func(t *testing.T) {
+		clientSet := newClientSet()
+		executor := NewExecutor(nil, clientSet, log)
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		err := executor.Execute(cancelledCtx, reconciler.Configuration{
+			Key:     "my.key",
+			Trigger: `restartPods("test", "app=test")`,
+		})
+		require.Error(t, err)
+		pods, err := clientSet.CoreV1().Pods("test").List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, pods.Items, 2)
+	})
+
+	t.Run("Execute triggers of changed values", func(t *testing.T) {
+		clientSet := newClientSet()
+		executor := NewExecutor(nil, clientSet, log)
+
+		podCount := func() int {
+			pods, err := clientSet.CoreV1().Pods("test").List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			return len(pods.Items)
+		}
+
+		configuration := []reconciler.Configuration{
+			{Key: "component.key.with.trigger", Value: "1", Trigger: `restartPods("test", "app=test")`},
+			{Key: "component.key.without.trigger", Value: "1"},
+			{Key: "other.key.with.trigger", Value: "1", Trigger: `deleteJob("test", "job")`},
+		}
+
+		//first run: triggers are not executed
+		require.NoError(t, executor.ExecuteChanged(ctx, "component", "test", configuration))
+		require.Equal(t, 2, podCount())
+		_, err := clientSet.CoreV1().ConfigMaps("test").Get(ctx, stateConfigMapPrefix+"component", metav1.GetOptions{})
+		require.NoError(t, err)
+
+		//unchanged value: triggers are not executed
+		require.NoError(t, executor.ExecuteChanged(ctx, "component", "test", configuration))
+		require.Equal(t, 2, podCount())
+
+		//changed value: trigger is executed
+		configuration[0].Value = "2"
+		require.NoError(t, executor.ExecuteChanged(ctx, "component", "test", configuration))
+		require.Equal(t, 1, podCount())
+
+		//changed value of another component: trigger is not executed
+		configuration[2].Value = "2"
+		require.NoError(t, executor.ExecuteChanged(ctx, "component", "test", configuration))
+		_, err = clientSet.BatchV1().Jobs("test").Get(ctx, "job", metav1.GetOptions{})
+		require.NoError(t, err)
+
+		//failing trigger: state is not updated and trigger is executed again
+		configuration[0].Value = "3"
+		configuration[0].Trigger = `deleteJob("test", "notExisting")`
+		require.Error(t, executor.ExecuteChanged(ctx, "component", "test", configuration))
+		configuration[0].Trigger = `deleteJob("test", "job")`
+		require.NoError(t, executor.ExecuteChanged(ctx, "component", "test", configuration))
+		_, err = clientSet.BatchV1().Jobs("test").Get(ctx, "job", metav1.GetOptions{})
+		require.True(t, k8serr.IsNotFound(err))
+	})
+}
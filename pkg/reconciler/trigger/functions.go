@@ -0,0 +1,170 @@
+package trigger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+//builtinFunctions are available in any trigger code:
+//
+//  restartPods(namespace, labelSelector)                   deletes all pods matching the label selector
+//  restartDeployments(namespace, labelSelector)            rollout restart of all deployments matching the label selector
+//  rolloutRestart(kind, namespace, name)                   rollout restart of a deployment, statefulset or daemonset
+//  deleteJob(namespace, name)                              deletes a job (inclusive its pods)
+//  annotate(kind, namespace, name, annotation, value)      sets an annotation on a resource
+var builtinFunctions = map[string]Function{
+	"restartPods":        restartPods,
+	"restartDeployments": restartDeployments,
+	"rolloutRestart":     rolloutRestart,
+	"deleteJob":          deleteJob,
+	"annotate":           annotate,
+}
+
+func restartPods(ctx *Context) interface{} {
+	return func(namespace, selector string) {
+		pods, err := ctx.ClientSet.CoreV1().Pods(namespace).List(ctx.Context, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			ctx.Fail(errors.Wrap(err, fmt.Sprintf("failed to list pods in namespace '%s' with selector '%s'", namespace, selector)))
+			return
+		}
+		for _, pod := range pods.Items {
+			ctx.Logger.Infof("Trigger restarts pod '%s' in namespace '%s'", pod.Name, namespace)
+			if err := ctx.ClientSet.CoreV1().Pods(namespace).Delete(ctx.Context, pod.Name, metav1.DeleteOptions{}); err != nil {
+				ctx.Fail(errors.Wrap(err, fmt.Sprintf("failed to delete pod '%s' in namespace '%s'", pod.Name, namespace)))
+			}
+		}
+	}
+}
+
+func restartDeployments(ctx *Context) interface{} {
+	return func(namespace, selector string) {
+		deployments, err := ctx.ClientSet.AppsV1().Deployments(namespace).List(ctx.Context, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			ctx.Fail(errors.Wrap(err, fmt.Sprintf("failed to list deployments in namespace '%s' with selector '%s'", namespace, selector)))
+			return
+		}
+		for _, deployment := range deployments.Items {
+			if err := rolloutRestartResource(ctx, "deployment", namespace, deployment.Name); err != nil {
+				ctx.Fail(err)
+			}
+		}
+	}
+}
+
+func rolloutRestart(ctx *Context) interface{} {
+	return func(kind, namespace, name string) {
+		if err := rolloutRestartResource(ctx, kind, namespace, name); err != nil {
+			ctx.Fail(err)
+		}
+	}
+}
+
+func rolloutRestartResource(ctx *Context, kind, namespace, name string) error {
+	ctx.Logger.Infof("Trigger restarts %s '%s' in namespace '%s'", kind, name, namespace)
+
+	//same approach as used by 'kubectl rollout restart'
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{
+						restartedAtAnnotation: time.Now().Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	apps := ctx.ClientSet.AppsV1()
+	switch strings.ToLower(kind) {
+	case "deployment":
+		_, err = apps.Deployments(namespace).Patch(ctx.Context, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case "statefulset":
+		_, err = apps.StatefulSets(namespace).Patch(ctx.Context, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case "daemonset":
+		_, err = apps.DaemonSets(namespace).Patch(ctx.Context, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	default:
+		return fmt.Errorf("rollout restart is not supported for kind '%s' (supported kinds are deployment, statefulset and daemonset)", kind)
+	}
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to restart %s '%s' in namespace '%s'", kind, name, namespace))
+	}
+	return nil
+}
+
+func deleteJob(ctx *Context) interface{} {
+	return func(namespace, name string) {
+		ctx.Logger.Infof("Trigger deletes job '%s' in namespace '%s'", name, namespace)
+		propagation := metav1.DeletePropagationBackground
+		err := ctx.ClientSet.BatchV1().Jobs(namespace).Delete(ctx.Context, name, metav1.DeleteOptions{
+			PropagationPolicy: &propagation,
+		})
+		if err != nil {
+			ctx.Fail(errors.Wrap(err, fmt.Sprintf("failed to delete job '%s' in namespace '%s'", name, namespace)))
+		}
+	}
+}
+
+func annotate(ctx *Context) interface{} {
+	return func(kind, namespace, name, annotation, value string) {
+		if err := annotateResource(ctx, kind, namespace, name, annotation, value); err != nil {
+			ctx.Fail(err)
+		}
+	}
+}
+
+func annotateResource(ctx *Context, kind, namespace, name, annotation, value string) error {
+	ctx.Logger.Infof("Trigger sets annotation '%s' on %s '%s' in namespace '%s'", annotation, kind, name, namespace)
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				annotation: value,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	clientSet := ctx.ClientSet
+	patchType := types.MergePatchType
+	opts := metav1.PatchOptions{}
+	switch strings.ToLower(kind) {
+	case "pod":
+		_, err = clientSet.CoreV1().Pods(namespace).Patch(ctx.Context, name, patchType, patch, opts)
+	case "service":
+		_, err = clientSet.CoreV1().Services(namespace).Patch(ctx.Context, name, patchType, patch, opts)
+	case "configmap":
+		_, err = clientSet.CoreV1().ConfigMaps(namespace).Patch(ctx.Context, name, patchType, patch, opts)
+	case "secret":
+		_, err = clientSet.CoreV1().Secrets(namespace).Patch(ctx.Context, name, patchType, patch, opts)
+	case "namespace":
+		_, err = clientSet.CoreV1().Namespaces().Patch(ctx.Context, name, patchType, patch, opts)
+	case "deployment":
+		_, err = clientSet.AppsV1().Deployments(namespace).Patch(ctx.Context, name, patchType, patch, opts)
+	case "statefulset":
+		_, err = clientSet.AppsV1().StatefulSets(namespace).Patch(ctx.Context, name, patchType, patch, opts)
+	case "daemonset":
+		_, err = clientSet.AppsV1().DaemonSets(namespace).Patch(ctx.Context, name, patchType, patch, opts)
+	case "job":
+		_, err = clientSet.BatchV1().Jobs(namespace).Patch(ctx.Context, name, patchType, patch, opts)
+	default:
+		return fmt.Errorf("annotating resources of kind '%s' is not supported", kind)
+	}
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to annotate %s '%s' in namespace '%s'", kind, name, namespace))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+)
+
+var functionNameRegExp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+//Context is passed to trigger functions and tracks the errors which occurred during their execution
+type Context struct {
+	Context   context.Context
+	ClientSet kubernetes.Interface
+	Logger    *zap.SugaredLogger
+	errors    []error
+}
+
+//Fail records an error of a trigger function: the trigger execution will fail after the trigger code was evaluated
+func (c *Context) Fail(err error) {
+	c.errors = append(c.errors, err)
+}
+
+func (c *Context) Errors() []error {
+	return c.errors
+}
+
+//Function returns the Go function which gets called by the trigger code
+//(the returned function has to be bound to the provided context)
+type Function func(ctx *Context) interface{}
+
+//Registry contains the functions which can be called by trigger code
+type Registry struct {
+	functions map[string]Function
+}
+
+//NewRegistry returns a registry which includes all built-in trigger functions
+func NewRegistry() *Registry {
+	registry := &Registry{
+		functions: make(map[string]Function),
+	}
+	for name, fct := range builtinFunctions {
+		registry.functions[name] = fct
+	}
+	return registry
+}
+
+func (r *Registry) Register(name string, fct Function) error {
+	if !functionNameRegExp.MatchString(name) {
+		return fmt.Errorf("trigger function name '%s' is not a valid identifier", name)
+	}
+	if fct == nil {
+		return fmt.Errorf("trigger function '%s' is undefined", name)
+	}
+	r.functions[name] = fct
+	return nil
+}
+
+func (r *Registry) Names() []string {
+	var names []string
+	for name := range r.functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Registry) bind(ctx *Context) map[string]interface{} {
+	result := make(map[string]interface{}, len(r.functions))
+	for name, fct := range r.functions {
+		result[name] = fct(ctx)
+	}
+	return result
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
-	"bytes"
+	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/kyma-incubator/reconciler/pkg/db"
 	"github.com/kyma-incubator/reconciler/pkg/logger"
@@ -23,7 +25,8 @@ type record struct {
 
 type invalidate struct {
 	*cacheDependencyManager
-	selector map[string]interface{}
+	selector  map[string]interface{}
+	reconcile bool
 }
 
 type get struct {
@@ -42,8 +45,22 @@ func newCacheDependencyManager(conn db.Connection, debug bool) (*cacheDependency
 	}, nil
 }
 
-func (cdm *cacheDependencyManager) transactional(desc string, dbOps func() error) error {
-	if err := db.Transaction(cdm.conn, dbOps, cdm.logger); err != nil {
+//withConn returns a copy of the cache dependency manager which uses the connection (e.g. a transaction)
+func (cdm *cacheDependencyManager) withConn(conn db.Connection) *cacheDependencyManager {
+	return &cacheDependencyManager{
+		conn:   conn,
+		logger: cdm.logger,
+	}
+}
+
+func (cdm *cacheDependencyManager) exec(desc string, newTx bool, dbOps func(conn db.Connection) error) error {
+	if !newTx { //no new DB transaction requested
+		return dbOps(cdm.conn)
+	}
+	txDbOps := func(tx *db.Tx) error {
+		return dbOps(tx)
+	}
+	if err := db.Transaction(cdm.conn, txDbOps, cdm.logger); err != nil {
 		return fmt.Errorf("Failed to execute database transaction '%s': %s", desc, err)
 	}
 	return nil
@@ -61,10 +78,10 @@ func (r *record) Exec(newTx bool) error {
 	if r.cacheEntry.ID <= 0 {
 		return fmt.Errorf("Cache entry '%s' has no ID: indicates that cache entity is not persisted in database", r.cacheEntry)
 	}
-	dbOps := func() error {
+	dbOps := func(conn db.Connection) error {
 		//track deps in DB
 		for _, value := range r.cacheDeps {
-			q, err := db.NewQuery(r.conn, &model.CacheDependencyEntity{
+			q, err := db.NewQuery(conn, &model.CacheDependencyEntity{
 				Bucket:  value.Bucket,
 				Key:     value.Key,
 				Label:   r.cacheEntry.Label,
@@ -81,16 +98,14 @@ func (r *record) Exec(newTx bool) error {
 		return nil
 	}
 
-	if newTx { //start new DB transaction
-		return r.transactional("recording cache dependencies", dbOps)
-	}
-	return dbOps() //no new DB transaction requested
+	return r.exec("recording cache dependencies", newTx, dbOps)
 }
 
 func (cdm *cacheDependencyManager) Invalidate() *invalidate {
 	return &invalidate{
 		cdm,
 		make(map[string]interface{}),
+		false,
 	}
 }
 
@@ -114,15 +129,22 @@ func (i *invalidate) WithCacheID(cacheID int64) *invalidate {
 	return i.with("CacheID", cacheID)
 }
 
+//WithClusterReconciliation marks the clusters of the invalidated cache entries as 'reconcile_pending'
+//(only clusters whose status is allowed to switch to 'reconcile_pending', e.g. running reconciliations are not touched)
+func (i *invalidate) WithClusterReconciliation() *invalidate {
+	i.reconcile = true
+	return i
+}
+
 func (i *invalidate) with(colName string, colValue interface{}) *invalidate {
 	i.selector[colName] = colValue
 	return i
 }
 
 func (i *invalidate) Exec(newTx bool) error {
-	dbOps := func() error {
+	dbOps := func(conn db.Connection) error {
 		//get cache dependencies
-		depQuery, err := db.NewQuery(i.conn, &model.CacheDependencyEntity{})
+		depQuery, err := db.NewQuery(conn, &model.CacheDependencyEntity{})
 		if err != nil {
 			return err
 		}
@@ -142,56 +164,125 @@ func (i *invalidate) Exec(newTx bool) error {
 		}
 
 		//get cache-entry IDs to invalidate
-		cacheEntityIdsCSV, cntUniqueIds := i.cacheIDsCSV(deps)
-		i.logger.Debugf("Identified %d cache entities which match selector '%v': %s", cntUniqueIds, i.selector, cacheEntityIdsCSV)
+		cacheEntityIDs := i.cacheIDs(deps)
+		i.logger.Debugf("Identified %d cache entities which match selector '%v': %v", len(cacheEntityIDs), i.selector, cacheEntityIDs)
 
 		//drop all cache entities
-		cacheQuery, err := db.NewQuery(i.conn, &model.CacheEntryEntity{})
+		cacheQuery, err := db.NewQuery(conn, &model.CacheEntryEntity{})
 		if err != nil {
 			return err
 		}
-		deletedEntries, err := cacheQuery.Delete().WhereIn("ID", cacheEntityIdsCSV).Exec()
+		deletedEntries, err := cacheQuery.Delete().WhereCondition(db.In("ID", cacheEntityIDs...)).Exec()
 		if err != nil {
 			return err
 		}
 		i.logger.Debugf("Deleted %d cache entries matching selector '%v'", deletedEntries, i.selector)
 
 		//drop all cache dependencies of the dropped cache entities
-		cacheDepQuery, err := db.NewQuery(i.conn, &model.CacheDependencyEntity{})
+		cacheDepQuery, err := db.NewQuery(conn, &model.CacheDependencyEntity{})
 		if err != nil {
 			return err
 		}
-		deletedDeps, err := cacheDepQuery.Delete().WhereIn("CacheID", cacheEntityIdsCSV).Exec()
+		deletedDeps, err := cacheDepQuery.Delete().WhereCondition(db.In("CacheID", cacheEntityIDs...)).Exec()
 		if err != nil {
 			return err
 		}
 		i.logger.Debugf("Deleted %d cache dependencies matching selector '%v'", deletedDeps, i.selector)
 
+		//schedule a reconciliation of the clusters which were using the invalidated cache entries
+		if i.reconcile {
+			return i.reconcileClusters(conn, deps)
+		}
 		return nil
 	}
 
-	if newTx { //start new DB transaction
-		return i.transactional("invalidating cache entries", dbOps)
+	return i.exec("invalidating cache entries", newTx, dbOps)
+}
+
+func (i *invalidate) reconcileClusters(conn db.Connection, deps []db.DatabaseEntity) error {
+	deduplicate := make(map[string]interface{}, len(deps))
+	var clusters []string
+	for _, dep := range deps {
+		depEntity := dep.(*model.CacheDependencyEntity)
+		if _, ok := deduplicate[depEntity.Cluster]; ok || depEntity.Cluster == "" {
+			continue
+		}
+		deduplicate[depEntity.Cluster] = nil
+		clusters = append(clusters, depEntity.Cluster)
+	}
+	sort.Strings(clusters)
+
+	for _, cluster := range clusters {
+		//serialize with status updates of the cluster inventory (uses the same lock)
+		if tx, ok := conn.(*db.Tx); ok {
+			if err := tx.Lock(cluster); err != nil {
+				return err
+			}
+		}
+
+		//get latest status of the cluster
+		statusQuery, err := db.NewQuery(conn, &model.ClusterStatusEntity{})
+		if err != nil {
+			return err
+		}
+		statusEntity, err := statusQuery.Select().
+			Where(map[string]interface{}{"Cluster": cluster}).
+			OrderBy(map[string]string{"ID": "desc"}).
+			GetOne()
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) { //cluster is not (or no longer) part of the inventory
+				i.logger.Debugf("Cluster '%s' of invalidated cache entry not found in inventory: "+
+					"reconciliation not scheduled", cluster)
+				continue
+			}
+			return err
+		}
+
+		oldStatus := statusEntity.(*model.ClusterStatusEntity)
+		if oldStatus.Status == model.ClusterStatusReconcilePending {
+			i.logger.Debugf("Cluster '%s' is already in status '%s'", cluster, oldStatus.Status)
+			continue
+		}
+		if err := model.ValidateStatusTransition(oldStatus.Status, model.ClusterStatusReconcilePending); err != nil {
+			//e.g. a running reconciliation: the cluster picks up the changed values with its next reconciliation
+			i.logger.Infof("Cluster '%s' is in status '%s': cache invalidation (selector '%v') doesn't change its status",
+				cluster, oldStatus.Status, i.selector)
+			continue
+		}
+
+		//add new status entry to keep the status history traceable
+		newStatus := &model.ClusterStatusEntity{
+			Cluster:        oldStatus.Cluster,
+			ClusterVersion: oldStatus.ClusterVersion,
+			ConfigVersion:  oldStatus.ConfigVersion,
+			Status:         model.ClusterStatusReconcilePending,
+		}
+		insertQuery, err := db.NewQuery(conn, newStatus)
+		if err != nil {
+			return err
+		}
+		if err := insertQuery.Insert().Exec(); err != nil {
+			return err
+		}
+		i.logger.Infof("Cache invalidation (selector '%v') changed status of cluster '%s' from '%s' to '%s'",
+			i.selector, cluster, oldStatus.Status, newStatus.Status)
 	}
-	return dbOps() //no new DB transaction requested
+
+	return nil
 }
 
-func (i *invalidate) cacheIDsCSV(deps []db.DatabaseEntity) (string, int) {
+func (i *invalidate) cacheIDs(deps []db.DatabaseEntity) []interface{} {
 	deduplicate := make(map[int64]interface{}, len(deps))
-	var buffer bytes.Buffer
+	var result []interface{}
 	for _, dep := range deps {
 		depEntity := dep.(*model.CacheDependencyEntity)
 		if _, ok := deduplicate[depEntity.CacheID]; ok {
 			continue
 		}
 		deduplicate[depEntity.CacheID] = nil //remember the cache IDs which are processed
-
-		if buffer.Len() > 0 {
-			buffer.WriteRune(',')
-		}
-		buffer.WriteString(fmt.Sprintf("%d", depEntity.CacheID))
+		result = append(result, depEntity.CacheID)
 	}
-	return buffer.String(), len(deduplicate)
+	return result
 }
 
 func (cdm *cacheDependencyManager) Get() *get {
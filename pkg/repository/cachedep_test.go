@@ -127,6 +127,77 @@ func TestCacheDependencyManager(t *testing.T) {
 		})
 	})
 
+	t.Run("Invalidate dependencies and reconcile clusters", func(t *testing.T) {
+		withTestData(t, func(t *testing.T, testEntries []*model.CacheEntryEntity, testDeps []*model.CacheDependencyEntity) {
+			//testCluster2 is part of the inventory, testCluster1 not
+			statusQuery, err := db.NewQuery(cacheDep.conn, &model.ClusterStatusEntity{
+				Cluster:        "testCluster2",
+				ClusterVersion: 1,
+				ConfigVersion:  1,
+				Status:         model.ClusterStatusReady,
+			})
+			require.NoError(t, err)
+			require.NoError(t, statusQuery.Insert().Exec())
+			defer func() {
+				_, err := statusQuery.Delete().Where(map[string]interface{}{"Cluster": "testCluster2"}).Exec()
+				require.NoError(t, err)
+			}()
+
+			//key1 is used by both clusters
+			err = cacheDep.Invalidate().WithKey("key1").WithClusterReconciliation().Exec(true)
+			require.NoError(t, err)
+
+			statuses, err := statusQuery.Select().
+				Where(map[string]interface{}{"Cluster": "testCluster2"}).
+				OrderBy(map[string]string{"ID": "asc"}).
+				GetMany()
+			require.NoError(t, err)
+			require.Len(t, statuses, 2)
+			require.Equal(t, model.ClusterStatusReady, statuses[0].(*model.ClusterStatusEntity).Status)
+			require.Equal(t, model.ClusterStatusReconcilePending, statuses[1].(*model.ClusterStatusEntity).Status)
+			require.Equal(t, int64(1), statuses[1].(*model.ClusterStatusEntity).ConfigVersion)
+
+			statuses, err = statusQuery.Select().
+				Where(map[string]interface{}{"Cluster": "testCluster1"}).
+				GetMany()
+			require.NoError(t, err)
+			require.Empty(t, statuses)
+		})
+	})
+
+	t.Run("Invalidate dependencies of reconciling cluster", func(t *testing.T) {
+		withTestData(t, func(t *testing.T, testEntries []*model.CacheEntryEntity, testDeps []*model.CacheDependencyEntity) {
+			statusQuery, err := db.NewQuery(cacheDep.conn, &model.ClusterStatusEntity{
+				Cluster:        "testCluster2",
+				ClusterVersion: 1,
+				ConfigVersion:  1,
+				Status:         model.ClusterStatusReconciling,
+			})
+			require.NoError(t, err)
+			require.NoError(t, statusQuery.Insert().Exec())
+			defer func() {
+				_, err := statusQuery.Delete().Where(map[string]interface{}{"Cluster": "testCluster2"}).Exec()
+				require.NoError(t, err)
+			}()
+
+			//a running reconciliation cannot switch to 'reconcile_pending'
+			err = cacheDep.Invalidate().WithKey("key1").WithClusterReconciliation().Exec(true)
+			require.NoError(t, err)
+
+			statuses, err := statusQuery.Select().
+				Where(map[string]interface{}{"Cluster": "testCluster2"}).
+				GetMany()
+			require.NoError(t, err)
+			require.Len(t, statuses, 1)
+			require.Equal(t, model.ClusterStatusReconciling, statuses[0].(*model.ClusterStatusEntity).Status)
+
+			//cache entry is invalidated anyway
+			deps, err := cacheDep.Get().WithKey("key1").Exec()
+			require.NoError(t, err)
+			require.Empty(t, deps)
+		})
+	})
+
 	t.Run("Get dependencies", func(t *testing.T) {
 		withTestData(t, func(t *testing.T, testEntries []*model.CacheEntryEntity, testDeps []*model.CacheDependencyEntity) {
 			depsByCacheID, err := cacheDep.Get().WithCacheID(testEntries[1].ID).Exec()
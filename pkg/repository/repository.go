@@ -13,6 +13,7 @@ type Repository struct {
 	Conn     db.Connection
 	Logger   *zap.SugaredLogger
 	CacheDep *cacheDependencyManager
+	Actor    string //user or system component which applies the writes (recorded in the audit log)
 }
 
 func NewRepository(dbFac db.ConnectionFactory, debug bool) (*Repository, error) {
@@ -35,16 +36,43 @@ func NewRepository(dbFac db.ConnectionFactory, debug bool) (*Repository, error)
 	}, nil
 }
 
-func (r *Repository) TransactionalResult(dbOps func() (interface{}, error)) (interface{}, error) {
+//WithTx returns a copy of the repository which executes all statements within the transaction
+func (r *Repository) WithTx(tx *db.Tx) *Repository {
+	return &Repository{
+		Conn:     tx,
+		Logger:   r.Logger,
+		CacheDep: r.CacheDep.withConn(tx),
+		Actor:    r.Actor,
+	}
+}
+
+//WithActor returns a copy of the repository which records its writes in the name of the actor
+func (r *Repository) WithActor(actor string) *Repository {
+	return &Repository{
+		Conn:     r.Conn,
+		Logger:   r.Logger,
+		CacheDep: r.CacheDep,
+		Actor:    actor,
+	}
+}
+
+//TransactionalResult executes the DB operations within a transaction: the DB operations have to use the passed
+//transaction (e.g. by using a repository returned by WithTx) otherwise their statements are not part of it
+func (r *Repository) TransactionalResult(dbOps func(tx *db.Tx) (interface{}, error)) (interface{}, error) {
 	return db.TransactionResult(r.Conn, dbOps, r.Logger)
 }
 
-func (r *Repository) Transactional(dbOps func() error) error {
+func (r *Repository) Transactional(dbOps func(tx *db.Tx) error) error {
 	return db.Transaction(r.Conn, dbOps, r.Logger)
 }
 
 func (r *Repository) NewNotFoundError(err error, entity db.DatabaseEntity,
 	identifier map[string]interface{}) error {
+	return NewEntityNotFoundError(err, entity, identifier)
+}
+
+//NewEntityNotFoundError is used by implementations which don't retrieve their entities from a repository
+func NewEntityNotFoundError(err error, entity db.DatabaseEntity, identifier map[string]interface{}) error {
 	return &EntityNotFoundError{
 		entity:     entity,
 		identifier: identifier,
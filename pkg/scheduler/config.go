@@ -13,4 +13,5 @@ type MothershipReconcilerConfig struct {
 	Port          int
 	CrdComponents []string
 	PreComponents []string
+	Buckets       []string //ordered sequence of buckets used to calculate the configuration of a cluster
 }
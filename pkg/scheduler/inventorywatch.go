@@ -7,6 +7,7 @@ import (
 
 	"github.com/kyma-incubator/reconciler/pkg/cluster"
 	"github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/kyma-incubator/reconciler/pkg/model"
 	"go.uber.org/zap"
 )
 
@@ -98,7 +99,24 @@ func (w *DefaultInventoryWatcher) processClustersToReconcile(queue InventoryQueu
 			w.logger.Debug("Nil cluster state when processing the list of clusters to reconcile")
 			continue
 		}
+		if clusterState.Status.Status == model.ClusterStatusReady {
+			//periodic reconciliation: the status has to pass the state machine like any other reconciliation
+			var err error
+			if clusterState, err = w.markReconcilePending(clusterState); err != nil {
+				w.logger.Warnf("Cluster '%s' is not added to reconciliation queue: %s", clusterState.Cluster.Cluster, err)
+				continue
+			}
+		}
 		w.logger.Debugf("Adding cluster '%s' to reconciliation queue", clusterState.Cluster.Cluster)
 		queue <- *clusterState
 	}
 }
+
+//markReconcilePending switches a ready cluster whose reconcile interval passed to the status ReconcilePending
+func (w *DefaultInventoryWatcher) markReconcilePending(clusterState *cluster.State) (*cluster.State, error) {
+	newState, err := w.inventory.UpdateStatus(clusterState.Status.ID, model.ClusterStatusReconcilePending)
+	if err != nil {
+		return clusterState, err
+	}
+	return newState, nil
+}
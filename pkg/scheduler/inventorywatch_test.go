@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/kyma-incubator/reconciler/pkg/cluster"
+	"github.com/kyma-incubator/reconciler/pkg/keb"
+	"github.com/kyma-incubator/reconciler/pkg/logger"
 	"github.com/kyma-incubator/reconciler/pkg/model"
 	"github.com/stretchr/testify/require"
 )
@@ -45,6 +47,47 @@ func TestInventoryWatch_ShouldStopOnCtxClose(t *testing.T) {
 	require.WithinDuration(t, startTime, time.Now(), 2*time.Second)
 }
 
+func TestInventoryWatch_PeriodicReconciliation(t *testing.T) {
+	inventory := cluster.NewInMemoryInventory(noopMetricsCollector{})
+	kebCluster := newStatusUpdaterCluster("periodic", "1.0.0")
+	state, err := inventory.CreateOrUpdate(1, kebCluster)
+	require.NoError(t, err)
+	for _, status := range []model.Status{model.ClusterStatusReconciling, model.ClusterStatusReady} {
+		state, err = inventory.UpdateStatus(state.Status.ID, status)
+		require.NoError(t, err)
+	}
+
+	watcher, err := NewInventoryWatch(inventory, true, &InventoryWatchConfig{ClusterReconcileInterval: 2 * time.Second})
+	require.NoError(t, err)
+	queue := make(chan cluster.State, 1)
+
+	//ready cluster is not reconciled within the interval
+	watcher.(*DefaultInventoryWatcher).processClustersToReconcile(queue)
+	require.Empty(t, queue)
+
+	//ready cluster is marked for reconciliation after the interval passed
+	time.Sleep(2 * time.Second)
+	watcher.(*DefaultInventoryWatcher).processClustersToReconcile(queue)
+	require.Len(t, queue, 1)
+	queuedState := <-queue
+	require.Equal(t, model.ClusterStatusReconcilePending, queuedState.Status.Status)
+
+	//reconciliation passes the state machine: reconciling -> ready
+	statusUpdater := NewClusterStatusUpdater(inventory, queuedState, []*keb.Components{{Component: "comp"}}, logger.NewOptionalLogger(true))
+	state, err = inventory.GetLatest(kebCluster.Cluster)
+	require.NoError(t, err)
+	require.Equal(t, model.ClusterStatusReconciling, state.Status.Status)
+	statusUpdater.statusMap["comp"] = model.OperationStateDone
+	statusUpdater.success()
+	state, err = inventory.GetLatest(kebCluster.Cluster)
+	require.NoError(t, err)
+	require.Equal(t, model.ClusterStatusReady, state.Status.Status)
+
+	//reconciled cluster is not picked again by the next watch cycle
+	watcher.(*DefaultInventoryWatcher).processClustersToReconcile(queue)
+	require.Empty(t, queue)
+}
+
 func mockState() *cluster.State {
 	return &cluster.State{
 		Cluster:       &model.ClusterEntity{},
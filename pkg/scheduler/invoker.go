@@ -3,10 +3,14 @@ package scheduler
 import (
 	"github.com/kyma-incubator/reconciler/pkg/cluster"
 	"github.com/kyma-incubator/reconciler/pkg/keb"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler"
 )
 
 type InvokeParams struct {
 	ComponentToReconcile *keb.Components
+	Configuration        []reconciler.Configuration
+	PostRender           []reconciler.PostRender
+	Values               []string
 	ComponentsReady      []string
 	ClusterState         cluster.State
 	SchedulingID         string
@@ -18,3 +22,11 @@ type InvokeParams struct {
 type ReconcilerInvoker interface {
 	Invoke(params *InvokeParams) error
 }
+
+//ConfigurationResolver calculates the effective configuration, the post-render declarations and the values documents
+//of a cluster component
+type ConfigurationResolver interface {
+	Configuration(component *keb.Components, state *cluster.State) ([]reconciler.Configuration, error)
+	PostRender(component *keb.Components, state *cluster.State) ([]reconciler.PostRender, error)
+	Values(component *keb.Components, state *cluster.State) ([]string, error)
+}
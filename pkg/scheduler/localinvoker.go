@@ -42,7 +42,9 @@ func (lri *LocalReconcilerInvoker) Invoke(params *InvokeParams) error {
 		Namespace:       params.ComponentToReconcile.Namespace,
 		Version:         params.ClusterState.Configuration.KymaVersion,
 		Profile:         params.ClusterState.Configuration.KymaProfile,
-		Configuration:   mapConfiguration(params.ComponentToReconcile.Configuration),
+		Configuration:   params.Configuration,
+		PostRender:      params.PostRender,
+		Values:          params.Values,
 		Kubeconfig:      params.ClusterState.Cluster.Kubeconfig,
 		CallbackFunc: func(status reconciler.Status) error {
 			if lri.statusFunc != nil {
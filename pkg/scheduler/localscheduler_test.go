@@ -176,6 +176,7 @@ func newWorkerFactory(t *testing.T) WorkerFactory {
 		func(component string, status reconciler.Status) {
 			t.Logf("Component %s has status %s", component, status)
 		},
+		nil,
 		true)
 	require.NoError(t, err)
 	return workerFactory
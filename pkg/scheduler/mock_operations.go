@@ -164,3 +164,33 @@ func (_m *MockOperationsRegistry) SetInProgress(correlationID string, scheduling
 
 	return r0
 }
+
+// SetSuperseded provides a mock function with given fields: correlationID, schedulingID, reason
+func (_m *MockOperationsRegistry) SetSuperseded(correlationID string, schedulingID string, reason string) error {
+	ret := _m.Called(correlationID, schedulingID, reason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(correlationID, schedulingID, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WithActor provides a mock function with given fields: actor
+func (_m *MockOperationsRegistry) WithActor(actor string) OperationsRegistry {
+	ret := _m.Called(actor)
+
+	var r0 OperationsRegistry
+	if rf, ok := ret.Get(0).(func(string) OperationsRegistry); ok {
+		r0 = rf(actor)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(OperationsRegistry)
+		}
+	}
+
+	return r0
+}
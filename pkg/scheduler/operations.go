@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/kyma-incubator/reconciler/pkg/audit"
 	"github.com/kyma-incubator/reconciler/pkg/db"
 	"github.com/kyma-incubator/reconciler/pkg/model"
 	"github.com/kyma-incubator/reconciler/pkg/repository"
@@ -20,6 +21,9 @@ type OperationsRegistry interface {
 	SetError(correlationID, schedulingID, reason string) error
 	SetClientError(correlationID, schedulingID, reason string) error
 	SetFailed(correlationID, schedulingID, reason string) error
+	SetSuperseded(correlationID, schedulingID, reason string) error
+	//WithActor returns a registry which records its writes in the audit log in the name of the actor
+	WithActor(actor string) OperationsRegistry
 }
 
 type OperationNotFoundError struct {
@@ -44,6 +48,25 @@ func IsOperationNotFoundError(err error) bool {
 	return ok
 }
 
+//auditOperation records a write of an operation in the audit log (the version of an operation is the version
+//of the cluster configuration it belongs to)
+func auditOperation(conn db.Connection, actor string, action model.AuditAction, op *model.OperationEntity, details string) error {
+	entry := &model.AuditEntity{
+		Actor:      actor,
+		Action:     action,
+		EntityType: model.AuditEntityOperation,
+		Target:     fmt.Sprintf("%s/%s", op.SchedulingID, op.CorrelationID),
+		Details:    details,
+	}
+	if action != model.AuditActionDelete {
+		entry.VersionAfter = op.ConfigVersion
+	}
+	if action != model.AuditActionCreate {
+		entry.VersionBefore = op.ConfigVersion
+	}
+	return audit.Record(conn, entry)
+}
+
 type PersistedOperationsRegistry struct {
 	*repository.Repository
 }
@@ -56,12 +79,22 @@ func NewPersistedOperationsRegistry(dbFac db.ConnectionFactory, debug bool) (Ope
 	return &PersistedOperationsRegistry{repo}, nil
 }
 
+//withTx returns a copy of the registry which executes all statements within the transaction
+func (or *PersistedOperationsRegistry) withTx(tx *db.Tx) *PersistedOperationsRegistry {
+	return &PersistedOperationsRegistry{or.Repository.WithTx(tx)}
+}
+
+func (or *PersistedOperationsRegistry) WithActor(actor string) OperationsRegistry {
+	return &PersistedOperationsRegistry{or.Repository.WithActor(actor)}
+}
+
 func (or *PersistedOperationsRegistry) GetDoneOperations(schedulingID string) ([]*model.OperationEntity, error) {
 	return nil, nil
 }
 
 func (or *PersistedOperationsRegistry) RegisterOperation(correlationID, schedulingID, component string, version int64) (*model.OperationEntity, error) {
-	dbOps := func() (interface{}, error) {
+	dbOps := func(tx *db.Tx) (interface{}, error) {
+		txRegistry := or.withTx(tx)
 		opEntity := &model.OperationEntity{
 			SchedulingID:  schedulingID,
 			CorrelationID: correlationID,
@@ -69,14 +102,14 @@ func (or *PersistedOperationsRegistry) RegisterOperation(correlationID, scheduli
 			Component:     component,
 			State:         model.OperationStateNew,
 		}
-		_, err := or.GetOperation(correlationID, schedulingID)
+		_, err := txRegistry.GetOperation(correlationID, schedulingID)
 		if err == nil {
 			return nil, fmt.Errorf("operation with the following id %s already registered", correlationID)
 		} else if !repository.IsNotFoundError(err) {
 			//unexpected error
 			return nil, err
 		} else {
-			q, err := db.NewQuery(or.Conn, opEntity)
+			q, err := db.NewQuery(txRegistry.Conn, opEntity)
 			if err != nil {
 				return nil, err
 			}
@@ -84,7 +117,7 @@ func (or *PersistedOperationsRegistry) RegisterOperation(correlationID, scheduli
 			if err != nil {
 				return nil, err
 			}
-			return opEntity, nil
+			return opEntity, auditOperation(txRegistry.Conn, txRegistry.Actor, model.AuditActionCreate, opEntity, "")
 		}
 	}
 	entity, err := db.TransactionResult(or.Conn, dbOps, or.Logger)
@@ -113,8 +146,9 @@ func (or *PersistedOperationsRegistry) GetOperation(correlationID, schedulingID
 }
 
 func (or *PersistedOperationsRegistry) RemoveOperation(correlationID, schedulingID string) error {
-	dbOps := func() (interface{}, error) {
-		_, err := or.GetOperation(correlationID, schedulingID)
+	dbOps := func(tx *db.Tx) (interface{}, error) {
+		txRegistry := or.withTx(tx)
+		op, err := txRegistry.GetOperation(correlationID, schedulingID)
 		if err != nil {
 			if !repository.IsNotFoundError(err) {
 				return nil, err
@@ -122,7 +156,7 @@ func (or *PersistedOperationsRegistry) RemoveOperation(correlationID, scheduling
 			return nil, fmt.Errorf("operation with the following id %s not found", correlationID)
 		}
 
-		q, err := db.NewQuery(or.Conn, &model.OperationEntity{})
+		q, err := db.NewQuery(txRegistry.Conn, &model.OperationEntity{})
 		if err != nil {
 			return nil, err
 		}
@@ -136,7 +170,7 @@ func (or *PersistedOperationsRegistry) RemoveOperation(correlationID, scheduling
 		if err != nil {
 			return nil, err
 		}
-		return nil, nil
+		return nil, auditOperation(txRegistry.Conn, txRegistry.Actor, model.AuditActionDelete, op, "")
 	}
 	_, err := db.TransactionResult(or.Conn, dbOps, or.Logger)
 	if err != nil {
@@ -165,9 +199,14 @@ func (or *PersistedOperationsRegistry) SetFailed(correlationID, schedulingID, re
 	return or.updateState(correlationID, schedulingID, model.OperationStateFailed, reason)
 }
 
+func (or *PersistedOperationsRegistry) SetSuperseded(correlationID, schedulingID, reason string) error {
+	return or.updateState(correlationID, schedulingID, model.OperationStateSuperseded, reason)
+}
+
 func (or *PersistedOperationsRegistry) updateState(correlationID, schedulingID, state, reason string) error {
-	dbOps := func() (interface{}, error) {
-		op, err := or.GetOperation(correlationID, schedulingID)
+	dbOps := func(tx *db.Tx) (interface{}, error) {
+		txRegistry := or.withTx(tx)
+		op, err := txRegistry.GetOperation(correlationID, schedulingID)
 		if err != nil {
 			if !repository.IsNotFoundError(err) {
 				return nil, err
@@ -175,7 +214,7 @@ func (or *PersistedOperationsRegistry) updateState(correlationID, schedulingID,
 			return nil, fmt.Errorf("operation with the following id %s not found", correlationID)
 		}
 
-		q, err := db.NewQuery(or.Conn, &model.OperationEntity{
+		q, err := db.NewQuery(txRegistry.Conn, &model.OperationEntity{
 			SchedulingID:  op.SchedulingID,
 			CorrelationID: op.CorrelationID,
 			ConfigVersion: op.ConfigVersion,
@@ -197,7 +236,8 @@ func (or *PersistedOperationsRegistry) updateState(correlationID, schedulingID,
 		if err != nil {
 			return nil, err
 		}
-		return nil, nil
+		return nil, auditOperation(txRegistry.Conn, txRegistry.Actor, model.AuditActionUpdate, op,
+			stateChange(op.State, state, reason))
 	}
 	_, err := db.TransactionResult(or.Conn, dbOps, or.Logger)
 	if err != nil {
@@ -206,14 +246,48 @@ func (or *PersistedOperationsRegistry) updateState(correlationID, schedulingID,
 	return nil
 }
 
+//stateChange describes a state change of an operation in the audit log
+func stateChange(oldState model.OperationState, newState, reason string) string {
+	if reason == "" {
+		return fmt.Sprintf("%s -> %s", oldState, newState)
+	}
+	return fmt.Sprintf("%s -> %s (%s)", oldState, newState, reason)
+}
+
 type InMemoryOperationsRegistry struct {
+	*inMemoryOperations
+	actor     string
+	auditConn db.Connection //without connection the audit entries are only exported to the audit sinks
+}
+
+//inMemoryOperations is shared by all copies of an in-memory registry (see WithActor)
+type inMemoryOperations struct {
 	registry map[string]map[string]model.OperationEntity
 	mu       sync.Mutex
 }
 
 func NewInMemoryOperationsRegistry() *InMemoryOperationsRegistry {
 	return &InMemoryOperationsRegistry{
-		registry: make(map[string]map[string]model.OperationEntity),
+		inMemoryOperations: &inMemoryOperations{
+			registry: make(map[string]map[string]model.OperationEntity),
+		},
+	}
+}
+
+//WithAuditConnection returns a copy of the registry which appends its audit entries to the audit log in the database
+func (or *InMemoryOperationsRegistry) WithAuditConnection(conn db.Connection) *InMemoryOperationsRegistry {
+	return &InMemoryOperationsRegistry{
+		inMemoryOperations: or.inMemoryOperations,
+		actor:              or.actor,
+		auditConn:          conn,
+	}
+}
+
+func (or *InMemoryOperationsRegistry) WithActor(actor string) OperationsRegistry {
+	return &InMemoryOperationsRegistry{
+		inMemoryOperations: or.inMemoryOperations,
+		actor:              actor,
+		auditConn:          or.auditConn,
 	}
 }
 
@@ -258,6 +332,9 @@ func (or *InMemoryOperationsRegistry) RegisterOperation(correlationID, schedulin
 		Created:       time.Now(),
 		Updated:       time.Now(),
 	}
+	if err := auditOperation(or.auditConn, or.actor, model.AuditActionCreate, &op, ""); err != nil {
+		return nil, err
+	}
 	or.registry[schedulingID][correlationID] = op
 	return &op, nil
 }
@@ -285,10 +362,13 @@ func (or *InMemoryOperationsRegistry) RemoveOperation(correlationID, schedulingI
 	if !ok {
 		return newOperationNotFoundError(schedulingID, correlationID)
 	}
-	_, ok = operations[correlationID]
+	op, ok := operations[correlationID]
 	if !ok {
 		return newOperationNotFoundError(schedulingID, correlationID)
 	}
+	if err := auditOperation(or.auditConn, or.actor, model.AuditActionDelete, &op, ""); err != nil {
+		return err
+	}
 	delete(or.registry[schedulingID], correlationID)
 	return nil
 }
@@ -313,6 +393,10 @@ func (or *InMemoryOperationsRegistry) SetFailed(correlationID, schedulingID, rea
 	return or.update(correlationID, schedulingID, model.OperationStateFailed, reason)
 }
 
+func (or *InMemoryOperationsRegistry) SetSuperseded(correlationID, schedulingID, reason string) error {
+	return or.update(correlationID, schedulingID, model.OperationStateSuperseded, reason)
+}
+
 func (or *InMemoryOperationsRegistry) update(correlationID, schedulingID, state, reason string) error {
 	or.mu.Lock()
 	defer or.mu.Unlock()
@@ -325,6 +409,9 @@ func (or *InMemoryOperationsRegistry) update(correlationID, schedulingID, state,
 	if !ok {
 		return newOperationNotFoundError(schedulingID, correlationID)
 	}
+	if err := auditOperation(or.auditConn, or.actor, model.AuditActionUpdate, &op, stateChange(op.State, state, reason)); err != nil {
+		return err
+	}
 
 	or.registry[schedulingID][correlationID] = model.OperationEntity{
 		CorrelationID: correlationID,
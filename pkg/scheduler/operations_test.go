@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/kyma-incubator/reconciler/pkg/audit"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+type operationsAuditSink struct {
+	entries []*model.AuditEntity
+}
+
+func (s *operationsAuditSink) Export(entry *model.AuditEntity) {
+	if entry.EntityType == model.AuditEntityOperation {
+		s.entries = append(s.entries, entry)
+	}
+}
+
+func TestInMemoryOperationsRegistryAudit(t *testing.T) {
+	sink := &operationsAuditSink{}
+	audit.RegisterSink(sink)
+	defer audit.UnregisterSink(sink)
+
+	registry := NewInMemoryOperationsRegistry()
+	_, err := registry.RegisterOperation("correlation", "scheduling", "comp", 3)
+	require.NoError(t, err)
+
+	//writes of a registry copy are recorded in the name of its actor but change the shared operations
+	require.NoError(t, registry.WithActor("comp-reconciler").SetError("correlation", "scheduling", "failed"))
+	op, err := registry.GetOperation("correlation", "scheduling")
+	require.NoError(t, err)
+	require.Equal(t, model.OperationState(model.OperationStateError), op.State)
+
+	require.NoError(t, registry.RemoveOperation("correlation", "scheduling"))
+
+	require.Len(t, sink.entries, 3)
+	require.Equal(t, audit.SystemActor, sink.entries[0].Actor)
+	require.Equal(t, model.AuditActionCreate, sink.entries[0].Action)
+	require.Equal(t, "scheduling/correlation", sink.entries[0].Target)
+	require.Equal(t, int64(3), sink.entries[0].VersionAfter)
+
+	require.Equal(t, "comp-reconciler", sink.entries[1].Actor)
+	require.Equal(t, model.AuditActionUpdate, sink.entries[1].Action)
+	require.Equal(t, "new -> error (failed)", sink.entries[1].Details)
+
+	require.Equal(t, model.AuditActionDelete, sink.entries[2].Action)
+	require.Equal(t, int64(3), sink.entries[2].VersionBefore)
+	require.Equal(t, int64(0), sink.entries[2].VersionAfter)
+}
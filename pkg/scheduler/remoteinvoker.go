@@ -27,7 +27,9 @@ func (rri *RemoteReconcilerInvoker) Invoke(params *InvokeParams) error {
 		Namespace:       params.ComponentToReconcile.Namespace,
 		Version:         params.ClusterState.Configuration.KymaVersion,
 		Profile:         params.ClusterState.Configuration.KymaProfile,
-		Configuration:   mapConfiguration(params.ComponentToReconcile.Configuration),
+		Configuration:   params.Configuration,
+		PostRender:      params.PostRender,
+		Values:          params.Values,
 		Kubeconfig:      params.ClusterState.Cluster.Kubeconfig,
 		CallbackURL:     fmt.Sprintf("%s://%s:%d/v1/operations/%s/callback/%s", rri.mothershipScheme, rri.mothershipHost, rri.mothershipPort, params.SchedulingID, params.CorrelationID),
 		InstallCRD:      params.InstallCRD,
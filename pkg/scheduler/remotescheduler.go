@@ -2,6 +2,8 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	"github.com/google/uuid"
 	"github.com/kyma-incubator/reconciler/pkg/cluster"
@@ -30,12 +32,23 @@ type Scheduler interface {
 type RemoteScheduler struct {
 	inventoryWatch InventoryWatcher
 	workerFactory  WorkerFactory
+	operationsReg  OperationsRegistry
 	mothershipCfg  MothershipReconcilerConfig
 	poolSize       int
 	logger         *zap.SugaredLogger
 }
 
-func NewRemoteScheduler(inventoryWatch InventoryWatcher, workerFactory WorkerFactory, mothershipCfg MothershipReconcilerConfig, workers int, debug bool) (Scheduler, error) {
+//schedulingRun is a reconciliation of one cluster configuration version
+type schedulingRun struct {
+	state         cluster.State
+	schedulingID  string
+	statusUpdater *ClusterStatusUpdater
+	superseded    bool
+	supersededBy  int64 //configuration version which superseded the run
+	mu            sync.Mutex
+}
+
+func NewRemoteScheduler(inventoryWatch InventoryWatcher, workerFactory WorkerFactory, operationsReg OperationsRegistry, mothershipCfg MothershipReconcilerConfig, workers int, debug bool) (Scheduler, error) {
 	l, err := logger.NewLogger(debug)
 	if err != nil {
 		return nil, err
@@ -43,6 +56,7 @@ func NewRemoteScheduler(inventoryWatch InventoryWatcher, workerFactory WorkerFac
 	return &RemoteScheduler{
 		inventoryWatch: inventoryWatch,
 		workerFactory:  workerFactory,
+		operationsReg:  operationsReg,
 		mothershipCfg:  mothershipCfg,
 		poolSize:       workers,
 		logger:         l,
@@ -111,31 +125,54 @@ func (rs *RemoteScheduler) schedule(ctx context.Context, state cluster.State) {
 	statusUpdater := NewClusterStatusUpdater(rs.inventoryWatch.Inventory(), state, components, rs.logger)
 	go statusUpdater.Run(ctx)
 
-	//Reconcile CRD components first
-	for _, component := range components {
-		if rs.isCRDComponent(component.Component) {
-			rs.reconcile(component, state, schedulingID, doInstallCRD, concurrencyNotAllowed, statusUpdater)
-		}
+	run := &schedulingRun{
+		state:         state,
+		schedulingID:  schedulingID,
+		statusUpdater: &statusUpdater,
 	}
 
-	//Reconcile pre components
+	var crdComponents, preComponents, otherComponents []*keb.Components
 	for _, component := range components {
-		if rs.isPreComponent(component.Component) {
-			rs.reconcile(component, state, schedulingID, doNotInstallCRD, concurrencyNotAllowed, statusUpdater)
+		switch {
+		case rs.isCRDComponent(component.Component):
+			crdComponents = append(crdComponents, component)
+		case rs.isPreComponent(component.Component):
+			preComponents = append(preComponents, component)
+		default:
+			otherComponents = append(otherComponents, component)
 		}
 	}
 
+	//Reconcile CRD components first
+	rs.reconcileRound(crdComponents, run, doInstallCRD, concurrencyNotAllowed)
+
+	//Reconcile pre components
+	rs.reconcileRound(preComponents, run, doNotInstallCRD, concurrencyNotAllowed)
+
 	//Reconcile the rest
+	rs.reconcileRound(otherComponents, run, doNotInstallCRD, concurrencyAllowed)
+}
+
+//reconcileRound dispatches the components of one round: whether the run was superseded is checked once per round
+func (rs *RemoteScheduler) reconcileRound(components []*keb.Components, run *schedulingRun, installCRD bool, concurrent concurrency) {
+	if len(components) == 0 {
+		return
+	}
+	rs.checkSuperseded(run)
 	for _, component := range components {
-		if rs.isPreComponent(component.Component) || rs.isCRDComponent(component.Component) {
-			continue
-		}
-		rs.reconcile(component, state, schedulingID, doNotInstallCRD, concurrencyAllowed, statusUpdater)
+		rs.reconcile(component, run, installCRD, concurrent)
 	}
 }
 
-func (rs *RemoteScheduler) reconcile(component *keb.Components, state cluster.State, schedulingID string, installCRD bool, concurrent concurrency, statusUpdater ClusterStatusUpdater) {
+func (rs *RemoteScheduler) reconcile(component *keb.Components, run *schedulingRun, installCRD bool, concurrent concurrency) {
 	fn := func(component *keb.Components, state cluster.State, schedulingID string) {
+		if run.isSuperseded() {
+			rs.logger.Debugf("Skipping reconciliation of component %s: configuration version %d of cluster %s was superseded",
+				component.Component, state.Configuration.Version, state.Cluster.Cluster)
+			rs.recordSuperseded(component, run)
+			run.statusUpdater.Update(component.Component, model.OperationStateSuperseded)
+			return
+		}
 		worker, err := rs.workerFactory.ForComponent(component.Component)
 		if err != nil {
 			rs.logger.Errorf("Error creating worker for component: %s", err)
@@ -144,16 +181,71 @@ func (rs *RemoteScheduler) reconcile(component *keb.Components, state cluster.St
 		err = worker.Reconcile(component, state, schedulingID, installCRD)
 		if err != nil {
 			rs.logger.Errorf("Error while reconciling component %s: %s", component.Component, err)
-			statusUpdater.Update(component.Component, model.OperationStateError)
+			run.statusUpdater.Update(component.Component, model.OperationStateError)
 			return
 		}
-		statusUpdater.Update(component.Component, model.OperationStateDone)
+		run.statusUpdater.Update(component.Component, model.OperationStateDone)
 	}
 
 	if bool(concurrent) {
-		go fn(component, state, schedulingID)
+		go fn(component, run.state, run.schedulingID)
 	} else {
-		fn(component, state, schedulingID)
+		fn(component, run.state, run.schedulingID)
+	}
+}
+
+func (run *schedulingRun) isSuperseded() bool {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	return run.superseded
+}
+
+//checkSuperseded checks whether KEB pushed a newer configuration of the cluster since the run was started:
+//all remaining components of the stale run are skipped. The newer configuration is scheduled by the inventory
+//watcher (its status is ReconcilePending).
+func (rs *RemoteScheduler) checkSuperseded(run *schedulingRun) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if run.superseded {
+		return
+	}
+
+	latestState, err := rs.inventoryWatch.Inventory().GetLatest(run.state.Cluster.Cluster)
+	if err != nil {
+		rs.logger.Warnf("Failed to retrieve latest configuration of cluster %s: continuing reconciliation of version %d: %s",
+			run.state.Cluster.Cluster, run.state.Configuration.Version, err)
+		return
+	}
+	if latestState.Configuration.Version <= run.state.Configuration.Version {
+		return
+	}
+
+	run.superseded = true
+	run.supersededBy = latestState.Configuration.Version
+	rs.logger.Infof("Configuration version %d of cluster %s was superseded by version %d: "+
+		"aborting reconciliation %s", run.state.Configuration.Version,
+		run.state.Cluster.Cluster, latestState.Configuration.Version, run.schedulingID)
+}
+
+//recordSuperseded registers the skipped component as operation in state 'superseded'
+func (rs *RemoteScheduler) recordSuperseded(component *keb.Components, run *schedulingRun) {
+	if rs.operationsReg == nil {
+		return
+	}
+	run.mu.Lock()
+	supersededBy := run.supersededBy
+	run.mu.Unlock()
+
+	correlationID := uuid.NewString()
+	if _, err := rs.operationsReg.RegisterOperation(correlationID, run.schedulingID, component.Component,
+		run.state.Configuration.Version); err != nil {
+		rs.logger.Errorf("Failed to register superseded operation of component %s: %s", component.Component, err)
+		return
+	}
+	err := rs.operationsReg.SetSuperseded(correlationID, run.schedulingID,
+		fmt.Sprintf("superseded by configuration version %d", supersededBy))
+	if err != nil {
+		rs.logger.Errorf("Failed to update state of operation %s to superseded: %s", correlationID, err)
 	}
 }
 
@@ -3,6 +3,8 @@ package scheduler
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -68,3 +70,105 @@ func TestRemoteScheduler(t *testing.T) {
 	workerFactoryMock.AssertNumberOfCalls(t, "ForComponent", 2)
 	workerMock.AssertNumberOfCalls(t, "Reconcile", 2)
 }
+
+func TestRemoteSchedulerSupersededConfiguration(t *testing.T) {
+	components := []keb.Components{
+		{Component: "logging"},
+		{Component: "monitoring"},
+	}
+	componentsJSON, _ := json.Marshal(components)
+
+	newState := func(version int64) cluster.State {
+		return cluster.State{
+			Cluster: &model.ClusterEntity{Cluster: "superseded"},
+			Configuration: &model.ClusterConfigurationEntity{
+				Cluster:    "superseded",
+				Version:    version,
+				Contract:   1,
+				Components: string(componentsJSON),
+			},
+			Status: &model.ClusterStatusEntity{
+				Status:        model.ClusterStatusReconcilePending,
+				ConfigVersion: version,
+			},
+		}
+	}
+	staleState := newState(1)
+	latestState := newState(2)
+
+	inventory := &cluster.MockInventory{}
+	inventory.GetLatestResult = &staleState
+	pushed := make(chan struct{})
+	inventoryWatchStub := &MockInventoryWatcher{}
+	inventoryWatchStub.On("Inventory").Return(inventory)
+	inventoryWatchStub.On("Run", mock.Anything, mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			queue := args.Get(1).(InventoryQueue)
+			queue <- staleState
+			//the watcher picks up the pending latest configuration (the scheduler doesn't enqueue it)
+			select {
+			case <-pushed:
+				queue <- latestState
+			case <-args.Get(0).(context.Context).Done():
+			}
+		})
+
+	var reconciledVersions []string
+	var mu sync.Mutex
+	workerMock := &MockReconciliationWorker{}
+	workerMock.On("Reconcile", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			component := args.Get(0).(*keb.Components)
+			state := args.Get(1).(cluster.State)
+			mu.Lock()
+			defer mu.Unlock()
+			reconciledVersions = append(reconciledVersions, fmt.Sprintf("%s:%d", component.Component, state.Configuration.Version))
+			if state.Configuration.Version == staleState.Configuration.Version {
+				//KEB pushes a new configuration while the stale one gets reconciled
+				inventory.GetLatestResult = &latestState
+				close(pushed)
+			}
+		})
+
+	workerFactoryMock := &MockWorkerFactory{}
+	workerFactoryMock.On("ForComponent", mock.Anything).Return(workerMock, nil)
+
+	operationsReg := NewInMemoryOperationsRegistry()
+
+	l, _ := logger.NewLogger(true)
+	sut := RemoteScheduler{
+		inventoryWatch: inventoryWatchStub,
+		workerFactory:  workerFactoryMock,
+		operationsReg:  operationsReg,
+		mothershipCfg:  MothershipReconcilerConfig{PreComponents: []string{"logging"}},
+		poolSize:       2,
+		logger:         l,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, sut.Run(ctx))
+
+	//stale run was aborted after the first component and the latest configuration got reconciled
+	mu.Lock()
+	defer mu.Unlock()
+	require.ElementsMatch(t, []string{"logging:1", "logging:2", "monitoring:2"}, reconciledVersions)
+
+	//skipped component of the stale run is recorded as superseded operation
+	var superseded []model.OperationEntity
+	operationsReg.mu.Lock()
+	defer operationsReg.mu.Unlock()
+	for _, operations := range operationsReg.registry {
+		for _, op := range operations {
+			if op.State == model.OperationStateSuperseded {
+				superseded = append(superseded, op)
+			}
+		}
+	}
+	require.Len(t, superseded, 1)
+	require.Equal(t, "monitoring", superseded[0].Component)
+	require.Equal(t, int64(1), superseded[0].ConfigVersion)
+	require.Equal(t, "superseded by configuration version 2", superseded[0].Reason)
+}
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/cluster"
+	"github.com/kyma-incubator/reconciler/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const defaultRetentionInterval = 1 * time.Hour
+
+type RetentionConfig struct {
+	Interval time.Duration
+	Policy   cluster.RetentionPolicy
+}
+
+func (rc *RetentionConfig) validate() error {
+	if rc.Interval < 0 {
+		return errors.New("retention interval cannot be < 0")
+	}
+	if rc.Interval == 0 {
+		rc.Interval = defaultRetentionInterval
+	}
+	return rc.Policy.Validate()
+}
+
+type purgeObserver interface {
+	OnPurge(result *cluster.PurgeResult)
+}
+
+//RetentionJob periodically removes historical entities from the inventory
+type RetentionJob struct {
+	inventory cluster.Inventory
+	config    *RetentionConfig
+	observer  purgeObserver
+	logger    *zap.SugaredLogger
+}
+
+func NewRetentionJob(inventory cluster.Inventory, observer purgeObserver, debug bool, config *RetentionConfig) (*RetentionJob, error) {
+	l, err := logger.NewLogger(debug)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	return &RetentionJob{
+		inventory: inventory,
+		config:    config,
+		observer:  observer,
+		logger:    l}, nil
+}
+
+func (j *RetentionJob) Run(ctx context.Context) error {
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+	j.logger.Debugf("Start retention job with an interval of %.1f secs", j.config.Interval.Seconds())
+	j.purge()
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Debug("Stopping retention job because parent context got closed")
+			return nil
+		case <-ticker.C:
+			j.purge()
+		}
+	}
+}
+
+func (j *RetentionJob) purge() {
+	result, err := j.inventory.Purge(&j.config.Policy)
+	if err != nil {
+		j.logger.Errorf("Error while purging the inventory: %s", err)
+		return
+	}
+	j.logger.Debugf("Retention job purged inventory: %s", result)
+	if j.observer != nil {
+		j.observer.OnPurge(result)
+	}
+}
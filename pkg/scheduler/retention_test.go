@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/cluster"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePurgeObserver struct {
+	results chan *cluster.PurgeResult
+}
+
+func (o *fakePurgeObserver) OnPurge(result *cluster.PurgeResult) {
+	o.results <- result
+}
+
+func TestRetentionJob(t *testing.T) {
+	t.Run("Invalid config", func(t *testing.T) {
+		_, err := NewRetentionJob(&cluster.MockInventory{}, nil, true, &RetentionConfig{Interval: -1 * time.Second})
+		require.Error(t, err)
+		_, err = NewRetentionJob(&cluster.MockInventory{}, nil, true, &RetentionConfig{
+			Policy: cluster.RetentionPolicy{KeepConfigs: -1},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("Purge periodically", func(t *testing.T) {
+		expected := &cluster.PurgeResult{Statuses: 3}
+		inventory := &cluster.MockInventory{PurgeResult: expected}
+		observer := &fakePurgeObserver{results: make(chan *cluster.PurgeResult, 3)}
+		ctx, cancelFn := context.WithTimeout(context.TODO(), 1200*time.Millisecond)
+		defer cancelFn()
+
+		retentionJob, err := NewRetentionJob(inventory, observer, true, &RetentionConfig{Interval: 500 * time.Millisecond})
+		require.NoError(t, err)
+
+		startTime := time.Now()
+		require.NoError(t, retentionJob.Run(ctx))
+		require.WithinDuration(t, startTime, time.Now(), 2*time.Second)
+
+		//purged on startup and after each interval
+		require.Len(t, observer.results, 3)
+		require.Equal(t, expected, <-observer.results)
+	})
+}
@@ -2,7 +2,6 @@ package scheduler
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/kyma-incubator/reconciler/pkg/cluster"
@@ -70,48 +69,46 @@ func (su *ClusterStatusUpdater) Update(component string, operationState string)
 }
 
 func (su *ClusterStatusUpdater) reconciling() {
-	if err := su.statusChangeAllowed(model.ClusterStatusReconciling); err != nil {
-		su.logger.Warn(err)
-		return
-	}
 	su.sendUpdate(model.ClusterStatusReconciling)
 }
 
 func (su *ClusterStatusUpdater) success() {
-	if err := su.statusChangeAllowed(model.ClusterStatusReady); err != nil {
-		su.logger.Warn(err)
-		return
-	}
 	if su.isAllDone() {
 		su.sendUpdate(model.ClusterStatusReady)
 	}
 }
 
 func (su *ClusterStatusUpdater) error() {
-	if err := su.statusChangeAllowed(model.ClusterStatusError); err != nil {
-		su.logger.Warn(err)
-		return
-	}
 	su.sendUpdate(model.ClusterStatusError)
 }
 
 func (su *ClusterStatusUpdater) sendUpdate(status model.Status) {
-	_, err := su.inventory.UpdateStatus(&su.clusterState, status)
-	if err != nil {
-		su.logger.Infof("Failed to update cluster status as ready: %s", err)
+	err := su.updateStatus(status)
+	if cluster.IsStatusConflictError(err) {
+		//status was changed concurrently: retry based on the latest status if it belongs to the same configuration
+		latestStatus := err.(*cluster.StatusConflictError).LatestStatus
+		if latestStatus.ConfigVersion != su.clusterState.Configuration.Version {
+			su.logger.Warnf("Cluster status of '%s' not updated to '%s': configuration version %d was superseded by version %d",
+				su.clusterState.Cluster.Cluster, status, su.clusterState.Configuration.Version, latestStatus.ConfigVersion)
+			return
+		}
+		su.clusterState.Status = latestStatus
+		err = su.updateStatus(status)
 	}
-}
-
-func (su *ClusterStatusUpdater) statusChangeAllowed(status model.Status) error {
-	latestState, err := su.inventory.GetLatest(su.clusterState.Cluster.Cluster)
-	if err != nil {
-		return fmt.Errorf("failed to get the latest cluster status: %s", err)
+	if model.IsInvalidStatusTransitionError(err) {
+		su.logger.Warn(err)
+	} else if err != nil {
+		su.logger.Infof("Failed to update cluster status to '%s': %s", status, err)
 	}
+}
 
-	if latestState.Status.Status == model.ClusterStatusError || latestState.Status.Status == model.ClusterStatusReady {
-		return fmt.Errorf("cannot switch in '%s' status because we are already in final status '%s'", status, latestState.Status.Status)
+//updateStatus changes the cluster status (the inventory verifies that the state machine allows the transition)
+func (su *ClusterStatusUpdater) updateStatus(status model.Status) error {
+	state, err := su.inventory.UpdateStatus(su.clusterState.Status.ID, status)
+	if err == nil && state != nil {
+		su.clusterState.Status = state.Status
 	}
-	return nil
+	return err
 }
 
 func (su *ClusterStatusUpdater) isAllDone() bool {
@@ -125,7 +122,7 @@ func (su *ClusterStatusUpdater) isAllDone() bool {
 
 func (su *ClusterStatusUpdater) isAllInEndState() bool {
 	for _, state := range su.statusMap {
-		if state != model.OperationStateDone && state != model.OperationStateError {
+		if state != model.OperationStateDone && state != model.OperationStateError && state != model.OperationStateSuperseded {
 			return false
 		}
 	}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/kyma-incubator/reconciler/pkg/cluster"
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/keb"
+	"github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+type noopMetricsCollector struct{}
+
+func (c noopMetricsCollector) OnClusterStateUpdate(state *cluster.State) error {
+	return nil
+}
+
+func TestClusterStatusUpdater(t *testing.T) {
+	connFact, err := db.NewTestConnectionFactory()
+	require.NoError(t, err)
+	inventory, err := cluster.NewInventory(connFact, true, noopMetricsCollector{})
+	require.NoError(t, err)
+
+	newStatusUpdater := func(t *testing.T, kebCluster *keb.Cluster) *ClusterStatusUpdater {
+		state, err := inventory.CreateOrUpdate(1, kebCluster)
+		require.NoError(t, err)
+		statusUpdater := NewClusterStatusUpdater(inventory, *state, []*keb.Components{{Component: "comp"}}, logger.NewOptionalLogger(true))
+		return &statusUpdater
+	}
+
+	requireLatestStatus := func(t *testing.T, kebCluster *keb.Cluster, configVersion int64, status model.Status) {
+		state, err := inventory.GetLatest(kebCluster.Cluster)
+		require.NoError(t, err)
+		require.Equal(t, configVersion, state.Configuration.Version)
+		require.Equal(t, status, state.Status.Status)
+	}
+
+	t.Run("Update status", func(t *testing.T) {
+		kebCluster := newStatusUpdaterCluster("statusupdater1", "1.0.0")
+		defer func() {
+			require.NoError(t, inventory.Delete(kebCluster.Cluster))
+		}()
+
+		statusUpdater := newStatusUpdater(t, kebCluster)
+		configVersion := statusUpdater.clusterState.Configuration.Version
+		requireLatestStatus(t, kebCluster, configVersion, model.ClusterStatusReconciling)
+
+		statusUpdater.statusMap["comp"] = model.OperationStateDone
+		statusUpdater.success()
+		requireLatestStatus(t, kebCluster, configVersion, model.ClusterStatusReady)
+
+		//final status is kept
+		statusUpdater.error()
+		requireLatestStatus(t, kebCluster, configVersion, model.ClusterStatusReady)
+	})
+
+	t.Run("Retry after concurrent status change", func(t *testing.T) {
+		kebCluster := newStatusUpdaterCluster("statusupdater2", "1.0.0")
+		defer func() {
+			require.NoError(t, inventory.Delete(kebCluster.Cluster))
+		}()
+
+		statusUpdater := newStatusUpdater(t, kebCluster)
+		configVersion := statusUpdater.clusterState.Configuration.Version
+
+		//status is changed by somebody else
+		state, err := inventory.GetLatest(kebCluster.Cluster)
+		require.NoError(t, err)
+		_, err = inventory.UpdateStatus(state.Status.ID, model.ClusterStatusReconciling)
+		require.NoError(t, err)
+
+		statusUpdater.error()
+		requireLatestStatus(t, kebCluster, configVersion, model.ClusterStatusError)
+	})
+
+	t.Run("Ignore status of superseded configuration", func(t *testing.T) {
+		kebCluster := newStatusUpdaterCluster("statusupdater3", "1.0.0")
+		defer func() {
+			require.NoError(t, inventory.Delete(kebCluster.Cluster))
+		}()
+
+		statusUpdater := newStatusUpdater(t, kebCluster)
+
+		//KEB pushes a new configuration
+		newState, err := inventory.CreateOrUpdate(1, newStatusUpdaterCluster(kebCluster.Cluster, "2.0.0"))
+		require.NoError(t, err)
+
+		statusUpdater.statusMap["comp"] = model.OperationStateDone
+		statusUpdater.success()
+		requireLatestStatus(t, kebCluster, newState.Configuration.Version, model.ClusterStatusReconcilePending)
+	})
+}
+
+func newStatusUpdaterCluster(name, version string) *keb.Cluster {
+	return &keb.Cluster{
+		Cluster:    name,
+		Kubeconfig: "fake kubeconfig",
+		KymaConfig: keb.KymaConfig{
+			Version:    version,
+			Profile:    "evaluation",
+			Components: []keb.Components{{Component: "comp", Namespace: "kyma-system"}},
+		},
+	}
+}
@@ -24,13 +24,14 @@ type ReconciliationWorker interface {
 }
 
 type Worker struct {
-	correlationID string
-	config        *ComponentReconciler
-	inventory     cluster.Inventory
-	operationsReg OperationsRegistry
-	invoker       ReconcilerInvoker
-	logger        *zap.SugaredLogger
-	errorsCount   int
+	correlationID  string
+	config         *ComponentReconciler
+	inventory      cluster.Inventory
+	operationsReg  OperationsRegistry
+	invoker        ReconcilerInvoker
+	configResolver ConfigurationResolver
+	logger         *zap.SugaredLogger
+	errorsCount    int
 }
 
 func NewWorker(
@@ -38,19 +39,21 @@ func NewWorker(
 	inventory cluster.Inventory,
 	operationsReg OperationsRegistry,
 	invoker ReconcilerInvoker,
+	configResolver ConfigurationResolver,
 	debug bool) (*Worker, error) {
 	log, err := logger.NewLogger(debug)
 	if err != nil {
 		return nil, err
 	}
 	return &Worker{
-		correlationID: uuid.NewString(),
-		config:        config,
-		inventory:     inventory,
-		operationsReg: operationsReg,
-		invoker:       invoker,
-		logger:        log,
-		errorsCount:   0,
+		correlationID:  uuid.NewString(),
+		config:         config,
+		inventory:      inventory,
+		operationsReg:  operationsReg,
+		invoker:        invoker,
+		configResolver: configResolver,
+		logger:         log,
+		errorsCount:    0,
 	}, nil
 }
 
@@ -129,10 +132,25 @@ func (w *Worker) process(component *keb.Components, state cluster.State, schedul
 
 func (w *Worker) callReconciler(component *keb.Components, state cluster.State, schedulingID string, installCRD bool) error {
 	var componentsReady []string
+	var configuration []reconciler.Configuration
+	var postRenders []reconciler.PostRender
+	var values []string
 	var err error
 	if componentsReady, err = w.getDoneComponents(schedulingID); err == nil {
+		configuration, err = w.configuration(component, state)
+	}
+	if err == nil {
+		postRenders, err = w.postRender(component, state)
+	}
+	if err == nil {
+		values, err = w.values(component, state)
+	}
+	if err == nil {
 		err = w.invoker.Invoke(&InvokeParams{
 			ComponentToReconcile: component,
+			Configuration:        configuration,
+			PostRender:           postRenders,
+			Values:               values,
 			ComponentsReady:      componentsReady,
 			ClusterState:         state,
 			SchedulingID:         schedulingID,
@@ -152,6 +170,27 @@ func (w *Worker) callReconciler(component *keb.Components, state cluster.State,
 	return nil
 }
 
+func (w *Worker) configuration(component *keb.Components, state cluster.State) ([]reconciler.Configuration, error) {
+	if w.configResolver == nil {
+		return mapConfiguration(component.Configuration), nil
+	}
+	return w.configResolver.Configuration(component, &state)
+}
+
+func (w *Worker) postRender(component *keb.Components, state cluster.State) ([]reconciler.PostRender, error) {
+	if w.configResolver == nil {
+		return nil, nil
+	}
+	return w.configResolver.PostRender(component, &state)
+}
+
+func (w *Worker) values(component *keb.Components, state cluster.State) ([]string, error) {
+	if w.configResolver == nil {
+		return nil, nil
+	}
+	return w.configResolver.Values(component, &state)
+}
+
 func (w *Worker) getDoneComponents(schedulingID string) ([]string, error) {
 	operations, err := w.operationsReg.GetDoneOperations(schedulingID)
 	if err != nil {
@@ -165,11 +204,12 @@ func (w *Worker) getDoneComponents(schedulingID string) ([]string, error) {
 }
 
 func mapConfiguration(kebCfg []keb.Configuration) []reconciler.Configuration {
-	reconcilerCfg := make([]reconciler.Configuration, len(kebCfg))
+	reconcilerCfg := make([]reconciler.Configuration, 0, len(kebCfg))
 	for _, k := range kebCfg {
 		reconcilerCfg = append(reconcilerCfg, reconciler.Configuration{
-			Key:   k.Key,
-			Value: k.Value,
+			Key:    k.Key,
+			Value:  k.Value,
+			Secret: k.Secret,
 		})
 	}
 	return reconcilerCfg
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/reconciler/pkg/cache"
+	"github.com/kyma-incubator/reconciler/pkg/cluster"
+	"github.com/kyma-incubator/reconciler/pkg/db"
+	"github.com/kyma-incubator/reconciler/pkg/keb"
+	"github.com/kyma-incubator/reconciler/pkg/kv"
+	"github.com/kyma-incubator/reconciler/pkg/logger"
+	"github.com/kyma-incubator/reconciler/pkg/model"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler"
+	"github.com/kyma-incubator/reconciler/pkg/reconciler/chart"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerValues(t *testing.T) {
+	connFact, err := db.NewTestConnectionFactory()
+	require.NoError(t, err)
+	kvRepo, err := kv.NewRepository(connFact, true)
+	require.NoError(t, err)
+	cacheRepo, err := cache.NewRepository(connFact, true)
+	require.NoError(t, err)
+
+	ts := time.Now().UnixNano()
+	component := &keb.Components{Component: fmt.Sprintf("component%d", ts), Namespace: "kyma-system"}
+	state := cluster.State{
+		Cluster: &model.ClusterEntity{
+			Cluster:    fmt.Sprintf("values-%d", ts),
+			Metadata:   `{"globalAccountID":"ga"}`,
+			Runtime:    `{"name":"runtime"}`,
+			Kubeconfig: "kubeconfig",
+			Contract:   1,
+		},
+		Configuration: &model.ClusterConfigurationEntity{KymaVersion: "1.0.0", KymaProfile: "evaluation"},
+	}
+	defaultBucket := fmt.Sprintf("default-%d", ts)
+	clusterBucket := state.Cluster.Cluster
+
+	//values documents of the component are defined in the default and the cluster bucket
+	valuesKey := cluster.ValuesKeyPrefix + component.Component
+	keyEntity, err := kvRepo.CreateKey(&model.KeyEntity{Key: valuesKey, DataType: model.YAML, Username: "test"})
+	require.NoError(t, err)
+	for bucket, valuesDoc := range map[string]string{
+		defaultBucket: "replicas: 1\nimage:\n  tag: default\n  pullPolicy: Always",
+		clusterBucket: "image:\n  tag: cluster",
+	} {
+		_, err := kvRepo.CreateValue(&model.ValueEntity{
+			Key: valuesKey, KeyVersion: keyEntity.Version, Bucket: bucket, Value: valuesDoc, Username: "test",
+		})
+		require.NoError(t, err)
+	}
+	defer func() {
+		for _, bucket := range []string{defaultBucket, clusterBucket} {
+			require.NoError(t, kvRepo.DeleteBucket(bucket))
+		}
+		require.NoError(t, kvRepo.DeleteKey(valuesKey))
+		require.NoError(t, cacheRepo.Invalidate(component.Component, state.Cluster.Cluster))
+	}()
+
+	configManager, err := cluster.NewConfigurationManager(kvRepo, cacheRepo,
+		[]string{defaultBucket, cluster.BucketPlaceholderCluster}, true)
+	require.NoError(t, err)
+
+	//component reconciler receives the values documents in the reconciliation request
+	var received *reconciler.Reconciliation
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = &reconciler.Reconciliation{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	operationsReg := NewInMemoryOperationsRegistry()
+	worker, err := NewWorker(&ComponentReconciler{URL: server.URL}, nil, operationsReg,
+		&RemoteReconcilerInvoker{logger: logger.NewOptionalLogger(true)}, configManager, true)
+	require.NoError(t, err)
+	_, err = operationsReg.RegisterOperation(worker.correlationID, "schedulingID", component.Component, 1)
+	require.NoError(t, err)
+	require.NoError(t, worker.callReconciler(component, state, "schedulingID", false))
+
+	require.NotNil(t, received)
+	require.Len(t, received.Values, 2)
+	for _, cfg := range received.Configuration {
+		require.NotEqual(t, valuesKey, cfg.Key) //values documents are not part of the configuration
+	}
+
+	//documents are merged in bucket order
+	values, err := chart.NewComponentBuilder(received.Version, received.Component).
+		WithValues(received.Values...).
+		Build().
+		Values()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"replicas": float64(1),
+		"image": map[string]interface{}{
+			"tag":        "cluster",
+			"pullPolicy": "Always",
+		},
+	}, values)
+}
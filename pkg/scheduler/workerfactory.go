@@ -11,11 +11,12 @@ type WorkerFactory interface {
 }
 
 type baseWorkerFactory struct {
-	inventory     cluster.Inventory
-	operationsReg OperationsRegistry
-	invoker       ReconcilerInvoker
-	logger        *zap.SugaredLogger
-	debug         bool
+	inventory      cluster.Inventory
+	operationsReg  OperationsRegistry
+	invoker        ReconcilerInvoker
+	configResolver ConfigurationResolver
+	logger         *zap.SugaredLogger
+	debug          bool
 }
 
 type remoteWorkerFactory struct {
@@ -29,6 +30,7 @@ func NewRemoteWorkerFactory(
 	reconcilersCfg ComponentReconcilersConfig,
 	mothershipCfg MothershipReconcilerConfig,
 	operationsReg OperationsRegistry,
+	configResolver ConfigurationResolver,
 	debug bool) (WorkerFactory, error) {
 
 	log, err := logger.NewLogger(debug)
@@ -46,8 +48,9 @@ func NewRemoteWorkerFactory(
 				mothershipHost:   mothershipCfg.Host,
 				mothershipPort:   mothershipCfg.Port,
 			},
-			logger: log,
-			debug:  debug,
+			configResolver: configResolver,
+			logger:         log,
+			debug:          debug,
 		},
 		reconcilersCfg,
 		mothershipCfg,
@@ -66,17 +69,20 @@ func (rwf *remoteWorkerFactory) ForComponent(component string) (ReconciliationWo
 		}
 	}
 
-	return NewWorker(reconcilerCfg, rwf.inventory, rwf.operationsReg, rwf.invoker, rwf.debug)
+	return NewWorker(reconcilerCfg, rwf.inventory, rwf.operationsReg, rwf.invoker, rwf.configResolver, rwf.debug)
 }
 
 type localWorkerFactory struct {
 	*baseWorkerFactory
 }
 
+//NewLocalWorkerFactory creates a factory for workers which run the component reconcilers in the same process.
+//The configuration resolver is optional: without it, only the component configuration of the cluster is used.
 func NewLocalWorkerFactory(
 	inventory cluster.Inventory,
 	operationsReg OperationsRegistry,
 	statusFunc ReconcilerStatusFunc,
+	configResolver ConfigurationResolver,
 	debug bool) (WorkerFactory, error) {
 
 	log, err := logger.NewLogger(debug)
@@ -93,12 +99,13 @@ func NewLocalWorkerFactory(
 				operationsReg: operationsReg,
 				statusFunc:    statusFunc,
 			},
-			logger: log,
-			debug:  debug,
+			configResolver: configResolver,
+			logger:         log,
+			debug:          debug,
 		},
 	}, nil
 }
 
 func (lwf *localWorkerFactory) ForComponent(component string) (ReconciliationWorker, error) {
-	return NewWorker(&ComponentReconciler{}, lwf.inventory, lwf.operationsReg, lwf.invoker, lwf.debug)
+	return NewWorker(&ComponentReconciler{}, lwf.inventory, lwf.operationsReg, lwf.invoker, lwf.configResolver, lwf.debug)
 }